@@ -1,60 +1,316 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"fmt"
+	"log"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/smtp"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/fuki01/onion-architecture/domain/task"
+	"github.com/fuki01/onion-architecture/domain/user"
 	"github.com/fuki01/onion-architecture/infrastructure"
+	"github.com/fuki01/onion-architecture/infrastructure/auth"
 	"github.com/fuki01/onion-architecture/infrastructure/config"
+	"github.com/fuki01/onion-architecture/infrastructure/health"
+	"github.com/fuki01/onion-architecture/infrastructure/logging"
+	"github.com/fuki01/onion-architecture/infrastructure/metrics"
+	"github.com/fuki01/onion-architecture/infrastructure/migration"
+	"github.com/fuki01/onion-architecture/infrastructure/notifier"
+	"github.com/fuki01/onion-architecture/infrastructure/ratelimit"
+	"github.com/fuki01/onion-architecture/infrastructure/replica"
+	"github.com/fuki01/onion-architecture/infrastructure/scheduler"
+	"github.com/fuki01/onion-architecture/infrastructure/server"
+	"github.com/fuki01/onion-architecture/infrastructure/tracing"
+	"github.com/fuki01/onion-architecture/infrastructure/webhookclient"
 	"github.com/fuki01/onion-architecture/presentation/controller"
+	"github.com/fuki01/onion-architecture/presentation/middleware"
 	"github.com/fuki01/onion-architecture/presentation/router"
 	"github.com/fuki01/onion-architecture/usecase"
-	"github.com/joho/godotenv"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"gorm.io/gorm"
 )
 
-func loadEnv(envfile string) {
-	err := godotenv.Load(envfile)
-	fmt.Println("err: ", err)
+func main() {
+	// 設定を読み込む
+	flags := flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+	printConfig := flags.Bool("print-config", false, "読み込んだ設定を秘密情報を伏せて出力し、終了する")
+	cfg, err := config.Load(flags, os.Args[1:])
 	if err != nil {
-		panic("no env file")
+		log.Fatal(err)
+	}
+	if *printConfig {
+		fmt.Print(cfg)
+		return
+	}
+	slog.SetDefault(logging.New(cfg.Log, os.Stdout, logging.StringAttr("request_id", middleware.RequestIDFromContext)))
+	slog.Info("starting", slog.String("profile", string(cfg.Env)))
+	gin.SetMode(cfg.Server.Mode)
+	gin.DebugPrintRouteFunc = func(method, path, handler string, handlers int) {
+		slog.Debug("route", slog.String("method", method), slog.String("path", path), slog.String("handler", handler))
 	}
-}
 
-func main() {
-	// 環境変数を読み込む
-	loadEnv(".env")
-	user := os.Getenv("DB_USER")
-	pass := os.Getenv("DB_PASS")
-	host := os.Getenv("DB_HOST")
-	dbname := os.Getenv("DB_NAME")
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
 
-	if user == "" || pass == "" || host == "" || dbname == "" {
-		panic("failed to load env")
+	shutdownTracing, err := tracing.Setup(ctx, cfg.Tracing)
+	if err != nil {
+		fatal("failed to set up tracing", err)
 	}
+	tracer := otel.Tracer("github.com/fuki01/onion-architecture")
 
-	db, err := config.NewDatabase(user, pass, host, dbname).Connect()
+	db, err := config.NewDatabase(cfg.Database).Connect(ctx)
 	if err != nil {
-		panic("failed to connect database")
+		fatal("failed to connect to database", err)
 	}
-
-	err = db.AutoMigrate(&task.Task{})
+	replicas, err := config.NewDatabase(cfg.Database).ConnectReplicas(ctx)
 	if err != nil {
-		panic("failed to migrate database")
+		fatal("failed to connect to replicas", err)
 	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		fatal("failed to get database handle", err)
+	}
+
+	// メトリクスを初期化し、クエリの時間とスパンを記録する
+	// クエリのログは失敗したものと遅いものをリクエストIDとともに出力する
+	appMetrics := metrics.New()
+	for _, d := range append([]*gorm.DB{db}, replicas...) {
+		d.Logger = logging.NewGormLogger(slog.Default(), cfg.Log.SlowQueryThreshold)
+		if err := errors.Join(d.Use(appMetrics.GormPlugin()), d.Use(tracing.GormPlugin())); err != nil {
+			fatal("failed to register gorm plugins", err)
+		}
+	}
+
+	if err := migration.Migrate(db); err != nil {
+		fatal("failed to migrate database", err)
+	}
+
+	// readyzで確認する依存先を登録する
+	checks := health.NewRegistry(2 * time.Second)
+	checks.Register("database", health.Ping(sqlDB))
+	checks.Register("migration", migration.Check(db))
+
+	// レプリカは疎通できなくてもプライマリから読めるため、readyzでは確認せずに読み込みの対象から外す
+	pool := replica.NewPool(db, replicas...)
+	go pool.Monitor(ctx, 5*time.Second)
 
 	// TaskRepositoryの実装を初期化
-	taskRepository := infrastructure.NewArticlePersistence(db)
+	taskRepository := infrastructure.NewArticlePersistence(pool)
+	appMetrics.Register(metrics.NewTaskCollector(taskRepository, time.Now, 5*time.Second))
+	userRepository := infrastructure.NewUserPersistence(db)
+	workspaceRepository := infrastructure.NewWorkspacePersistence(db)
+
+	// Webhookを初期化
+	webhookUseCase := usecase.NewWebhookUsecase(
+		infrastructure.NewWebhookPersistence(db),
+		webhookclient.NewSender(webhookclient.NewClient(10*time.Second)),
+		usecase.DefaultRetryPolicy,
+	)
 
 	// UseCaseを初期化
-	taskUseCase := usecase.NewTaskUsecase(taskRepository)
+	taskUseCase := usecase.TraceTaskUsecase(usecase.NewTaskUsecase(
+		taskRepository,
+		usecase.WithDelayPolicy(task.DelayPolicy{MaxExtensions: cfg.Delay.MaxExtensions, MaxDelayDays: cfg.Delay.MaxDays}),
+		usecase.WithEventPublisher(webhookUseCase),
+		usecase.WithTaskMetrics(appMetrics),
+		usecase.WithWorkspaceRepository(workspaceRepository),
+		usecase.WithUserRepository(userRepository),
+	), tracer)
+	workspaceUseCase := usecase.NewWorkspaceUsecase(workspaceRepository, userRepository)
+
+	// 通知を初期化
+	notificationUseCase := usecase.NewNotificationUsecase(
+		infrastructure.NewNotificationSettingsPersistence(db),
+		newNotifiers(cfg.SMTP),
+	)
 
 	// Controllerを初期化
 	taskController := controller.NewTaskController(taskUseCase)
+	notificationController := controller.NewNotificationController(notificationUseCase)
+	webhookController := controller.NewWebhookController(webhookUseCase)
+	workspaceController := controller.NewWorkspaceController(workspaceUseCase)
+	metricsController := controller.NewMetricsController(sqlDB, appMetrics.Handler())
+	healthController := controller.NewHealthController(checks)
+
+	// トークンの検証と発行を初期化
+	authConfig := auth.Config{
+		HMACSecret:       cfg.Auth.HMACSecret,
+		RSAPublicKeyFile: cfg.Auth.RSAPublicKeyFile,
+		JWKSFile:         cfg.Auth.JWKSFile,
+		Issuer:           cfg.Auth.Issuer,
+		Audience:         cfg.Auth.Audience,
+	}
+	verifier, err := auth.NewJWTVerifier(authConfig)
+	if err != nil {
+		fatal("failed to load jwt keys", err)
+	}
+
+	// ローカルアカウントはトークンの署名に使うHMACの秘密鍵がある場合のみ提供する
+	// RS256のトークンのみを受け付ける環境では、トークンは外部の認証サーバーが発行する
+	var accountController *controller.AccountController
+	if cfg.Auth.HMACSecret != "" {
+		issuer, err := auth.NewJWTIssuer(authConfig, cfg.Auth.AccessTokenTTL)
+		if err != nil {
+			fatal("failed to initialize token issuer", err)
+		}
+		accountUseCase := usecase.NewAccountUsecase(
+			userRepository,
+			infrastructure.NewRefreshTokenPersistence(db),
+			auth.NewBcryptHasher(0),
+			issuer,
+			usecase.WithRefreshTokenTTL(cfg.Auth.RefreshTokenTTL),
+		)
+		accountController = controller.NewAccountController(accountUseCase)
+	} else {
+		slog.Info("local accounts are disabled because no HMAC secret is configured")
+	}
+
+	// APIキーを初期化
+	apiKeyUseCase := usecase.NewApiKeyUsecase(infrastructure.NewApiKeyPersistence(db))
+	apiKeyController := controller.NewApiKeyController(apiKeyUseCase)
+
+	// タスクの作成の再送に同じ応答を返すよう、冪等キーの記録を初期化
+	idempotencyUseCase := usecase.NewIdempotencyUsecase(infrastructure.NewIdempotencyPersistence(db), cfg.Idempotency.TTL)
+
+	// ルートのグループごとのレート制限を初期化
+	limitStore := ratelimit.NewMemoryStore()
+	limits := router.RateLimiters{
+		Client: middleware.RateLimit(limitStore, "client", middleware.Limit{PerMinute: cfg.RateLimit.ClientPerMinute, Burst: cfg.RateLimit.ClientBurst}, middleware.ByClientIP),
+		Auth:   middleware.RateLimit(limitStore, "auth", middleware.Limit{PerMinute: cfg.RateLimit.AuthPerMinute, Burst: cfg.RateLimit.AuthBurst}, middleware.ByClientIP),
+		API:    middleware.RateLimit(limitStore, "api", middleware.Limit{PerMinute: cfg.RateLimit.APIPerMinute, Burst: cfg.RateLimit.APIBurst}, middleware.ByIdentity),
+	}
 
 	// ルーティングを設定
-	r := router.SetupRouter(taskController)
+	r := router.SetupRouter(middleware.Trace(tracer), middleware.Instrument(appMetrics), limits, middleware.Authenticate(verifier, apiKeyUseCase), middleware.ReadYourWrites(replica.NewTracker(cfg.Database.ReplicaStickiness)), middleware.Idempotency(idempotencyUseCase), taskController, notificationController, webhookController, accountController, apiKeyController, workspaceController, healthController)
+	if err := r.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		fatal("failed to set trusted proxies", err)
+	}
 
-	// サーバーを起動
-	r.Run(":8080")
+	// 定期実行ジョブを開始
+	store := scheduler.NewGormStore(db)
+	jobs := scheduler.NewScheduler(store, store)
+	if err := registerJobs(jobs, cfg.Scheduler, usecase.NewReminderUsecase(taskRepository), notificationUseCase, idempotencyUseCase); err != nil {
+		fatal("failed to register jobs", err)
+	}
+	jobs.Start(ctx)
+	checks.Register("scheduler", health.Running(jobs))
+
+	// メトリクスはAPIとは別の内部向けのアドレスでのみ公開し、APIのサーバーが停止するまで待ち受ける
+	metricsCtx, stopMetrics := context.WithCancel(context.Background())
+	metricsDone := make(chan struct{})
+	if cfg.Server.MetricsAddr != "" {
+		metricsConfig := cfg.Server
+		metricsConfig.Addr = cfg.Server.MetricsAddr
+		metricsConfig.DrainDelay = 0
+		slog.Info("serving metrics", slog.String("addr", metricsConfig.Addr))
+		go func() {
+			defer close(metricsDone)
+			if err := server.New(metricsConfig, router.SetupMetricsRouter(metricsController)).Run(metricsCtx); err != nil {
+				slog.Error("metrics server stopped", slog.Any("error", err))
+			}
+		}()
+	} else {
+		close(metricsDone)
+	}
+
+	// サーバーを起動し、終了のシグナルを受け取ったらreadyzを失敗させ、処理中のリクエストを待って停止する
+	slog.Info("listening", slog.String("addr", cfg.Server.Addr))
+	srv := server.New(cfg.Server, r)
+	srv.OnDrain(checks.Drain)
+	if err := srv.Run(ctx); err != nil {
+		slog.Error("server stopped", slog.Any("error", err))
+	}
+	stop()
+	stopMetrics()
+	<-metricsDone
+
+	// 実行中のジョブと配送の終了を待ち、データベースの接続を閉じる
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+	if err := jobs.Stop(shutdownCtx); err != nil {
+		slog.Error("failed to stop jobs", slog.Any("error", err))
+	}
+	if err := webhookUseCase.Shutdown(shutdownCtx); err != nil {
+		slog.Error("failed to stop webhook deliveries", slog.Any("error", err))
+	}
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		slog.Error("failed to flush traces", slog.Any("error", err))
+	}
+	closeDatabases(append([]*gorm.DB{db}, replicas...))
+}
+
+// 起動に失敗した理由を出力して終了する
+func fatal(msg string, err error) {
+	slog.Error(msg, slog.Any("error", err))
+	os.Exit(1)
+}
+
+// データベースの接続を全て閉じる
+func closeDatabases(dbs []*gorm.DB) {
+	for _, db := range dbs {
+		sqlDB, err := db.DB()
+		if err == nil {
+			err = sqlDB.Close()
+		}
+		if err != nil {
+			slog.Error("failed to close database", slog.Any("error", err))
+		}
+	}
+}
+
+// 定期実行するジョブを登録する
+func registerJobs(jobs *scheduler.Scheduler, cfg config.SchedulerConfig, reminderUseCase usecase.ReminderUsecase, notificationUseCase usecase.NotificationUsecase, idempotencyUseCase usecase.IdempotencyUsecase) error {
+	err := jobs.Register("remind_due_soon", cfg.ReminderCron, func(ctx context.Context) error {
+		reminded, err := reminderUseCase.RemindDueSoon(ctx, time.Now(), 24*time.Hour)
+		return errors.Join(err, notificationUseCase.Notify(ctx, usecase.NotificationDueSoon, reminded))
+	})
+	if err != nil {
+		return err
+	}
+
+	err = jobs.Register("mark_overdue", cfg.OverdueCron, func(ctx context.Context) error {
+		marked, err := reminderUseCase.MarkOverdue(ctx, time.Now())
+		return errors.Join(err, notificationUseCase.Notify(ctx, usecase.NotificationOverdue, marked))
+	})
+	if err != nil {
+		return err
+	}
+
+	return jobs.Register("delete_expired_idempotency_keys", cfg.IdempotencyCleanupCron, func(ctx context.Context) error {
+		deleted, err := idempotencyUseCase.DeleteExpired(ctx, time.Now())
+		if deleted > 0 {
+			slog.InfoContext(ctx, "deleted expired idempotency keys", slog.Int64("count", deleted))
+		}
+		return err
+	})
+}
+
+// 通知チャネルごとの送信方法を初期化する
+// SMTPのアドレスが未設定の場合はメールを送らない
+func newNotifiers(cfg config.SMTPConfig) map[user.NotificationChannel]usecase.Notifier {
+	client := &http.Client{Timeout: 10 * time.Second}
+	notifiers := map[user.NotificationChannel]usecase.Notifier{
+		user.ChannelWebhook: notifier.NewWebhookNotifier(client),
+		user.ChannelSlack:   notifier.NewSlackNotifier(client),
+	}
+
+	if cfg.Addr != "" {
+		var auth smtp.Auth
+		if cfg.User != "" {
+			host, _, _ := net.SplitHostPort(cfg.Addr)
+			auth = smtp.PlainAuth("", cfg.User, cfg.Pass, host)
+		}
+		notifiers[user.ChannelEmail] = notifier.NewSMTPNotifier(cfg.Addr, cfg.From, auth)
+	}
+	return notifiers
 }
@@ -0,0 +1,92 @@
+package idempotency
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/fuki01/onion-architecture/domain/user"
+)
+
+// MaxKeyLength は冪等キーの最大の長さ
+const MaxKeyLength = 255
+
+var (
+	ErrInvalidKey = errors.New("idempotency key must be 1 to 255 characters")
+	// ErrKeyReused は同じキーが別の内容のリクエストに使われたことを表す
+	ErrKeyReused = errors.New("idempotency key was already used for a different request")
+	// ErrInProgress は同じキーのリクエストがまだ処理中であることを表す
+	ErrInProgress = errors.New("a request with this idempotency key is in progress")
+)
+
+// Record は冪等キーで受け付けたリクエストと、その応答
+// キーはユーザーごとに区別し、キー自体は保存せずハッシュのみを保存する
+type Record struct {
+	UserId      user.UserId `gorm:"primaryKey;autoIncrement:false"`
+	KeyHash     string      `gorm:"primaryKey;size:64"`
+	RequestHash string      `gorm:"size:64"`
+	// StatusCode は応答を記録するまで0で、その間は処理中として扱う
+	StatusCode  int
+	ContentType string `gorm:"size:255"`
+	Body        []byte `gorm:"type:mediumblob"`
+	CreatedAt   time.Time
+	ExpiresAt   time.Time `gorm:"index"`
+}
+
+// TableName は冪等キーの記録のテーブル名
+func (Record) TableName() string {
+	return "idempotency_keys"
+}
+
+// NewRecord はキーで受け付けたリクエストを処理中として記録する
+func NewRecord(userId user.UserId, key, requestHash string, now time.Time, ttl time.Duration) (*Record, error) {
+	if key == "" || len(key) > MaxKeyLength {
+		return nil, ErrInvalidKey
+	}
+	// 記録を作成日時で特定できるよう、データベースに保存できる精度に揃える
+	now = now.Truncate(time.Millisecond)
+	return &Record{
+		UserId:      userId,
+		KeyHash:     HashKey(key),
+		RequestHash: requestHash,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(ttl),
+	}, nil
+}
+
+// HashKey は保存用のキーのハッシュを返す
+func HashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// HashRequest は同じキーで同じリクエストが送られたかを比べるためのハッシュを返す
+func HashRequest(method, path string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(method + " " + path + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// IsCompleted は応答を記録済みかを返す
+func (r *Record) IsCompleted() bool {
+	return r.StatusCode != 0
+}
+
+// IsExpired は保存期間を過ぎたかを返す
+func (r *Record) IsExpired(now time.Time) bool {
+	return !now.Before(r.ExpiresAt)
+}
+
+// IsAbandoned は処理中のままlockTimeoutを過ぎ、処理したプロセスが応答を記録できなかったとみなせるかを返す
+func (r *Record) IsAbandoned(now time.Time, lockTimeout time.Duration) bool {
+	return !r.IsCompleted() && now.Sub(r.CreatedAt) >= lockTimeout
+}
+
+// Complete は応答を記録する
+func (r *Record) Complete(statusCode int, contentType string, body []byte) {
+	r.StatusCode = statusCode
+	r.ContentType = contentType
+	r.Body = body
+}
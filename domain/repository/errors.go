@@ -0,0 +1,9 @@
+package repository
+
+import "errors"
+
+// ErrNotFound は対象のレコードが存在しないことを表す
+var ErrNotFound = errors.New("record not found")
+
+// ErrAlreadyExists は同じキーのレコードが既に存在することを表す
+var ErrAlreadyExists = errors.New("record already exists")
@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/fuki01/onion-architecture/domain/idempotency"
+	"github.com/fuki01/onion-architecture/domain/user"
+)
+
+type IdempotencyRepository interface {
+	// Insert は記録を登録する。同じユーザーとキーの記録がある場合はErrAlreadyExistsを返す
+	Insert(ctx context.Context, r *idempotency.Record) error
+	Find(ctx context.Context, userId user.UserId, keyHash string) (*idempotency.Record, error)
+	Update(ctx context.Context, r *idempotency.Record) error
+	Delete(ctx context.Context, r *idempotency.Record) error
+	// DeleteExpired は保存期間を過ぎた記録を削除し、削除した件数を返す
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+}
@@ -0,0 +1,8 @@
+package repository
+
+import "github.com/fuki01/onion-architecture/domain/user"
+
+type NotificationSettingsRepository interface {
+	FindByUserId(userId user.UserId) (*user.NotificationSettings, error)
+	Save(settings *user.NotificationSettings) error
+}
@@ -1,14 +1,40 @@
 package repository
 
 import (
+	"context"
+
 	"github.com/fuki01/onion-architecture/domain/task"
 	"github.com/fuki01/onion-architecture/domain/user"
+	"github.com/fuki01/onion-architecture/domain/workspace"
 )
 
 type TaskRepository interface {
-	FindById(id task.TaskId) (*task.Task, error)
-	FindByUserId(userId user.UserId) ([]*task.Task, error)
-	Insert(task *task.Task) (task.TaskId, error)
-	Update(task *task.Task) error
-	Delete(task *task.Task) error
+	FindById(ctx context.Context, id task.TaskId) (*task.Task, error)
+	FindByCreatedBy(ctx context.Context, userId user.UserId, filter task.Filter) ([]*task.Task, error)
+	FindByAssigneeId(ctx context.Context, userId user.UserId, filter task.Filter) ([]*task.Task, error)
+	FindByWorkspaceId(ctx context.Context, workspaceId workspace.WorkspaceId, filter task.Filter) ([]*task.Task, error)
+	FindIncompleteDueBy(ctx context.Context, dueDate string) ([]*task.Task, error)
+	// CountIncomplete は未完了のタスクの数と、そのうち期限日がtodayより前のタスクの数を返す
+	CountIncomplete(ctx context.Context, today string) (open int64, overdue int64, err error)
+	Insert(ctx context.Context, task *task.Task) (task.TaskId, error)
+	Update(ctx context.Context, task *task.Task) error
+	// UpdateWithNext はタスクを更新し、繰り返しの次回分のタスクを同じトランザクションで登録する
+	UpdateWithNext(ctx context.Context, task *task.Task, next *task.Task) error
+	// UpdatePositions は子タスクの表示順のみを同じトランザクションで更新する
+	UpdatePositions(ctx context.Context, tasks []*task.Task) error
+	// UpdateReminderState はリマインドした日時と期限切れになった日時のみを更新する
+	UpdateReminderState(ctx context.Context, task *task.Task) error
+	Delete(ctx context.Context, task *task.Task) error
+	// FindDependencies は指定したタスクから依存先を辿って到達できる依存関係を取得する
+	FindDependencies(ctx context.Context, id task.TaskId) ([]task.Dependency, error)
+	// AddDependency は依存関係を登録する
+	// 循環が生じる場合はtask.ErrDependencyCycleを返す
+	AddDependency(ctx context.Context, dep task.Dependency) error
+	RemoveDependency(ctx context.Context, dep task.Dependency) error
+	AddShare(ctx context.Context, share task.Share) error
+	RemoveShare(ctx context.Context, share task.Share) error
+	// Reassign はタスクの担当者を更新し、変更履歴を登録する
+	Reassign(ctx context.Context, t *task.Task, a *task.Assignment) error
+	// FindAssignments はタスクの担当者の変更履歴を古い順に取得する
+	FindAssignments(ctx context.Context, id task.TaskId) ([]task.Assignment, error)
 }
@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/fuki01/onion-architecture/domain/user"
+)
+
+type UserRepository interface {
+	FindById(id user.UserId) (*user.User, error)
+	FindByEmail(email string) (*user.User, error)
+	Insert(u *user.User) error
+	// UpdatePasswordHash はパスワードのハッシュのみを更新し、同じトランザクションで有効なリフレッシュトークンを全て失効させる
+	UpdatePasswordHash(id user.UserId, hash string, at time.Time) error
+	// RecordLoginFailure はログインの失敗を行ロックを取った上で記録し、失敗回数とロックの列のみを更新する
+	// 記録後のユーザーを返す
+	RecordLoginFailure(id user.UserId, now time.Time, policy user.LockoutPolicy) (*user.User, error)
+	// ResetLoginFailures はログインの失敗回数とロックの列のみを解除する
+	ResetLoginFailures(id user.UserId) error
+}
+
+type RefreshTokenRepository interface {
+	FindByHash(hash string) (*user.RefreshToken, error)
+	Insert(t *user.RefreshToken) error
+	// Revoke は有効なトークンを失効させる
+	// 既に失効していた場合はErrNotFoundを返す
+	Revoke(t *user.RefreshToken) error
+	// RevokeFamily は同じログインから発行された有効なトークンを全て失効させる
+	RevokeFamily(familyId string, at time.Time) error
+}
+
+type ApiKeyRepository interface {
+	FindById(id int) (*user.ApiKey, error)
+	FindByHash(hash string) (*user.ApiKey, error)
+	FindByUserId(userId user.UserId) ([]*user.ApiKey, error)
+	Insert(k *user.ApiKey) error
+	Revoke(k *user.ApiKey) error
+	// TouchLastUsed は最終利用日時を更新する
+	TouchLastUsed(id int, at time.Time) error
+}
@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"github.com/fuki01/onion-architecture/domain/user"
+	"github.com/fuki01/onion-architecture/domain/webhook"
+)
+
+type WebhookRepository interface {
+	FindSubscriptionById(id webhook.SubscriptionId) (*webhook.Subscription, error)
+	// HasSubscriptionsFor は指定したイベントを購読している送信先があるかを返す
+	HasSubscriptionsFor(eventType webhook.EventType) (bool, error)
+	// FindSubscriptionsByUserIds は指定したユーザーが登録した送信先を取得する
+	FindSubscriptionsByUserIds(userIds []user.UserId) ([]*webhook.Subscription, error)
+	InsertSubscription(s *webhook.Subscription) error
+	DeleteSubscription(s *webhook.Subscription) error
+	FindDeliveryById(id webhook.DeliveryId) (*webhook.Delivery, error)
+	FindDeliveriesBySubscriptionId(id webhook.SubscriptionId) ([]*webhook.Delivery, error)
+	InsertDelivery(d *webhook.Delivery) error
+	// UpdateDelivery は配送の試行結果のみを更新する
+	// 送信先とともに配送記録が削除されていた場合はErrNotFoundを返す
+	UpdateDelivery(d *webhook.Delivery) error
+}
@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"github.com/fuki01/onion-architecture/domain/user"
+	"github.com/fuki01/onion-architecture/domain/workspace"
+)
+
+type WorkspaceRepository interface {
+	// FindById はメンバーを含めてワークスペースを取得する
+	FindById(id workspace.WorkspaceId) (*workspace.Workspace, error)
+	// FindByUserId はユーザーが参加しているワークスペースを取得する
+	FindByUserId(userId user.UserId) ([]*workspace.Workspace, error)
+	// Insert はワークスペースをメンバーと一緒に登録する
+	Insert(w *workspace.Workspace) error
+	AddMember(m workspace.Member) error
+	UpdateMember(m workspace.Member) error
+	RemoveMember(m workspace.Member) error
+}
@@ -0,0 +1,71 @@
+package task
+
+import (
+	"errors"
+	"time"
+
+	"github.com/fuki01/onion-architecture/domain/user"
+	"github.com/fuki01/onion-architecture/domain/workspace"
+)
+
+var ErrAssigneeNotFound = errors.New("assignee does not exist")
+
+// Assignment は担当者の変更履歴
+// 担当者を外した場合はToUserIdがnilになる
+type Assignment struct {
+	Id         int          `json:"id" gorm:"primaryKey"`
+	TaskId     TaskId       `json:"task_id" gorm:"index"`
+	FromUserId *user.UserId `json:"from_user_id"`
+	ToUserId   *user.UserId `json:"to_user_id"`
+	ChangedBy  user.UserId  `json:"changed_by"`
+	ChangedAt  time.Time    `json:"changed_at"`
+}
+
+func (Assignment) TableName() string {
+	return "task_assignments"
+}
+
+// Reassign は担当者を変更し、変更履歴を返す
+// nilを指定した場合は担当者を外す。担当者が変わらない場合は履歴を返さない
+// ワークスペースのタスクはwsにタスクのワークスペースを渡し、そのメンバーのみ担当者にできる
+// ワークスペースに属さないタスクは所有者か共有されたユーザーのみ担当者にできる
+func (t *Task) Reassign(assigneeId *user.UserId, ws *workspace.Workspace, by user.UserId, now time.Time) (*Assignment, error) {
+	if assigneeId != nil {
+		if t.WorkspaceId != nil {
+			if ws == nil || ws.Id != *t.WorkspaceId || !ws.IsMember(*assigneeId) {
+				return nil, newValidationError("assignee_id", "assignee must be a member of the workspace")
+			}
+		} else if !t.IsAccessibleBy(*assigneeId) {
+			return nil, newValidationError("assignee_id", "assignee must have access to the task")
+		}
+	}
+	if sameAssignee(t.AssigneeId, assigneeId) {
+		return nil, nil
+	}
+
+	a := &Assignment{
+		TaskId:     t.Id,
+		FromUserId: t.AssigneeId,
+		ToUserId:   assigneeId,
+		ChangedBy:  by,
+		ChangedAt:  now,
+	}
+	t.AssigneeId = assigneeId
+	return a, nil
+}
+
+// Recipient は通知を受け取るユーザーを返す
+// 担当者がいない場合は作成者になる
+func (t *Task) Recipient() user.UserId {
+	if t.AssigneeId != nil {
+		return *t.AssigneeId
+	}
+	return t.CreatedBy
+}
+
+func sameAssignee(a, b *user.UserId) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,77 @@
+package task
+
+import (
+	"fmt"
+	"time"
+)
+
+// DelayPolicy は期限延長の制限
+// 0のフィールドは制限しない
+type DelayPolicy struct {
+	MaxExtensions int
+	MaxDelayDays  int
+}
+
+// DelayPolicyError は期限延長が制限を超えたことを表す
+type DelayPolicyError struct {
+	Rule  string
+	Limit int
+}
+
+const (
+	DelayRuleMaxExtensions = "max_extensions"
+	DelayRuleMaxDelayDays  = "max_delay_days"
+)
+
+func (e *DelayPolicyError) Error() string {
+	return fmt.Sprintf("delay policy violated: %s is %d", e.Rule, e.Limit)
+}
+
+// ExtendDueDate は延長の制限を確認して期限を延長する
+// 延長日数は最初の期限からの累計で数える
+func (t *Task) ExtendDueDate(dueDate string, policy DelayPolicy) error {
+	if t.Status == StatusComplete {
+		return ErrCompletedTask
+	}
+
+	newDue, err := time.Parse(DueDateLayout, dueDate)
+	if err != nil {
+		return newValidationError("due_date", "invalid due date")
+	}
+	if current, err := time.Parse(DueDateLayout, t.DueDate); err == nil && !newDue.After(current) {
+		return newValidationError("due_date", "due date must be later than current due date")
+	}
+
+	if policy.MaxExtensions > 0 && t.DelayCount+1 > policy.MaxExtensions {
+		return &DelayPolicyError{Rule: DelayRuleMaxExtensions, Limit: policy.MaxExtensions}
+	}
+
+	original := t.OriginalDueDate
+	if original == "" {
+		original = t.DueDate
+	}
+	if originalDue, err := time.Parse(DueDateLayout, original); err == nil && policy.MaxDelayDays > 0 {
+		if days := int(newDue.Sub(originalDue).Hours() / 24); days > policy.MaxDelayDays {
+			return &DelayPolicyError{Rule: DelayRuleMaxDelayDays, Limit: policy.MaxDelayDays}
+		}
+	}
+
+	t.OriginalDueDate = original
+	t.DueDate = dueDate
+	t.DelayCount += 1
+	return nil
+}
+
+// IsOverdue は未完了のまま期限日を過ぎているかを返す
+// 期限日の当日中は期限切れとしない
+func (t *Task) IsOverdue(now time.Time) bool {
+	if t.Status == StatusComplete || t.DueDate == "" {
+		return false
+	}
+	return now.Format(DueDateLayout) > t.DueDate
+}
+
+// EvaluateOverdue は時刻nowの時点で期限切れかどうかをOverdueに設定する
+func (t *Task) EvaluateOverdue(now time.Time) {
+	t.Overdue = t.IsOverdue(now)
+}
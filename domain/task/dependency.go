@@ -0,0 +1,104 @@
+package task
+
+import (
+	"errors"
+	"sort"
+)
+
+var (
+	ErrDependencyCycle = errors.New("dependency would create a cycle")
+	ErrSelfDependency  = errors.New("task cannot depend on itself")
+	ErrOpenBlockers    = errors.New("blocking tasks are not completed")
+)
+
+// Dependency はTaskIdのタスクがBlockedByIdのタスクの完了を待つ関係
+type Dependency struct {
+	TaskId      TaskId `json:"task_id" gorm:"primaryKey"`
+	BlockedById TaskId `json:"blocked_by_id" gorm:"primaryKey"`
+}
+
+func (Dependency) TableName() string {
+	return "task_dependencies"
+}
+
+// DependencyGraph はタスク間の依存関係の有向グラフ
+type DependencyGraph struct {
+	blockers map[TaskId][]TaskId
+}
+
+// NewDependencyGraph は既存の依存関係からグラフを構築する
+func NewDependencyGraph(deps []Dependency) *DependencyGraph {
+	g := &DependencyGraph{blockers: make(map[TaskId][]TaskId)}
+	for _, dep := range deps {
+		g.blockers[dep.TaskId] = append(g.blockers[dep.TaskId], dep.BlockedById)
+	}
+	return g
+}
+
+// Add は依存関係を追加する
+// 追加により循環が生じる場合はエラーを返し、グラフは変更しない
+func (g *DependencyGraph) Add(dep Dependency) error {
+	if dep.TaskId == dep.BlockedById {
+		return ErrSelfDependency
+	}
+	if g.reachable(dep.BlockedById, dep.TaskId) {
+		return ErrDependencyCycle
+	}
+	for _, id := range g.blockers[dep.TaskId] {
+		if id == dep.BlockedById {
+			return nil
+		}
+	}
+	g.blockers[dep.TaskId] = append(g.blockers[dep.TaskId], dep.BlockedById)
+	return nil
+}
+
+// Chain は指定したタスクが推移的に依存するタスクを、先に完了すべき順に返す
+// 指定したタスク自身は含まない
+func (g *DependencyGraph) Chain(id TaskId) []TaskId {
+	var order []TaskId
+	visited := map[TaskId]bool{id: true}
+	var visit func(TaskId)
+	visit = func(current TaskId) {
+		blockers := append([]TaskId(nil), g.blockers[current]...)
+		sort.Slice(blockers, func(i, j int) bool { return blockers[i] < blockers[j] })
+		for _, blocker := range blockers {
+			if visited[blocker] {
+				continue
+			}
+			visited[blocker] = true
+			visit(blocker)
+			order = append(order, blocker)
+		}
+	}
+	visit(id)
+	return order
+}
+
+// reachable はfromからtoへ依存関係を辿って到達できるかを返す
+func (g *DependencyGraph) reachable(from, to TaskId) bool {
+	visited := map[TaskId]bool{}
+	stack := []TaskId{from}
+	for len(stack) > 0 {
+		current := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if current == to {
+			return true
+		}
+		if visited[current] {
+			continue
+		}
+		visited[current] = true
+		stack = append(stack, g.blockers[current]...)
+	}
+	return false
+}
+
+func (t *Task) hasOpenBlockers() bool {
+	for _, blocker := range t.BlockedBy {
+		if blocker.Status != StatusComplete {
+			return true
+		}
+	}
+	return false
+}
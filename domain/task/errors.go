@@ -0,0 +1,27 @@
+package task
+
+import "errors"
+
+var (
+	ErrCompletedTask      = errors.New("cannot edit completed task")
+	ErrIncompleteSubtasks = errors.New("subtasks are not completed")
+	ErrAlreadyCompleted   = errors.New("already completed")
+	ErrCannotRevert       = errors.New("cannot revert to incomplete")
+	ErrNestedSubtask      = errors.New("subtasks cannot be nested")
+	ErrInvalidOrder       = errors.New("order must contain every subtask exactly once")
+	ErrForbidden          = errors.New("forbidden")
+)
+
+// ValidationError はどのフィールドの検証に失敗したかを保持するエラー
+type ValidationError struct {
+	Field   string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Message
+}
+
+func newValidationError(field, message string) error {
+	return &ValidationError{Field: field, Message: message}
+}
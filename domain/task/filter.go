@@ -0,0 +1,13 @@
+package task
+
+import "time"
+
+// Filter はタスク一覧の絞り込み条件
+// ゼロ値のフィールドは条件に含めない
+type Filter struct {
+	Tag      string
+	Priority Priority
+	// Overdueの場合はNow時点で期限切れのタスクに絞り込む
+	Overdue bool
+	Now     time.Time
+}
@@ -0,0 +1,45 @@
+package task
+
+// Patch はタスクの部分更新の内容
+// nilのフィールドは変更しない
+type Patch struct {
+	Name            *string
+	Description     *string
+	Priority        *Priority
+	EstimateMinutes *int
+	Tags            *[]string
+	Recurrence      *string
+}
+
+// Apply は部分更新を適用して検証する
+// 完了済みのタスクは編集できない
+func (t *Task) Apply(p Patch) error {
+	if t.Status == StatusComplete {
+		return ErrCompletedTask
+	}
+
+	if p.Name != nil {
+		t.Name = *p.Name
+	}
+	if p.Description != nil {
+		t.Description = *p.Description
+	}
+	if p.Priority != nil {
+		t.Priority = *p.Priority
+	}
+	if p.EstimateMinutes != nil {
+		t.EstimateMinutes = *p.EstimateMinutes
+	}
+	if p.Recurrence != nil {
+		t.Recurrence = *p.Recurrence
+	}
+	if p.Tags != nil {
+		tags, err := NewTags(*p.Tags)
+		if err != nil {
+			return err
+		}
+		t.Tags = tags
+	}
+
+	return t.Validate()
+}
@@ -0,0 +1,293 @@
+package task
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DueDateLayout は期限日の書式
+const DueDateLayout = "2006-01-02"
+
+type Frequency string
+
+const (
+	FrequencyDaily   Frequency = "DAILY"
+	FrequencyWeekly  Frequency = "WEEKLY"
+	FrequencyMonthly Frequency = "MONTHLY"
+)
+
+// 存在しない日付を読み飛ばす際の探索上限
+const maxRecurrenceSearch = 1000
+
+var weekdayCodes = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+// Recurrence はiCalendarのRRULEのうちFREQ, INTERVAL, BYDAY, COUNT, UNTILに対応した繰り返し規則
+type Recurrence struct {
+	Freq     Frequency
+	Interval int
+	ByDay    []time.Weekday
+	Count    int
+	Until    time.Time
+	// UntilがDATE形式で指定されたか(日付単位で比較する)
+	untilIsDate bool
+}
+
+// ParseRecurrence はRRULE文字列を解釈する
+// 先頭の"RRULE:"は省略できる
+func ParseRecurrence(rule string) (*Recurrence, error) {
+	rule = strings.TrimPrefix(strings.TrimSpace(rule), "RRULE:")
+	r := &Recurrence{Interval: 1}
+	for _, part := range strings.Split(rule, ";") {
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid rrule part %q", part)
+		}
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			r.Freq = Frequency(strings.ToUpper(value))
+			if r.Freq != FrequencyDaily && r.Freq != FrequencyWeekly && r.Freq != FrequencyMonthly {
+				return nil, fmt.Errorf("unsupported frequency %q", value)
+			}
+		case "INTERVAL":
+			interval, err := strconv.Atoi(value)
+			if err != nil || interval < 1 {
+				return nil, fmt.Errorf("invalid interval %q", value)
+			}
+			r.Interval = interval
+		case "BYDAY":
+			for _, code := range strings.Split(value, ",") {
+				day, ok := weekdayCodes[strings.ToUpper(code)]
+				if !ok {
+					return nil, fmt.Errorf("unsupported byday %q", code)
+				}
+				r.ByDay = append(r.ByDay, day)
+			}
+		case "COUNT":
+			count, err := strconv.Atoi(value)
+			if err != nil || count < 1 {
+				return nil, fmt.Errorf("invalid count %q", value)
+			}
+			r.Count = count
+		case "UNTIL":
+			if until, err := time.Parse("20060102T150405Z", value); err == nil {
+				r.Until = until
+			} else if until, err := time.Parse("20060102", value); err == nil {
+				r.Until = until
+				r.untilIsDate = true
+			} else {
+				return nil, fmt.Errorf("invalid until %q", value)
+			}
+		default:
+			return nil, fmt.Errorf("unsupported rrule part %q", key)
+		}
+	}
+
+	if r.Freq == "" {
+		return nil, errors.New("rrule requires FREQ")
+	}
+	if r.Count > 0 && !r.Until.IsZero() {
+		return nil, errors.New("rrule cannot have both COUNT and UNTIL")
+	}
+	// 7の倍数の日おきでは曜日が変わらないため、開始日の曜日がBYDAYに含まれないと繰り返しが終わってしまう
+	if r.Freq == FrequencyDaily && len(r.ByDay) > 0 && r.Interval%7 == 0 {
+		return nil, errors.New("rrule with FREQ=DAILY and BYDAY requires an INTERVAL that is not a multiple of 7; use FREQ=WEEKLY instead")
+	}
+	sort.Slice(r.ByDay, func(i, j int) bool {
+		return mondayIndex(r.ByDay[i]) < mondayIndex(r.ByDay[j])
+	})
+	return r, nil
+}
+
+// String はRRULE文字列に変換する
+func (r *Recurrence) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		codes := make([]string, 0, len(r.ByDay))
+		for _, day := range r.ByDay {
+			codes = append(codes, strings.ToUpper(day.String()[:2]))
+		}
+		parts = append(parts, "BYDAY="+strings.Join(codes, ","))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if !r.Until.IsZero() {
+		if r.untilIsDate {
+			parts = append(parts, "UNTIL="+r.Until.Format("20060102"))
+		} else {
+			parts = append(parts, "UNTIL="+r.Until.UTC().Format("20060102T150405Z"))
+		}
+	}
+	return strings.Join(parts, ";")
+}
+
+// Next はoccurrence回目の発生日currentの次の発生日を返す
+// 壁時計の時刻を保ったまま日付を進めるため、夏時間の切り替えをまたいでも時刻はずれない
+// 月末など該当日が存在しない月はRFC 5545に従い読み飛ばす
+func (r *Recurrence) Next(current time.Time, occurrence int) (time.Time, bool) {
+	if r.Count > 0 && occurrence >= r.Count {
+		return time.Time{}, false
+	}
+
+	var next time.Time
+	var ok bool
+	switch r.Freq {
+	case FrequencyDaily:
+		next, ok = r.nextDaily(current)
+	case FrequencyWeekly:
+		next, ok = r.nextWeekly(current)
+	case FrequencyMonthly:
+		next, ok = r.nextMonthly(current)
+	}
+	if !ok || r.afterUntil(next) {
+		return time.Time{}, false
+	}
+	return next, true
+}
+
+func (r *Recurrence) nextDaily(current time.Time) (time.Time, bool) {
+	// 曜日の並びは7回で一巡する
+	for step := 1; step <= 7; step++ {
+		candidate := addDays(current, step*r.Interval)
+		if r.matchesDay(candidate.Weekday()) {
+			return candidate, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func (r *Recurrence) nextWeekly(current time.Time) (time.Time, bool) {
+	if len(r.ByDay) == 0 {
+		return addDays(current, 7*r.Interval), true
+	}
+
+	// 同じ週(月曜始まり)の残りの曜日を探す
+	today := mondayIndex(current.Weekday())
+	for _, day := range r.ByDay {
+		if index := mondayIndex(day); index > today {
+			return addDays(current, index-today), true
+		}
+	}
+
+	// INTERVAL週後の最初の曜日
+	weekStart := addDays(current, -today+7*r.Interval)
+	return addDays(weekStart, mondayIndex(r.ByDay[0])), true
+}
+
+func (r *Recurrence) nextMonthly(current time.Time) (time.Time, bool) {
+	if len(r.ByDay) > 0 {
+		// 同じ月の残りの日を探す
+		for candidate := addDays(current, 1); candidate.Month() == current.Month(); candidate = addDays(candidate, 1) {
+			if r.matchesDay(candidate.Weekday()) {
+				return candidate, true
+			}
+		}
+		first := time.Date(current.Year(), current.Month()+time.Month(r.Interval), 1,
+			current.Hour(), current.Minute(), current.Second(), current.Nanosecond(), current.Location())
+		for candidate := first; ; candidate = addDays(candidate, 1) {
+			if r.matchesDay(candidate.Weekday()) {
+				return candidate, true
+			}
+		}
+	}
+
+	for step := 1; step <= maxRecurrenceSearch; step++ {
+		month := current.Month() + time.Month(step*r.Interval)
+		candidate := time.Date(current.Year(), month, current.Day(),
+			current.Hour(), current.Minute(), current.Second(), current.Nanosecond(), current.Location())
+		// 日が繰り上がった場合はその月に該当日が存在しない
+		if candidate.Day() == current.Day() {
+			return candidate, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func (r *Recurrence) matchesDay(day time.Weekday) bool {
+	if len(r.ByDay) == 0 {
+		return true
+	}
+	for _, d := range r.ByDay {
+		if d == day {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Recurrence) afterUntil(t time.Time) bool {
+	if r.Until.IsZero() {
+		return false
+	}
+	if r.untilIsDate {
+		return t.Format("20060102") > r.Until.Format("20060102")
+	}
+	return t.After(r.Until)
+}
+
+// addDays は壁時計の時刻を保ったまま日数を進める
+func addDays(t time.Time, days int) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day()+days,
+		t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+}
+
+// mondayIndex は月曜日を0とした曜日の番号を返す
+func mondayIndex(day time.Weekday) int {
+	return (int(day) + 6) % 7
+}
+
+// NextOccurrence は完了した繰り返しタスクの次回分のタスクを生成する
+// 未完了、繰り返しが設定されていない、または繰り返しが終了している場合はnilを返す
+func (t *Task) NextOccurrence() (*Task, error) {
+	if t.Status != StatusComplete || t.Recurrence == "" {
+		return nil, nil
+	}
+	rule, err := ParseRecurrence(t.Recurrence)
+	if err != nil {
+		return nil, err
+	}
+	due, err := time.Parse(DueDateLayout, t.DueDate)
+	if err != nil {
+		return nil, err
+	}
+
+	occurrence := t.Occurrence
+	if occurrence < 1 {
+		occurrence = 1
+	}
+	nextDue, ok := rule.Next(due, occurrence)
+	if !ok {
+		return nil, nil
+	}
+
+	next := NewTask(t.Name, t.CreatedBy, nextDue.Format(DueDateLayout))
+	next.Description = t.Description
+	next.Priority = t.Priority
+	next.EstimateMinutes = t.EstimateMinutes
+	next.Recurrence = t.Recurrence
+	next.Occurrence = occurrence + 1
+	next.WorkspaceId = t.WorkspaceId
+	next.AssigneeId = t.AssigneeId
+	for _, tag := range t.Tags {
+		next.Tags = append(next.Tags, Tag{Name: tag.Name})
+	}
+	for _, share := range t.Shares {
+		next.Shares = append(next.Shares, Share{UserId: share.UserId})
+	}
+	return next, nil
+}
@@ -0,0 +1,186 @@
+package task_test
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata"
+
+	"github.com/fuki01/onion-architecture/domain/task"
+	"github.com/fuki01/onion-architecture/domain/user"
+	"github.com/stretchr/testify/assert"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestParseRecurrence(t *testing.T) {
+	testCases := []struct {
+		name     string
+		rule     string
+		expected string
+		hasError bool
+	}{
+		{name: "daily", rule: "FREQ=DAILY", expected: "FREQ=DAILY"},
+		{name: "prefix", rule: "RRULE:FREQ=WEEKLY;INTERVAL=2;BYDAY=FR,MO", expected: "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,FR"},
+		{name: "count", rule: "FREQ=MONTHLY;COUNT=12", expected: "FREQ=MONTHLY;COUNT=12"},
+		{name: "until date", rule: "FREQ=DAILY;UNTIL=20240131", expected: "FREQ=DAILY;UNTIL=20240131"},
+		{name: "until datetime", rule: "FREQ=DAILY;UNTIL=20240131T090000Z", expected: "FREQ=DAILY;UNTIL=20240131T090000Z"},
+		{name: "missing freq", rule: "INTERVAL=2", hasError: true},
+		{name: "yearly", rule: "FREQ=YEARLY", hasError: true},
+		{name: "ordinal byday", rule: "FREQ=MONTHLY;BYDAY=1MO", hasError: true},
+		{name: "invalid interval", rule: "FREQ=DAILY;INTERVAL=0", hasError: true},
+		{name: "count and until", rule: "FREQ=DAILY;COUNT=2;UNTIL=20240131", hasError: true},
+		{name: "daily byday with weekly interval", rule: "FREQ=DAILY;INTERVAL=14;BYDAY=MO", hasError: true},
+		{name: "daily byday with other interval", rule: "FREQ=DAILY;INTERVAL=3;BYDAY=MO", expected: "FREQ=DAILY;INTERVAL=3;BYDAY=MO"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rule, err := task.ParseRecurrence(tc.rule)
+			if tc.hasError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, rule.String())
+		})
+	}
+}
+
+func TestRecurrenceNext(t *testing.T) {
+	testCases := []struct {
+		name       string
+		rule       string
+		current    time.Time
+		occurrence int
+		expected   time.Time
+		done       bool
+	}{
+		{name: "daily interval", rule: "FREQ=DAILY;INTERVAL=3", current: date(2024, 1, 30), expected: date(2024, 2, 2)},
+		{name: "daily weekdays", rule: "FREQ=DAILY;BYDAY=MO,TU,WE,TH,FR", current: date(2024, 1, 5), expected: date(2024, 1, 8)},
+		{name: "weekly same week", rule: "FREQ=WEEKLY;BYDAY=MO,TH", current: date(2024, 1, 1), expected: date(2024, 1, 4)},
+		{name: "weekly next interval", rule: "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH", current: date(2024, 1, 4), expected: date(2024, 1, 15)},
+		{name: "weekly sunday ends week", rule: "FREQ=WEEKLY;BYDAY=SU", current: date(2024, 1, 7), expected: date(2024, 1, 14)},
+		{name: "monthly", rule: "FREQ=MONTHLY", current: date(2024, 1, 15), expected: date(2024, 2, 15)},
+		{name: "monthly skips short months", rule: "FREQ=MONTHLY", current: date(2024, 1, 31), expected: date(2024, 3, 31)},
+		{name: "monthly 30th skips february", rule: "FREQ=MONTHLY", current: date(2023, 1, 30), expected: date(2023, 3, 30)},
+		{name: "monthly leap day", rule: "FREQ=MONTHLY;INTERVAL=12", current: date(2024, 2, 29), expected: date(2028, 2, 29)},
+		{name: "monthly interval keeps cadence", rule: "FREQ=MONTHLY;INTERVAL=2", current: date(2024, 12, 31), expected: date(2025, 8, 31)},
+		{name: "monthly byday", rule: "FREQ=MONTHLY;BYDAY=FR", current: date(2024, 1, 26), expected: date(2024, 2, 2)},
+		{name: "count reached", rule: "FREQ=DAILY;COUNT=3", current: date(2024, 1, 3), occurrence: 3, done: true},
+		{name: "count remaining", rule: "FREQ=DAILY;COUNT=3", current: date(2024, 1, 2), occurrence: 2, expected: date(2024, 1, 3)},
+		{name: "until inclusive", rule: "FREQ=DAILY;UNTIL=20240131", current: date(2024, 1, 30), expected: date(2024, 1, 31)},
+		{name: "until passed", rule: "FREQ=DAILY;UNTIL=20240131", current: date(2024, 1, 31), done: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rule, err := task.ParseRecurrence(tc.rule)
+			assert.NoError(t, err)
+
+			occurrence := tc.occurrence
+			if occurrence == 0 {
+				occurrence = 1
+			}
+			next, ok := rule.Next(tc.current, occurrence)
+			assert.Equal(t, !tc.done, ok)
+			if !tc.done {
+				assert.Equal(t, tc.expected, next)
+			}
+		})
+	}
+}
+
+func TestRecurrenceNextAcrossDST(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	assert.NoError(t, err)
+
+	t.Run("spring forward keeps wall clock", func(t *testing.T) {
+		rule, _ := task.ParseRecurrence("FREQ=DAILY")
+		current := time.Date(2024, 3, 9, 9, 0, 0, 0, newYork)
+
+		next, ok := rule.Next(current, 1)
+		assert.True(t, ok)
+		assert.Equal(t, time.Date(2024, 3, 10, 9, 0, 0, 0, newYork), next)
+		assert.Equal(t, 23*time.Hour, next.Sub(current))
+	})
+
+	t.Run("fall back keeps wall clock", func(t *testing.T) {
+		rule, _ := task.ParseRecurrence("FREQ=WEEKLY")
+		current := time.Date(2024, 10, 30, 9, 0, 0, 0, newYork)
+
+		next, ok := rule.Next(current, 1)
+		assert.True(t, ok)
+		assert.Equal(t, time.Date(2024, 11, 6, 9, 0, 0, 0, newYork), next)
+		assert.Equal(t, 7*24*time.Hour+time.Hour, next.Sub(current))
+	})
+
+	t.Run("until compares instants", func(t *testing.T) {
+		rule, _ := task.ParseRecurrence("FREQ=DAILY;UNTIL=20240310T120000Z")
+		current := time.Date(2024, 3, 9, 9, 0, 0, 0, newYork)
+
+		// 3/10 9:00 EDT は 13:00 UTC で UNTIL を過ぎる
+		_, ok := rule.Next(current, 1)
+		assert.False(t, ok)
+	})
+}
+
+func TestNextOccurrence(t *testing.T) {
+	t.Run("creates next task", func(t *testing.T) {
+		current := task.NewTask("月次請求", user.UserId(1), "2024-01-31")
+		current.Recurrence = "FREQ=MONTHLY;COUNT=3"
+		current.Tags = []task.Tag{{Id: 5, Name: "billing"}}
+		assert.NoError(t, current.SetStatus(task.StatusComplete))
+
+		next, err := current.NextOccurrence()
+		assert.NoError(t, err)
+		assert.Equal(t, "2024-03-31", next.DueDate)
+		assert.Equal(t, 2, next.Occurrence)
+		assert.Equal(t, task.StatusIncomplete, next.Status)
+		assert.Equal(t, []string{"billing"}, next.TagNames())
+		assert.Equal(t, task.TaskId(0), next.Id)
+	})
+
+	t.Run("series finished", func(t *testing.T) {
+		current := task.NewTask("週報", user.UserId(1), "2024-01-05")
+		current.Recurrence = "FREQ=WEEKLY;COUNT=2"
+		current.Occurrence = 2
+		assert.NoError(t, current.SetStatus(task.StatusComplete))
+
+		next, err := current.NextOccurrence()
+		assert.NoError(t, err)
+		assert.Nil(t, next)
+	})
+
+	t.Run("incomplete task", func(t *testing.T) {
+		current := task.NewTask("日報", user.UserId(1), "2024-01-05")
+		current.Recurrence = "FREQ=DAILY"
+
+		next, err := current.NextOccurrence()
+		assert.NoError(t, err)
+		assert.Nil(t, next)
+	})
+
+	t.Run("no recurrence", func(t *testing.T) {
+		next, err := task.NewTask("単発", user.UserId(1), "2024-01-05").NextOccurrence()
+		assert.NoError(t, err)
+		assert.Nil(t, next)
+	})
+
+	t.Run("invalid recurrence fails validation", func(t *testing.T) {
+		current := task.NewTask("週報", user.UserId(1), "2024-01-05")
+		current.Recurrence = "FREQ=HOURLY"
+		assert.EqualError(t, current.Validate(), "invalid recurrence")
+	})
+
+	t.Run("subtasks cannot recur", func(t *testing.T) {
+		parent := task.NewTask("親", user.UserId(1), "2024-01-31")
+		parent.Id = task.TaskId(1)
+		child := task.NewTask("子", user.UserId(1), "2024-01-05")
+		assert.NoError(t, parent.AddSubtask(child))
+
+		recurrence := "FREQ=WEEKLY"
+		assert.EqualError(t, child.Apply(task.Patch{Recurrence: &recurrence}), "subtasks cannot recur")
+	})
+}
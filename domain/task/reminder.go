@@ -0,0 +1,39 @@
+package task
+
+import "time"
+
+// Deadline は期限日の終わり(翌日0時)を返す
+func (t *Task) Deadline(loc *time.Location) (time.Time, bool) {
+	due, err := time.ParseInLocation(DueDateLayout, t.DueDate, loc)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return due.AddDate(0, 0, 1), true
+}
+
+// NeedsReminder は未通知の未完了タスクの期限がnowからwithin以内に迫っているかを返す
+func (t *Task) NeedsReminder(now time.Time, within time.Duration) bool {
+	if t.Status == StatusComplete || t.RemindedAt != nil {
+		return false
+	}
+	deadline, ok := t.Deadline(now.Location())
+	if !ok {
+		return false
+	}
+	return deadline.After(now) && !deadline.After(now.Add(within))
+}
+
+// MarkReminded は期限前の通知を済ませたことを記録する
+func (t *Task) MarkReminded(now time.Time) {
+	t.RemindedAt = &now
+}
+
+// MarkOverdue は期限切れになったタスクに期限切れの日時を記録する
+// 新たに期限切れとして記録した場合はtrueを返す
+func (t *Task) MarkOverdue(now time.Time) bool {
+	if t.OverdueAt != nil || !t.IsOverdue(now) {
+		return false
+	}
+	t.OverdueAt = &now
+	return true
+}
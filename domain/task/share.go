@@ -0,0 +1,50 @@
+package task
+
+import "github.com/fuki01/onion-architecture/domain/user"
+
+// Share はタスクを所有者以外のユーザーに共有した記録
+type Share struct {
+	TaskId TaskId      `json:"task_id" gorm:"primaryKey"`
+	UserId user.UserId `json:"user_id" gorm:"primaryKey"`
+}
+
+func (Share) TableName() string {
+	return "task_shares"
+}
+
+// IsOwnedBy は指定したユーザーが所有しているかを返す
+// タスクの所有者は作成者とする
+func (t *Task) IsOwnedBy(userId user.UserId) bool {
+	return t.CreatedBy == userId
+}
+
+// IsAccessibleBy は指定したユーザーが参照・更新できるかを返す
+// 所有者と共有されたユーザーが対象になる
+func (t *Task) IsAccessibleBy(userId user.UserId) bool {
+	if t.IsOwnedBy(userId) {
+		return true
+	}
+	for _, s := range t.Shares {
+		if s.UserId == userId {
+			return true
+		}
+	}
+	return false
+}
+
+// ShareWith は指定したユーザーに共有する
+// ワークスペースのタスクはメンバーの役割で権限を管理するため共有できない
+func (t *Task) ShareWith(userId user.UserId) (Share, error) {
+	if userId == 0 {
+		return Share{}, newValidationError("user_id", "invalid user id")
+	}
+	if t.IsOwnedBy(userId) {
+		return Share{}, newValidationError("user_id", "cannot share with owner")
+	}
+	if t.WorkspaceId != nil {
+		return Share{}, newValidationError("user_id", "workspace tasks are shared with workspace members")
+	}
+	s := Share{TaskId: t.Id, UserId: userId}
+	t.Shares = append(t.Shares, s)
+	return s, nil
+}
@@ -0,0 +1,83 @@
+package task
+
+import "sort"
+
+// AddSubtask は子タスクとして追加する
+// 子タスクは親と同じユーザーが所有し、末尾に並ぶ
+func (t *Task) AddSubtask(child *Task) error {
+	if t.Status == StatusComplete {
+		return ErrCompletedTask
+	}
+	if t.ParentId != nil {
+		return ErrNestedSubtask
+	}
+
+	parentId := t.Id
+	child.ParentId = &parentId
+	child.CreatedBy = t.CreatedBy
+	child.WorkspaceId = t.WorkspaceId
+	child.Position = len(t.Subtasks)
+	if err := child.Validate(); err != nil {
+		return err
+	}
+
+	t.Subtasks = append(t.Subtasks, child)
+	return nil
+}
+
+// ReorderSubtasks は指定したIDの順に子タスクを並べ替える
+// idsには全ての子タスクを一度ずつ含める必要がある
+func (t *Task) ReorderSubtasks(ids []TaskId) error {
+	if len(ids) != len(t.Subtasks) {
+		return ErrInvalidOrder
+	}
+
+	positions := make(map[TaskId]int, len(ids))
+	for i, id := range ids {
+		if _, ok := positions[id]; ok {
+			return ErrInvalidOrder
+		}
+		positions[id] = i
+	}
+	for _, child := range t.Subtasks {
+		if _, ok := positions[child.Id]; !ok {
+			return ErrInvalidOrder
+		}
+	}
+
+	for _, child := range t.Subtasks {
+		child.Position = positions[child.Id]
+	}
+	sort.SliceStable(t.Subtasks, func(i, j int) bool {
+		return t.Subtasks[i].Position < t.Subtasks[j].Position
+	})
+	return nil
+}
+
+// Progress は子タスクの完了割合(0〜100)を返す
+// 子タスクがない場合は自身の状態から判定する
+func (t *Task) Progress() int {
+	if len(t.Subtasks) == 0 {
+		if t.Status == StatusComplete {
+			return 100
+		}
+		return 0
+	}
+
+	completed := 0
+	for _, child := range t.Subtasks {
+		if child.Status == StatusComplete {
+			completed++
+		}
+	}
+	return completed * 100 / len(t.Subtasks)
+}
+
+func (t *Task) hasIncompleteSubtasks() bool {
+	for _, child := range t.Subtasks {
+		if child.Status != StatusComplete {
+			return true
+		}
+	}
+	return false
+}
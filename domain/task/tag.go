@@ -0,0 +1,38 @@
+package task
+
+import "strings"
+
+const maxTagLength = 32
+
+type Tag struct {
+	Id   int    `json:"id" gorm:"primaryKey"`
+	Name string `json:"name" gorm:"uniqueIndex;size:32"`
+}
+
+// NewTags はタグ名の一覧を正規化してタグを生成する
+// 前後の空白を取り除き、重複したタグ名はまとめる
+func NewTags(names []string) ([]Tag, error) {
+	tags := make([]Tag, 0, len(names))
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" || len(name) > maxTagLength {
+			return nil, newValidationError("tags", "invalid tag name")
+		}
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		tags = append(tags, Tag{Name: name})
+	}
+	return tags, nil
+}
+
+// TagNames はタスクに付与されたタグ名の一覧を返す
+func (t *Task) TagNames() []string {
+	names := make([]string, 0, len(t.Tags))
+	for _, tag := range t.Tags {
+		names = append(names, tag.Name)
+	}
+	return names
+}
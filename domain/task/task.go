@@ -1,9 +1,10 @@
 package task
 
 import (
-	"errors"
+	"time"
 
 	"github.com/fuki01/onion-architecture/domain/user"
+	"github.com/fuki01/onion-architecture/domain/workspace"
 )
 
 type TaskStatus string
@@ -14,35 +15,82 @@ const (
 )
 
 type Task struct {
-	Id         TaskId      `json:"id" gorm:"primaryKey"`
-	Name       string      `json:"name"`
-	UserId     user.UserId `json:"user_id"`
-	Status     TaskStatus  `json:"status"`
-	DueDate    string      `json:"due_date"`
-	DelayCount int         `json:"delay_count"`
+	Id          TaskId `json:"id" gorm:"primaryKey"`
+	Name        string `json:"name"`
+	Description string `json:"description" gorm:"type:text"`
+	// 作成者。既存のデータと互換性を保つため列名はuser_idのままにする
+	CreatedBy       user.UserId            `json:"created_by" gorm:"column:user_id"`
+	Status          TaskStatus             `json:"status"`
+	Priority        Priority               `json:"priority"`
+	EstimateMinutes int                    `json:"estimate_minutes"`
+	Tags            []Tag                  `json:"tags" gorm:"many2many:task_tags"`
+	DueDate         string                 `json:"due_date"`
+	OriginalDueDate string                 `json:"original_due_date"`
+	DelayCount      int                    `json:"delay_count"`
+	ParentId        *TaskId                `json:"parent_id" gorm:"index"`
+	Position        int                    `json:"position"`
+	Subtasks        []*Task                `json:"subtasks,omitempty" gorm:"foreignKey:ParentId"`
+	BlockedBy       []*Task                `json:"blocked_by,omitempty" gorm:"many2many:task_dependencies;joinForeignKey:TaskId;joinReferences:BlockedById"`
+	Recurrence      string                 `json:"recurrence"`
+	Occurrence      int                    `json:"occurrence"`
+	RemindedAt      *time.Time             `json:"reminded_at"`
+	OverdueAt       *time.Time             `json:"overdue_at"`
+	Shares          []Share                `json:"-" gorm:"foreignKey:TaskId"`
+	WorkspaceId     *workspace.WorkspaceId `json:"workspace_id" gorm:"index"`
+	AssigneeId      *user.UserId           `json:"assignee_id" gorm:"index"`
+	// 取得した時点で期限切れかどうか。保存はしない
+	Overdue bool `json:"is_overdue" gorm:"-"`
 }
 
-func NewTask(name string, userId user.UserId, dueDate string) *Task {
+func NewTask(name string, createdBy user.UserId, dueDate string) *Task {
 	return &Task{
-		Name:       name,
-		UserId:     userId,
-		Status:     "未完了",
-		DueDate:    dueDate,
-		DelayCount: 0,
+		Name:            name,
+		CreatedBy:       createdBy,
+		Status:          "未完了",
+		Priority:        PriorityMedium,
+		DueDate:         dueDate,
+		OriginalDueDate: dueDate,
+		DelayCount:      0,
+		Occurrence:      1,
 	}
 }
 
 func (t *Task) Validate() error {
 	if t.Name == "" {
-		return errors.New("invalid task name")
+		return newValidationError("name", "invalid task name")
 	}
 
-	if t.UserId == 0 {
-		return errors.New("invalid user id")
+	if t.CreatedBy == 0 {
+		return newValidationError("created_by", "invalid user id")
 	}
 
-	if t.DueDate == "" {
-		return errors.New("invalid due date")
+	// 期限切れの判定は文字列で比較するため、書式を揃える
+	if _, err := time.Parse(DueDateLayout, t.DueDate); err != nil {
+		return newValidationError("due_date", "invalid due date")
+	}
+
+	if !t.Priority.IsValid() {
+		return newValidationError("priority", "invalid priority")
+	}
+
+	if t.EstimateMinutes < 0 {
+		return newValidationError("estimate_minutes", "invalid estimate")
+	}
+
+	for _, tag := range t.Tags {
+		if tag.Name == "" || len(tag.Name) > maxTagLength {
+			return newValidationError("tags", "invalid tag name")
+		}
+	}
+
+	if t.Recurrence != "" {
+		if _, err := ParseRecurrence(t.Recurrence); err != nil {
+			return newValidationError("recurrence", "invalid recurrence")
+		}
+		// 次回分は親を持たないタスクとして登録するため、子タスクは繰り返せない
+		if t.ParentId != nil {
+			return newValidationError("recurrence", "subtasks cannot recur")
+		}
 	}
 
 	return nil
@@ -50,11 +98,20 @@ func (t *Task) Validate() error {
 
 func (t *Task) SetStatus(newStatus TaskStatus) error {
 	if t.Status == StatusComplete && newStatus == StatusComplete {
-		return errors.New("already completed")
+		return ErrAlreadyCompleted
 	} else if t.Status == StatusComplete && newStatus == StatusIncomplete {
-		return errors.New("cannot revert to incomplete")
+		return ErrCannotRevert
+	} else if newStatus == StatusComplete && t.hasIncompleteSubtasks() {
+		return ErrIncompleteSubtasks
+	} else if newStatus == StatusComplete && t.hasOpenBlockers() {
+		return ErrOpenBlockers
 	} else {
 		t.Status = newStatus
 	}
 	return nil
 }
+
+// IsCompleted は完了済みかどうかを返す
+func (t *Task) IsCompleted() bool {
+	return t.Status == StatusComplete
+}
@@ -2,9 +2,11 @@ package task_test
 
 import (
 	"testing"
+	"time"
 
 	"github.com/fuki01/onion-architecture/domain/task"
 	"github.com/fuki01/onion-architecture/domain/user"
+	"github.com/fuki01/onion-architecture/domain/workspace"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -12,9 +14,10 @@ func TestNewTask(t *testing.T) {
 	task := task.NewTask("test", user.UserId(1), "2024-01-01")
 
 	assert.Equal(t, "test", task.Name)
-	assert.Equal(t, user.UserId(1), task.UserId)
+	assert.Equal(t, user.UserId(1), task.CreatedBy)
 	assert.Equal(t, "2024-01-01", task.DueDate)
 	assert.Equal(t, 0, task.DelayCount)
+	assert.Equal(t, "medium", string(task.Priority))
 }
 
 func TestValidate(t *testing.T) {
@@ -43,6 +46,21 @@ func TestValidate(t *testing.T) {
 					task:        task.NewTask("task1", user.UserId(1), ""),
 					expectedErr: "invalid due date",
 			},
+			{
+					name:        "Malformed due date",
+					task:        task.NewTask("task1", user.UserId(1), "2026/10/19"),
+					expectedErr: "invalid due date",
+			},
+			{
+					name:        "Invalid priority",
+					task:        &task.Task{Name: "task1", CreatedBy: user.UserId(1), DueDate: "2024-01-01", Priority: "critical"},
+					expectedErr: "invalid priority",
+			},
+			{
+					name:        "Invalid estimate",
+					task:        &task.Task{Name: "task1", CreatedBy: user.UserId(1), DueDate: "2024-01-01", Priority: task.PriorityLow, EstimateMinutes: -1},
+					expectedErr: "invalid estimate",
+			},
 	}
 
 	for _, tc := range testCases {
@@ -59,8 +77,279 @@ func TestValidate(t *testing.T) {
 
 
 func TestSetStatus(t *testing.T) {
-	task := task.NewTask("test", user.UserId(1), "2024-01-01")
-	assert.NoError(t, task.SetStatus("完了"))
-	assert.EqualError(t, task.SetStatus("未完了"), "cannot revert to incomplete")
-	assert.EqualError(t, task.SetStatus("完了"), "already completed")
+	completed := task.NewTask("test", user.UserId(1), "2024-01-01")
+	assert.NoError(t, completed.SetStatus("完了"))
+	assert.ErrorIs(t, completed.SetStatus("未完了"), task.ErrCannotRevert)
+	assert.ErrorIs(t, completed.SetStatus("完了"), task.ErrAlreadyCompleted)
+}
+
+func TestNewTags(t *testing.T) {
+	tags, err := task.NewTags([]string{" work ", "urgent", "work"})
+	assert.NoError(t, err)
+	assert.Equal(t, []task.Tag{{Name: "work"}, {Name: "urgent"}}, tags)
+
+	_, err = task.NewTags([]string{"  "})
+	assert.EqualError(t, err, "invalid tag name")
+}
+
+func TestApply(t *testing.T) {
+	name := "renamed"
+	priority := task.PriorityUrgent
+	tags := []string{"docs"}
+
+	t.Run("apply fields", func(t *testing.T) {
+		target := task.NewTask("test", user.UserId(1), "2024-01-01")
+		err := target.Apply(task.Patch{Name: &name, Priority: &priority, Tags: &tags})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "renamed", target.Name)
+		assert.Equal(t, task.PriorityUrgent, target.Priority)
+		assert.Equal(t, []string{"docs"}, target.TagNames())
+	})
+
+	t.Run("field error", func(t *testing.T) {
+		empty := ""
+		target := task.NewTask("test", user.UserId(1), "2024-01-01")
+		err := target.Apply(task.Patch{Name: &empty})
+
+		var validationErr *task.ValidationError
+		assert.ErrorAs(t, err, &validationErr)
+		assert.Equal(t, "name", validationErr.Field)
+	})
+
+	t.Run("completed task", func(t *testing.T) {
+		target := task.NewTask("test", user.UserId(1), "2024-01-01")
+		assert.NoError(t, target.SetStatus(task.StatusComplete))
+
+		assert.ErrorIs(t, target.Apply(task.Patch{Name: &name}), task.ErrCompletedTask)
+		assert.Equal(t, "test", target.Name)
+	})
+}
+
+func TestSubtasks(t *testing.T) {
+	newParent := func() *task.Task {
+		parent := task.NewTask("parent", user.UserId(1), "2024-01-31")
+		parent.Id = task.TaskId(1)
+		return parent
+	}
+
+	t.Run("add subtask", func(t *testing.T) {
+		parent := newParent()
+		first := task.NewTask("first", user.UserId(0), "2024-01-10")
+		second := task.NewTask("second", user.UserId(0), "2024-01-20")
+
+		assert.NoError(t, parent.AddSubtask(first))
+		assert.NoError(t, parent.AddSubtask(second))
+
+		assert.Equal(t, task.TaskId(1), *second.ParentId)
+		assert.Equal(t, user.UserId(1), second.CreatedBy)
+		assert.Equal(t, 1, second.Position)
+		assert.ErrorIs(t, first.AddSubtask(task.NewTask("nested", user.UserId(1), "2024-01-10")), task.ErrNestedSubtask)
+	})
+
+	t.Run("parent cannot complete with incomplete children", func(t *testing.T) {
+		parent := newParent()
+		child := task.NewTask("child", user.UserId(1), "2024-01-10")
+		assert.NoError(t, parent.AddSubtask(child))
+
+		assert.ErrorIs(t, parent.SetStatus(task.StatusComplete), task.ErrIncompleteSubtasks)
+		assert.NoError(t, child.SetStatus(task.StatusComplete))
+		assert.NoError(t, parent.SetStatus(task.StatusComplete))
+	})
+
+	t.Run("progress", func(t *testing.T) {
+		parent := newParent()
+		assert.Equal(t, 0, parent.Progress())
+
+		for _, name := range []string{"a", "b", "c"} {
+			assert.NoError(t, parent.AddSubtask(task.NewTask(name, user.UserId(1), "2024-01-10")))
+		}
+		assert.NoError(t, parent.Subtasks[0].SetStatus(task.StatusComplete))
+		assert.Equal(t, 33, parent.Progress())
+	})
+
+	t.Run("reorder", func(t *testing.T) {
+		parent := newParent()
+		for i, name := range []string{"a", "b", "c"} {
+			child := task.NewTask(name, user.UserId(1), "2024-01-10")
+			assert.NoError(t, parent.AddSubtask(child))
+			child.Id = task.TaskId(10 + i)
+		}
+
+		assert.NoError(t, parent.ReorderSubtasks([]task.TaskId{12, 10, 11}))
+		assert.Equal(t, "c", parent.Subtasks[0].Name)
+		assert.Equal(t, 2, parent.Subtasks[2].Position)
+
+		assert.ErrorIs(t, parent.ReorderSubtasks([]task.TaskId{12, 10}), task.ErrInvalidOrder)
+		assert.ErrorIs(t, parent.ReorderSubtasks([]task.TaskId{12, 12, 10}), task.ErrInvalidOrder)
+		assert.ErrorIs(t, parent.ReorderSubtasks([]task.TaskId{12, 10, 99}), task.ErrInvalidOrder)
+	})
+}
+
+func TestDependencyGraph(t *testing.T) {
+	t.Run("detect cycle", func(t *testing.T) {
+		graph := task.NewDependencyGraph([]task.Dependency{
+			{TaskId: 2, BlockedById: 1},
+			{TaskId: 3, BlockedById: 2},
+		})
+
+		assert.ErrorIs(t, graph.Add(task.Dependency{TaskId: 1, BlockedById: 3}), task.ErrDependencyCycle)
+		assert.ErrorIs(t, graph.Add(task.Dependency{TaskId: 1, BlockedById: 1}), task.ErrSelfDependency)
+		assert.NoError(t, graph.Add(task.Dependency{TaskId: 3, BlockedById: 1}))
+	})
+
+	t.Run("chain is topologically ordered", func(t *testing.T) {
+		graph := task.NewDependencyGraph([]task.Dependency{
+			{TaskId: 4, BlockedById: 3},
+			{TaskId: 4, BlockedById: 2},
+			{TaskId: 3, BlockedById: 1},
+			{TaskId: 2, BlockedById: 1},
+		})
+
+		assert.Equal(t, []task.TaskId{1, 2, 3}, graph.Chain(4))
+		assert.Empty(t, graph.Chain(1))
+	})
+
+	t.Run("open blockers prevent completion", func(t *testing.T) {
+		blocker := task.NewTask("blocker", user.UserId(1), "2024-01-01")
+		blocked := task.NewTask("blocked", user.UserId(1), "2024-01-02")
+		blocked.BlockedBy = []*task.Task{blocker}
+
+		assert.ErrorIs(t, blocked.SetStatus(task.StatusComplete), task.ErrOpenBlockers)
+		assert.NoError(t, blocker.SetStatus(task.StatusComplete))
+		assert.NoError(t, blocked.SetStatus(task.StatusComplete))
+	})
+}
+
+func TestExtendDueDate(t *testing.T) {
+	t.Run("extend", func(t *testing.T) {
+		target := task.NewTask("test", user.UserId(1), "2024-01-01")
+		assert.NoError(t, target.ExtendDueDate("2024-01-03", task.DelayPolicy{}))
+		assert.Equal(t, "2024-01-03", target.DueDate)
+		assert.Equal(t, "2024-01-01", target.OriginalDueDate)
+		assert.Equal(t, 1, target.DelayCount)
+	})
+
+	t.Run("must be later", func(t *testing.T) {
+		target := task.NewTask("test", user.UserId(1), "2024-01-01")
+		assert.EqualError(t, target.ExtendDueDate("2024-01-01", task.DelayPolicy{}), "due date must be later than current due date")
+		assert.EqualError(t, target.ExtendDueDate("2024/01/02", task.DelayPolicy{}), "invalid due date")
+	})
+
+	t.Run("max extensions", func(t *testing.T) {
+		policy := task.DelayPolicy{MaxExtensions: 2}
+		target := task.NewTask("test", user.UserId(1), "2024-01-01")
+		assert.NoError(t, target.ExtendDueDate("2024-01-02", policy))
+		assert.NoError(t, target.ExtendDueDate("2024-01-03", policy))
+
+		err := target.ExtendDueDate("2024-01-04", policy)
+		var delayErr *task.DelayPolicyError
+		assert.ErrorAs(t, err, &delayErr)
+		assert.Equal(t, task.DelayRuleMaxExtensions, delayErr.Rule)
+		assert.Equal(t, "2024-01-03", target.DueDate)
+		assert.Equal(t, 2, target.DelayCount)
+	})
+
+	t.Run("max cumulative delay days", func(t *testing.T) {
+		policy := task.DelayPolicy{MaxDelayDays: 7}
+		target := task.NewTask("test", user.UserId(1), "2024-01-01")
+		assert.NoError(t, target.ExtendDueDate("2024-01-05", policy))
+		assert.NoError(t, target.ExtendDueDate("2024-01-08", policy))
+
+		err := target.ExtendDueDate("2024-01-09", policy)
+		var delayErr *task.DelayPolicyError
+		assert.ErrorAs(t, err, &delayErr)
+		assert.Equal(t, task.DelayRuleMaxDelayDays, delayErr.Rule)
+		assert.Equal(t, 7, delayErr.Limit)
+	})
+
+	t.Run("completed task", func(t *testing.T) {
+		target := task.NewTask("test", user.UserId(1), "2024-01-01")
+		assert.NoError(t, target.SetStatus(task.StatusComplete))
+		assert.ErrorIs(t, target.ExtendDueDate("2024-01-02", task.DelayPolicy{}), task.ErrCompletedTask)
+	})
+}
+
+func TestIsOverdue(t *testing.T) {
+	target := task.NewTask("test", user.UserId(1), "2024-01-10")
+
+	assert.False(t, target.IsOverdue(time.Date(2024, 1, 9, 12, 0, 0, 0, time.UTC)))
+	assert.False(t, target.IsOverdue(time.Date(2024, 1, 10, 23, 59, 0, 0, time.UTC)))
+	assert.True(t, target.IsOverdue(time.Date(2024, 1, 11, 0, 0, 0, 0, time.UTC)))
+
+	assert.NoError(t, target.SetStatus(task.StatusComplete))
+	assert.False(t, target.IsOverdue(time.Date(2024, 1, 11, 0, 0, 0, 0, time.UTC)))
+}
+
+func TestShareWith(t *testing.T) {
+	target := task.NewTask("test", user.UserId(1), "2024-01-10")
+	target.Id = task.TaskId(1)
+
+	assert.True(t, target.IsAccessibleBy(user.UserId(1)))
+	assert.False(t, target.IsAccessibleBy(user.UserId(2)))
+
+	share, err := target.ShareWith(user.UserId(2))
+	assert.NoError(t, err)
+	assert.Equal(t, task.Share{TaskId: 1, UserId: 2}, share)
+	assert.True(t, target.IsAccessibleBy(user.UserId(2)))
+	assert.False(t, target.IsOwnedBy(user.UserId(2)))
+
+	_, err = target.ShareWith(user.UserId(1))
+	var validationErr *task.ValidationError
+	assert.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, "user_id", validationErr.Field)
+}
+
+func TestReassign(t *testing.T) {
+	owner := user.UserId(1)
+	shared := user.UserId(2)
+	stranger := user.UserId(3)
+	now := time.Date(2024, 1, 5, 9, 0, 0, 0, time.UTC)
+
+	// 個人のタスクは所有者か共有されたユーザーのみ担当者にできる
+	personal := task.NewTask("test", owner, "2024-01-10")
+	personal.Id = task.TaskId(1)
+	personal.Shares = []task.Share{{TaskId: 1, UserId: shared}}
+	assert.Equal(t, owner, personal.Recipient())
+
+	assignment, err := personal.Reassign(&shared, nil, owner, now)
+	assert.NoError(t, err)
+	assert.Equal(t, &task.Assignment{TaskId: 1, ToUserId: &shared, ChangedBy: owner, ChangedAt: now}, assignment)
+	assert.Equal(t, &shared, personal.AssigneeId)
+	assert.Equal(t, shared, personal.Recipient())
+	assert.Equal(t, owner, personal.CreatedBy)
+
+	// 担当者が変わらない場合は履歴を残さない
+	same := shared
+	assignment, err = personal.Reassign(&same, nil, owner, now)
+	assert.NoError(t, err)
+	assert.Nil(t, assignment)
+
+	var validationErr *task.ValidationError
+	_, err = personal.Reassign(&stranger, nil, owner, now)
+	assert.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, "assignee_id", validationErr.Field)
+
+	assignment, err = personal.Reassign(nil, nil, shared, now)
+	assert.NoError(t, err)
+	assert.Equal(t, &shared, assignment.FromUserId)
+	assert.Nil(t, assignment.ToUserId)
+	assert.Nil(t, personal.AssigneeId)
+
+	// ワークスペースのタスクはメンバーのみ担当者にできる
+	ws, _ := workspace.NewWorkspace("team", owner, now)
+	ws.Id = workspace.WorkspaceId(1)
+	ws.Members = append(ws.Members, workspace.Member{WorkspaceId: 1, UserId: stranger, Role: workspace.RoleViewer})
+	teamTask := task.NewTask("test", owner, "2024-01-10")
+	teamTask.WorkspaceId = &ws.Id
+	_, err = teamTask.Reassign(&stranger, ws, owner, now)
+	assert.NoError(t, err)
+	_, err = teamTask.Reassign(&shared, ws, owner, now)
+	assert.ErrorAs(t, err, &validationErr)
+	_, err = teamTask.Reassign(&stranger, nil, owner, now)
+	assert.ErrorAs(t, err, &validationErr)
+
+	// ワークスペースのタスクは共有できない
+	_, err = teamTask.ShareWith(shared)
+	assert.ErrorAs(t, err, &validationErr)
 }
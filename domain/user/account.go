@@ -0,0 +1,56 @@
+package user
+
+import (
+	"errors"
+	"time"
+	"unicode/utf8"
+)
+
+var (
+	ErrInvalidCredentials = errors.New("invalid email or password")
+	ErrAccountLocked      = errors.New("account is temporarily locked")
+	ErrEmailTaken         = errors.New("email is already registered")
+	ErrWeakPassword       = errors.New("password must be between 8 and 72 characters")
+)
+
+// パスワードの長さの制限
+// bcryptは72バイトを超える部分を無視するため上限を設ける
+const (
+	MinPasswordLength = 8
+	MaxPasswordLength = 72
+)
+
+// ValidatePassword はパスワードが長さの制限を満たすかを確認する
+func ValidatePassword(password string) error {
+	if utf8.RuneCountInString(password) < MinPasswordLength || len(password) > MaxPasswordLength {
+		return ErrWeakPassword
+	}
+	return nil
+}
+
+// LockoutPolicy はログインの失敗が続いた場合にアカウントをロックする設定
+// MaxFailuresが0の場合はロックしない
+type LockoutPolicy struct {
+	MaxFailures int
+	Duration    time.Duration
+}
+
+var DefaultLockoutPolicy = LockoutPolicy{
+	MaxFailures: 5,
+	Duration:    15 * time.Minute,
+}
+
+// IsLocked はロック中かどうかを返す
+func (u *User) IsLocked(now time.Time) bool {
+	return u.LockedUntil != nil && now.Before(*u.LockedUntil)
+}
+
+// RecordLoginFailure はログインの失敗を記録し、上限に達した場合はロックする
+func (u *User) RecordLoginFailure(now time.Time, policy LockoutPolicy) {
+	u.FailedLogins++
+	if policy.MaxFailures > 0 && u.FailedLogins >= policy.MaxFailures {
+		until := now.Add(policy.Duration)
+		u.LockedUntil = &until
+		u.FailedLogins = 0
+	}
+}
@@ -0,0 +1,126 @@
+package user
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidApiKey    = errors.New("invalid api key")
+	ErrInvalidScope     = errors.New("invalid scope")
+	ErrInvalidApiKeyTTL = errors.New("expires_at must be in the future")
+)
+
+// Scope はAPIキーで許可する操作の範囲
+type Scope string
+
+const (
+	ScopeTasksRead  Scope = "tasks:read"
+	ScopeTasksWrite Scope = "tasks:write"
+)
+
+// ApiKeyPrefix はAPIキーの先頭に付ける識別子
+// アクセストークンと区別するために使う
+const ApiKeyPrefix = "tk_"
+
+// ApiKey はスクリプトやCIなど対話的にログインしない利用者のためのキー
+// キー自体は保存せず、ハッシュと識別用の先頭部分のみを保存する
+type ApiKey struct {
+	Id         int        `json:"id" gorm:"primaryKey"`
+	UserId     UserId     `json:"user_id" gorm:"index"`
+	Name       string     `json:"name" gorm:"size:100"`
+	Prefix     string     `json:"prefix" gorm:"size:16"`
+	KeyHash    string     `json:"-" gorm:"uniqueIndex;size:64"`
+	Scopes     []Scope    `json:"scopes" gorm:"serializer:json"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// ParseScopes はスコープを検証し、重複を除いて返す
+func ParseScopes(values []string) ([]Scope, error) {
+	if len(values) == 0 {
+		return nil, ErrInvalidScope
+	}
+	scopes := make([]Scope, 0, len(values))
+	seen := map[Scope]bool{}
+	for _, v := range values {
+		scope := Scope(v)
+		if scope != ScopeTasksRead && scope != ScopeTasksWrite {
+			return nil, ErrInvalidScope
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes, nil
+}
+
+// NewApiKey はキーを生成し、利用者に渡すキーと保存する記録を返す
+// expiresAtがnilの場合は失効させるまで有効になる
+func NewApiKey(userId UserId, name string, scopes []Scope, expiresAt *time.Time, now time.Time) (string, *ApiKey, error) {
+	if len(scopes) == 0 {
+		return "", nil, ErrInvalidScope
+	}
+	if expiresAt != nil && !expiresAt.After(now) {
+		return "", nil, ErrInvalidApiKeyTTL
+	}
+
+	id := make([]byte, 4)
+	if _, err := rand.Read(id); err != nil {
+		return "", nil, err
+	}
+	secret, err := randomToken()
+	if err != nil {
+		return "", nil, err
+	}
+	prefix := ApiKeyPrefix + hex.EncodeToString(id)
+	raw := prefix + "_" + secret
+
+	return raw, &ApiKey{
+		UserId:    userId,
+		Name:      strings.TrimSpace(name),
+		Prefix:    prefix,
+		KeyHash:   HashApiKey(raw),
+		Scopes:    scopes,
+		ExpiresAt: expiresAt,
+		CreatedAt: now,
+	}, nil
+}
+
+// HashApiKey は保存用のキーのハッシュを返す
+func HashApiKey(raw string) string {
+	return HashRefreshToken(raw)
+}
+
+// IsApiKey はBearerトークンがAPIキーの形式かどうかを返す
+func IsApiKey(token string) bool {
+	return strings.HasPrefix(token, ApiKeyPrefix)
+}
+
+// IsActive は失効しておらず有効期限内かどうかを返す
+func (k *ApiKey) IsActive(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
+
+// HasScope は指定した操作が許可されているかを返す
+func (k *ApiKey) HasScope(scope Scope) bool {
+	for _, s := range k.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// Revoke はキーを失効させる
+func (k *ApiKey) Revoke(now time.Time) {
+	if k.RevokedAt == nil {
+		k.RevokedAt = &now
+	}
+}
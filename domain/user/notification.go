@@ -0,0 +1,98 @@
+package user
+
+import (
+	"errors"
+	"net/mail"
+	"net/url"
+)
+
+var (
+	ErrInvalidLocale     = errors.New("invalid locale")
+	ErrInvalidEmail      = errors.New("invalid email address")
+	ErrInvalidWebhookURL = errors.New("invalid webhook url")
+	ErrInvalidChannel    = errors.New("invalid notification channel")
+)
+
+type NotificationChannel string
+
+const (
+	ChannelEmail   NotificationChannel = "email"
+	ChannelWebhook NotificationChannel = "webhook"
+	ChannelSlack   NotificationChannel = "slack"
+)
+
+type Locale string
+
+const (
+	LocaleJa Locale = "ja"
+	LocaleEn Locale = "en"
+)
+
+// NotificationSettings はユーザーごとの通知設定
+type NotificationSettings struct {
+	UserId      UserId                   `json:"user_id" gorm:"primaryKey;autoIncrement:false"`
+	Locale      Locale                   `json:"locale"`
+	Preferences []NotificationPreference `json:"preferences" gorm:"foreignKey:UserId;references:UserId"`
+}
+
+// NotificationPreference は通知先の設定
+// Targetはチャネルに応じてメールアドレスかURLを保持する
+type NotificationPreference struct {
+	Id      int                 `json:"id" gorm:"primaryKey"`
+	UserId  UserId              `json:"user_id" gorm:"index"`
+	Channel NotificationChannel `json:"channel"`
+	Target  string              `json:"target"`
+	Enabled bool                `json:"enabled"`
+}
+
+// NewNotificationSettings は既定の通知設定を生成する
+// 既定では日本語で、通知先は持たない
+func NewNotificationSettings(userId UserId) *NotificationSettings {
+	return &NotificationSettings{
+		UserId: userId,
+		Locale: LocaleJa,
+	}
+}
+
+func (s *NotificationSettings) Validate() error {
+	if s.UserId == 0 {
+		return errors.New("invalid user id")
+	}
+	if s.Locale != LocaleJa && s.Locale != LocaleEn {
+		return ErrInvalidLocale
+	}
+	for _, p := range s.Preferences {
+		if err := p.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *NotificationPreference) Validate() error {
+	switch p.Channel {
+	case ChannelEmail:
+		if _, err := mail.ParseAddress(p.Target); err != nil {
+			return ErrInvalidEmail
+		}
+	case ChannelWebhook, ChannelSlack:
+		u, err := url.Parse(p.Target)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return ErrInvalidWebhookURL
+		}
+	default:
+		return ErrInvalidChannel
+	}
+	return nil
+}
+
+// EnabledPreferences は有効な通知先のみを返す
+func (s *NotificationSettings) EnabledPreferences() []NotificationPreference {
+	var enabled []NotificationPreference
+	for _, p := range s.Preferences {
+		if p.Enabled {
+			enabled = append(enabled, p)
+		}
+	}
+	return enabled
+}
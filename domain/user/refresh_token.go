@@ -0,0 +1,72 @@
+package user
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"time"
+)
+
+var ErrInvalidRefreshToken = errors.New("invalid refresh token")
+
+// RefreshToken はアクセストークンを再発行するためのトークン
+// トークン自体は保存せず、ハッシュのみを保存する
+// 同じログインから発行されたトークンは同じFamilyIdを持ち、再利用を検知した場合はまとめて失効させる
+type RefreshToken struct {
+	Id        int        `json:"id" gorm:"primaryKey"`
+	UserId    UserId     `json:"user_id" gorm:"index"`
+	FamilyId  string     `json:"family_id" gorm:"index;size:64"`
+	TokenHash string     `json:"-" gorm:"uniqueIndex;size:64"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	RevokedAt *time.Time `json:"revoked_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// NewRefreshToken はトークンを生成し、利用者に渡すトークンと保存する記録を返す
+// familyIdが空の場合は新しいログインとして扱う
+func NewRefreshToken(userId UserId, familyId string, now time.Time, ttl time.Duration) (string, *RefreshToken, error) {
+	raw, err := randomToken()
+	if err != nil {
+		return "", nil, err
+	}
+	if familyId == "" {
+		if familyId, err = randomToken(); err != nil {
+			return "", nil, err
+		}
+	}
+	return raw, &RefreshToken{
+		UserId:    userId,
+		FamilyId:  familyId,
+		TokenHash: HashRefreshToken(raw),
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+	}, nil
+}
+
+// HashRefreshToken は保存用のトークンのハッシュを返す
+func HashRefreshToken(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
+
+// IsActive は失効しておらず有効期限内かどうかを返す
+func (t *RefreshToken) IsActive(now time.Time) bool {
+	return t.RevokedAt == nil && now.Before(t.ExpiresAt)
+}
+
+// Revoke はトークンを失効させる
+func (t *RefreshToken) Revoke(now time.Time) {
+	if t.RevokedAt == nil {
+		t.RevokedAt = &now
+	}
+}
+
+func randomToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
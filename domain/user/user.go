@@ -2,17 +2,33 @@ package user
 
 import (
 	"errors"
+	"net/mail"
+	"strings"
+	"time"
 )
 
+// ErrForbidden は他のユーザーの情報を操作しようとしたことを表す
+var ErrForbidden = errors.New("forbidden")
+
 type User struct {
-	Id   UserId
-	Name string
+	Id           UserId     `json:"id" gorm:"primaryKey"`
+	Name         string     `json:"name"`
+	Email        string     `json:"email" gorm:"uniqueIndex;size:255"`
+	PasswordHash string     `json:"-"`
+	FailedLogins int        `json:"-"`
+	LockedUntil  *time.Time `json:"-"`
+	CreatedAt    time.Time  `json:"created_at"`
 }
 
 func (u *User) Validate() error {
 	if u.Name == "" {
 		return errors.New("invalid name")
 	}
+	if u.Email != "" {
+		if _, err := mail.ParseAddress(u.Email); err != nil {
+			return ErrInvalidEmail
+		}
+	}
 	return nil
 }
 
@@ -22,3 +38,19 @@ func NewUser(id UserId, name string) *User {
 		Name: name,
 	}
 }
+
+// NewAccount はメールアドレスでログインするユーザーを生成する
+// メールアドレスは大文字小文字を区別しない
+func NewAccount(name, email string) (*User, error) {
+	u := &User{
+		Name:  strings.TrimSpace(name),
+		Email: strings.ToLower(strings.TrimSpace(email)),
+	}
+	if u.Email == "" {
+		return nil, ErrInvalidEmail
+	}
+	if err := u.Validate(); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
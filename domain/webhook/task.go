@@ -0,0 +1,47 @@
+package webhook
+
+import (
+	"github.com/fuki01/onion-architecture/domain/task"
+	"github.com/fuki01/onion-architecture/domain/user"
+	"github.com/fuki01/onion-architecture/domain/workspace"
+)
+
+// TaskData はタスクのイベントで送るタスクの内容
+// 共有先などの内部の情報を送らないよう、送る項目を明示する
+type TaskData struct {
+	Id              task.TaskId            `json:"id"`
+	Name            string                 `json:"name"`
+	Description     string                 `json:"description"`
+	UserId          user.UserId            `json:"user_id"`
+	DueDate         string                 `json:"due_date"`
+	Status          string                 `json:"status"`
+	Priority        string                 `json:"priority"`
+	EstimateMinutes int                    `json:"estimate_minutes"`
+	Tags            []string               `json:"tags"`
+	DelayCount      int                    `json:"delay_count"`
+	ParentId        *task.TaskId           `json:"parent_id"`
+	Recurrence      string                 `json:"recurrence"`
+	Occurrence      int                    `json:"occurrence"`
+	WorkspaceId     *workspace.WorkspaceId `json:"workspace_id"`
+	AssigneeId      *user.UserId           `json:"assignee_id"`
+}
+
+func NewTaskData(t *task.Task) TaskData {
+	return TaskData{
+		Id:              t.Id,
+		Name:            t.Name,
+		Description:     t.Description,
+		UserId:          t.CreatedBy,
+		DueDate:         t.DueDate,
+		Status:          string(t.Status),
+		Priority:        string(t.Priority),
+		EstimateMinutes: t.EstimateMinutes,
+		Tags:            t.TagNames(),
+		DelayCount:      t.DelayCount,
+		ParentId:        t.ParentId,
+		Recurrence:      t.Recurrence,
+		Occurrence:      t.Occurrence,
+		WorkspaceId:     t.WorkspaceId,
+		AssigneeId:      t.AssigneeId,
+	}
+}
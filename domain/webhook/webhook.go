@@ -0,0 +1,150 @@
+package webhook
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"time"
+
+	"github.com/fuki01/onion-architecture/domain/user"
+)
+
+type SubscriptionId int
+
+type DeliveryId int
+
+type EventType string
+
+const (
+	EventTaskCreated   EventType = "task.created"
+	EventTaskExtended  EventType = "task.extended"
+	EventTaskCompleted EventType = "task.completed"
+)
+
+var (
+	ErrInvalidURL       = errors.New("invalid webhook url")
+	ErrNonPublicURL     = errors.New("webhook url must resolve to a public address")
+	ErrInvalidEventType = errors.New("invalid event type")
+)
+
+// IsValid は定義済みのイベント種別かどうかを返す
+func (e EventType) IsValid() bool {
+	switch e {
+	case EventTaskCreated, EventTaskExtended, EventTaskCompleted:
+		return true
+	}
+	return false
+}
+
+// Subscription はイベントの送信先
+// 登録したユーザーが参照できるタスクのイベントのみを送る
+type Subscription struct {
+	Id         SubscriptionId `json:"id" gorm:"primaryKey"`
+	UserId     user.UserId    `json:"user_id" gorm:"index"`
+	URL        string         `json:"url"`
+	Secret     string         `json:"-"`
+	EventTypes []EventType    `json:"event_types" gorm:"serializer:json"`
+	CreatedAt  time.Time      `json:"created_at"`
+}
+
+// NewSubscription は送信先を生成する
+// secretが空の場合はランダムな値を生成する
+func NewSubscription(userId user.UserId, rawURL, secret string, eventTypes []EventType) (*Subscription, error) {
+	if secret == "" {
+		var err error
+		if secret, err = newSecret(); err != nil {
+			return nil, err
+		}
+	}
+	s := &Subscription{
+		UserId:     userId,
+		URL:        rawURL,
+		Secret:     secret,
+		EventTypes: eventTypes,
+	}
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *Subscription) Validate() error {
+	u, err := url.Parse(s.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return ErrInvalidURL
+	}
+	if len(s.EventTypes) == 0 {
+		return ErrInvalidEventType
+	}
+	for _, e := range s.EventTypes {
+		if !e.IsValid() {
+			return ErrInvalidEventType
+		}
+	}
+	return nil
+}
+
+// Subscribes は指定したイベントを購読しているかを返す
+func (s *Subscription) Subscribes(eventType EventType) bool {
+	for _, e := range s.EventTypes {
+		if e == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+func newSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// Event は送信先に通知する出来事
+type Event struct {
+	Type       EventType   `json:"type"`
+	OccurredAt time.Time   `json:"occurred_at"`
+	Data       interface{} `json:"data"`
+}
+
+// Delivery は送信先へのイベントの配送記録
+type Delivery struct {
+	Id             DeliveryId     `json:"id" gorm:"primaryKey"`
+	SubscriptionId SubscriptionId `json:"subscription_id" gorm:"index"`
+	EventType      EventType      `json:"event_type"`
+	Payload        string         `json:"payload" gorm:"type:text"`
+	Attempts       int            `json:"attempts"`
+	StatusCode     int            `json:"status_code"`
+	Error          string         `json:"error" gorm:"type:text"`
+	Succeeded      bool           `json:"succeeded"`
+	RedeliveryOf   *DeliveryId    `json:"redelivery_of"`
+	CreatedAt      time.Time      `json:"created_at"`
+	LastAttemptAt  *time.Time     `json:"last_attempt_at"`
+}
+
+// RecordAttempt は配送の試行結果を記録する
+// 2xxの応答を受けた場合に成功とする
+func (d *Delivery) RecordAttempt(at time.Time, statusCode int, err error) {
+	d.Attempts++
+	d.LastAttemptAt = &at
+	d.StatusCode = statusCode
+	d.Error = ""
+	if err != nil {
+		d.Error = err.Error()
+	}
+	d.Succeeded = err == nil && statusCode >= 200 && statusCode < 300
+}
+
+// Redelivery は同じ内容を再送するための配送記録を生成する
+func (d *Delivery) Redelivery() *Delivery {
+	original := d.Id
+	return &Delivery{
+		SubscriptionId: d.SubscriptionId,
+		EventType:      d.EventType,
+		Payload:        d.Payload,
+		RedeliveryOf:   &original,
+	}
+}
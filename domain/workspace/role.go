@@ -0,0 +1,48 @@
+package workspace
+
+// Role はワークスペースでのメンバーの役割
+type Role string
+
+const (
+	RoleOwner  Role = "owner"
+	RoleAdmin  Role = "admin"
+	RoleMember Role = "member"
+	RoleViewer Role = "viewer"
+)
+
+// Action は役割によって許可される操作
+type Action int
+
+const (
+	// ActionViewTasks はタスクの参照
+	ActionViewTasks Action = iota
+	// ActionEditTasks はタスクの登録・更新・担当者の割り当て
+	ActionEditTasks
+	// ActionManageMembers は所有者以外のメンバーの追加・変更・削除
+	ActionManageMembers
+	// ActionManageOwners は所有者の追加・変更・削除
+	ActionManageOwners
+)
+
+var permissions = map[Role][]Action{
+	RoleOwner:  {ActionViewTasks, ActionEditTasks, ActionManageMembers, ActionManageOwners},
+	RoleAdmin:  {ActionViewTasks, ActionEditTasks, ActionManageMembers},
+	RoleMember: {ActionViewTasks, ActionEditTasks},
+	RoleViewer: {ActionViewTasks},
+}
+
+// IsValid は定義済みの役割かどうかを返す
+func (r Role) IsValid() bool {
+	_, ok := permissions[r]
+	return ok
+}
+
+// Can は役割に操作が許可されているかを返す
+func (r Role) Can(action Action) bool {
+	for _, a := range permissions[r] {
+		if a == action {
+			return true
+		}
+	}
+	return false
+}
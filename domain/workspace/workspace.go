@@ -0,0 +1,173 @@
+package workspace
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/fuki01/onion-architecture/domain/user"
+)
+
+type WorkspaceId int
+
+var (
+	ErrInvalidName   = errors.New("invalid workspace name")
+	ErrInvalidRole   = errors.New("invalid role")
+	ErrForbidden     = errors.New("forbidden")
+	ErrNotMember     = errors.New("user is not a member of the workspace")
+	ErrAlreadyMember = errors.New("user is already a member of the workspace")
+	ErrLastOwner     = errors.New("workspace must have at least one owner")
+)
+
+// Workspace は複数のユーザーでタスクを共有する単位
+type Workspace struct {
+	Id        WorkspaceId `json:"id" gorm:"primaryKey"`
+	Name      string      `json:"name"`
+	CreatedAt time.Time   `json:"created_at"`
+	Members   []Member    `json:"members" gorm:"foreignKey:WorkspaceId"`
+}
+
+// Member はワークスペースに参加しているユーザーとその役割
+type Member struct {
+	WorkspaceId WorkspaceId `json:"workspace_id" gorm:"primaryKey"`
+	UserId      user.UserId `json:"user_id" gorm:"primaryKey"`
+	Role        Role        `json:"role" gorm:"size:16"`
+	CreatedAt   time.Time   `json:"created_at"`
+}
+
+func (Member) TableName() string {
+	return "workspace_members"
+}
+
+// NewWorkspace はワークスペースを生成する
+// 作成したユーザーが所有者になる
+func NewWorkspace(name string, owner user.UserId, now time.Time) (*Workspace, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > 100 {
+		return nil, ErrInvalidName
+	}
+	return &Workspace{
+		Name:      name,
+		CreatedAt: now,
+		Members:   []Member{{UserId: owner, Role: RoleOwner, CreatedAt: now}},
+	}, nil
+}
+
+// RoleOf はユーザーの役割を返す
+// メンバーでない場合はfalseを返す
+func (w *Workspace) RoleOf(userId user.UserId) (Role, bool) {
+	for _, m := range w.Members {
+		if m.UserId == userId {
+			return m.Role, true
+		}
+	}
+	return "", false
+}
+
+// IsMember はユーザーがメンバーかどうかを返す
+func (w *Workspace) IsMember(userId user.UserId) bool {
+	_, ok := w.RoleOf(userId)
+	return ok
+}
+
+// Authorize はユーザーが操作を行えるかを確認する
+func (w *Workspace) Authorize(userId user.UserId, action Action) error {
+	role, ok := w.RoleOf(userId)
+	if !ok || !role.Can(action) {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// AddMember はメンバーを追加する
+// 所有者を追加できるのは所有者のみ
+func (w *Workspace) AddMember(actor, userId user.UserId, role Role, now time.Time) (Member, error) {
+	if !role.IsValid() {
+		return Member{}, ErrInvalidRole
+	}
+	if err := w.authorizeRole(actor, role); err != nil {
+		return Member{}, err
+	}
+	if w.IsMember(userId) {
+		return Member{}, ErrAlreadyMember
+	}
+
+	m := Member{WorkspaceId: w.Id, UserId: userId, Role: role, CreatedAt: now}
+	w.Members = append(w.Members, m)
+	return m, nil
+}
+
+// ChangeRole はメンバーの役割を変更する
+// 所有者の役割を変更できるのは所有者のみで、最後の所有者は変更できない
+func (w *Workspace) ChangeRole(actor, userId user.UserId, role Role) (Member, error) {
+	if !role.IsValid() {
+		return Member{}, ErrInvalidRole
+	}
+	i, err := w.indexOf(userId)
+	if err != nil {
+		return Member{}, err
+	}
+	if err := w.authorizeRole(actor, role); err != nil {
+		return Member{}, err
+	}
+	if err := w.authorizeRole(actor, w.Members[i].Role); err != nil {
+		return Member{}, err
+	}
+	if w.Members[i].Role == RoleOwner && role != RoleOwner && w.countOwners() == 1 {
+		return Member{}, ErrLastOwner
+	}
+
+	w.Members[i].Role = role
+	return w.Members[i], nil
+}
+
+// RemoveMember はメンバーを削除する
+// メンバーは自分自身を削除してワークスペースから抜けられる
+func (w *Workspace) RemoveMember(actor, userId user.UserId) (Member, error) {
+	i, err := w.indexOf(userId)
+	if err != nil {
+		return Member{}, err
+	}
+	target := w.Members[i]
+	if actor != userId {
+		if err := w.authorizeRole(actor, target.Role); err != nil {
+			return Member{}, err
+		}
+	}
+	if target.Role == RoleOwner && w.countOwners() == 1 {
+		return Member{}, ErrLastOwner
+	}
+
+	w.Members = append(w.Members[:i], w.Members[i+1:]...)
+	return target, nil
+}
+
+// authorizeRole はユーザーが指定した役割のメンバーを管理できるかを確認する
+func (w *Workspace) authorizeRole(actor user.UserId, role Role) error {
+	if err := w.Authorize(actor, ActionManageMembers); err != nil {
+		return err
+	}
+	if role == RoleOwner {
+		return w.Authorize(actor, ActionManageOwners)
+	}
+	return nil
+}
+
+func (w *Workspace) indexOf(userId user.UserId) (int, error) {
+	for i, m := range w.Members {
+		if m.UserId == userId {
+			return i, nil
+		}
+	}
+	return 0, ErrNotMember
+}
+
+func (w *Workspace) countOwners() int {
+	count := 0
+	for _, m := range w.Members {
+		if m.Role == RoleOwner {
+			count++
+		}
+	}
+	return count
+}
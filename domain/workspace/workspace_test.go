@@ -0,0 +1,140 @@
+package workspace_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fuki01/onion-architecture/domain/user"
+	"github.com/fuki01/onion-architecture/domain/workspace"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoleCan(t *testing.T) {
+	testCases := []struct {
+		role    workspace.Role
+		allowed []workspace.Action
+		denied  []workspace.Action
+	}{
+		{
+			role:    workspace.RoleOwner,
+			allowed: []workspace.Action{workspace.ActionViewTasks, workspace.ActionEditTasks, workspace.ActionManageMembers, workspace.ActionManageOwners},
+		},
+		{
+			role:    workspace.RoleAdmin,
+			allowed: []workspace.Action{workspace.ActionViewTasks, workspace.ActionEditTasks, workspace.ActionManageMembers},
+			denied:  []workspace.Action{workspace.ActionManageOwners},
+		},
+		{
+			role:    workspace.RoleMember,
+			allowed: []workspace.Action{workspace.ActionViewTasks, workspace.ActionEditTasks},
+			denied:  []workspace.Action{workspace.ActionManageMembers, workspace.ActionManageOwners},
+		},
+		{
+			role:    workspace.RoleViewer,
+			allowed: []workspace.Action{workspace.ActionViewTasks},
+			denied:  []workspace.Action{workspace.ActionEditTasks, workspace.ActionManageMembers, workspace.ActionManageOwners},
+		},
+		{
+			role:   workspace.Role("guest"),
+			denied: []workspace.Action{workspace.ActionViewTasks},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(string(tc.role), func(t *testing.T) {
+			for _, action := range tc.allowed {
+				assert.True(t, tc.role.Can(action))
+			}
+			for _, action := range tc.denied {
+				assert.False(t, tc.role.Can(action))
+			}
+		})
+	}
+}
+
+func TestMembers(t *testing.T) {
+	owner := user.UserId(1)
+	admin := user.UserId(2)
+	member := user.UserId(3)
+	outsider := user.UserId(4)
+	now := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+
+	newWorkspace := func() *workspace.Workspace {
+		ws, err := workspace.NewWorkspace("team", owner, now)
+		require.NoError(t, err)
+		ws.Id = workspace.WorkspaceId(1)
+		_, err = ws.AddMember(owner, admin, workspace.RoleAdmin, now)
+		require.NoError(t, err)
+		_, err = ws.AddMember(admin, member, workspace.RoleMember, now)
+		require.NoError(t, err)
+		return ws
+	}
+
+	t.Run("creator is owner", func(t *testing.T) {
+		ws := newWorkspace()
+		role, ok := ws.RoleOf(owner)
+		assert.True(t, ok)
+		assert.Equal(t, workspace.RoleOwner, role)
+		_, ok = ws.RoleOf(outsider)
+		assert.False(t, ok)
+	})
+
+	t.Run("invalid name", func(t *testing.T) {
+		_, err := workspace.NewWorkspace(" ", owner, now)
+		assert.ErrorIs(t, err, workspace.ErrInvalidName)
+	})
+
+	t.Run("add member", func(t *testing.T) {
+		ws := newWorkspace()
+		_, err := ws.AddMember(member, outsider, workspace.RoleViewer, now)
+		assert.ErrorIs(t, err, workspace.ErrForbidden)
+		_, err = ws.AddMember(admin, outsider, workspace.RoleOwner, now)
+		assert.ErrorIs(t, err, workspace.ErrForbidden)
+		_, err = ws.AddMember(admin, member, workspace.RoleViewer, now)
+		assert.ErrorIs(t, err, workspace.ErrAlreadyMember)
+		_, err = ws.AddMember(admin, outsider, workspace.Role("guest"), now)
+		assert.ErrorIs(t, err, workspace.ErrInvalidRole)
+
+		m, err := ws.AddMember(admin, outsider, workspace.RoleViewer, now)
+		require.NoError(t, err)
+		assert.Equal(t, workspace.Member{WorkspaceId: 1, UserId: outsider, Role: workspace.RoleViewer, CreatedAt: now}, m)
+	})
+
+	t.Run("change role", func(t *testing.T) {
+		ws := newWorkspace()
+		_, err := ws.ChangeRole(admin, owner, workspace.RoleMember)
+		assert.ErrorIs(t, err, workspace.ErrForbidden)
+		_, err = ws.ChangeRole(owner, owner, workspace.RoleMember)
+		assert.ErrorIs(t, err, workspace.ErrLastOwner)
+		_, err = ws.ChangeRole(owner, outsider, workspace.RoleMember)
+		assert.ErrorIs(t, err, workspace.ErrNotMember)
+
+		m, err := ws.ChangeRole(admin, member, workspace.RoleViewer)
+		require.NoError(t, err)
+		assert.Equal(t, workspace.RoleViewer, m.Role)
+
+		// 所有者が二人いれば自分の役割を変更できる
+		_, err = ws.ChangeRole(owner, admin, workspace.RoleOwner)
+		require.NoError(t, err)
+		_, err = ws.ChangeRole(owner, owner, workspace.RoleMember)
+		assert.NoError(t, err)
+	})
+
+	t.Run("remove member", func(t *testing.T) {
+		ws := newWorkspace()
+		_, err := ws.RemoveMember(member, admin)
+		assert.ErrorIs(t, err, workspace.ErrForbidden)
+		_, err = ws.RemoveMember(owner, owner)
+		assert.ErrorIs(t, err, workspace.ErrLastOwner)
+
+		// メンバーは自分から抜けられる
+		_, err = ws.RemoveMember(member, member)
+		require.NoError(t, err)
+		assert.False(t, ws.IsMember(member))
+
+		_, err = ws.RemoveMember(admin, admin)
+		require.NoError(t, err)
+		assert.Len(t, ws.Members, 1)
+	})
+}
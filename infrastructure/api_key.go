@@ -0,0 +1,73 @@
+package infrastructure
+
+// api_key_repositoryの実装
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/fuki01/onion-architecture/domain/repository"
+	"github.com/fuki01/onion-architecture/domain/user"
+)
+
+type apiKeyPersistence struct {
+	db *gorm.DB
+}
+
+func NewApiKeyPersistence(db *gorm.DB) repository.ApiKeyRepository {
+	return &apiKeyPersistence{
+		db: db,
+	}
+}
+
+// FindById は指定したIDのキーを取得する
+func (ap *apiKeyPersistence) FindById(id int) (*user.ApiKey, error) {
+	var k user.ApiKey
+	if err := ap.db.First(&k, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, repository.ErrNotFound
+		}
+		return nil, err
+	}
+	return &k, nil
+}
+
+// FindByHash は指定したハッシュのキーを取得する
+func (ap *apiKeyPersistence) FindByHash(hash string) (*user.ApiKey, error) {
+	var k user.ApiKey
+	if err := ap.db.First(&k, "key_hash = ?", hash).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, repository.ErrNotFound
+		}
+		return nil, err
+	}
+	return &k, nil
+}
+
+// FindByUserId は指定したユーザーのキーを作成順に取得する
+func (ap *apiKeyPersistence) FindByUserId(userId user.UserId) ([]*user.ApiKey, error) {
+	var keys []*user.ApiKey
+	if err := ap.db.Where("user_id = ?", userId).Order("id").Find(&keys).Error; err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// Insert はキーを登録する
+func (ap *apiKeyPersistence) Insert(k *user.ApiKey) error {
+	return ap.db.Create(k).Error
+}
+
+// Revoke はキーを失効させる
+func (ap *apiKeyPersistence) Revoke(k *user.ApiKey) error {
+	return ap.db.Model(&user.ApiKey{}).
+		Where("id = ? AND revoked_at IS NULL", k.Id).
+		Update("revoked_at", k.RevokedAt).Error
+}
+
+// TouchLastUsed は最終利用日時を更新する
+func (ap *apiKeyPersistence) TouchLastUsed(id int, at time.Time) error {
+	return ap.db.Model(&user.ApiKey{}).Where("id = ?", id).Update("last_used_at", at).Error
+}
@@ -0,0 +1,56 @@
+package auth
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/fuki01/onion-architecture/domain/user"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var ErrNoSigningKey = errors.New("HMAC secret is required to issue tokens")
+
+// JWTIssuer はHS256で署名したアクセストークンを発行する
+// 発行したトークンは同じConfigから作ったJWTVerifierで検証できる
+type JWTIssuer struct {
+	secret   []byte
+	issuer   string
+	audience string
+	ttl      time.Duration
+	now      func() time.Time
+}
+
+func NewJWTIssuer(cfg Config, ttl time.Duration) (*JWTIssuer, error) {
+	if cfg.HMACSecret == "" {
+		return nil, ErrNoSigningKey
+	}
+	return &JWTIssuer{
+		secret:   []byte(cfg.HMACSecret),
+		issuer:   cfg.Issuer,
+		audience: cfg.Audience,
+		ttl:      ttl,
+		now:      time.Now,
+	}, nil
+}
+
+// Issue はユーザーのアクセストークンと有効期限を返す
+func (i *JWTIssuer) Issue(userId user.UserId) (string, time.Time, error) {
+	now := i.now()
+	expiresAt := now.Add(i.ttl)
+	claims := jwt.RegisteredClaims{
+		Subject:   strconv.Itoa(int(userId)),
+		Issuer:    i.issuer,
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(expiresAt),
+	}
+	if i.audience != "" {
+		claims.Audience = jwt.ClaimStrings{i.audience}
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(i.secret)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return token, expiresAt, nil
+}
@@ -0,0 +1,122 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/fuki01/onion-architecture/domain/user"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	ErrInvalidToken = errors.New("invalid token")
+	ErrNoKeys       = errors.New("no verification keys configured")
+)
+
+// Config はトークンの検証に使う鍵と検証項目の設定
+// HMACSecretはHS256、RSAPublicKeyFileとJWKSFileはRS256の検証に使う
+type Config struct {
+	HMACSecret       string
+	RSAPublicKeyFile string
+	JWKSFile         string
+	Issuer           string
+	Audience         string
+}
+
+// JWTVerifier はJWTを検証し、subクレームからユーザーを特定する
+type JWTVerifier struct {
+	hmacSecret []byte
+	rsaKeys    map[string]*rsa.PublicKey
+	parser     *jwt.Parser
+}
+
+func NewJWTVerifier(cfg Config) (*JWTVerifier, error) {
+	v := &JWTVerifier{
+		rsaKeys: map[string]*rsa.PublicKey{},
+	}
+	if cfg.HMACSecret != "" {
+		v.hmacSecret = []byte(cfg.HMACSecret)
+	}
+	if cfg.RSAPublicKeyFile != "" {
+		pem, err := os.ReadFile(cfg.RSAPublicKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		key, err := jwt.ParseRSAPublicKeyFromPEM(pem)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", cfg.RSAPublicKeyFile, err)
+		}
+		// kidを持たないトークンの検証に使う
+		v.rsaKeys[""] = key
+	}
+	if cfg.JWKSFile != "" {
+		keys, err := LoadJWKS(cfg.JWKSFile)
+		if err != nil {
+			return nil, err
+		}
+		for kid, key := range keys {
+			v.rsaKeys[kid] = key
+		}
+	}
+	if v.hmacSecret == nil && len(v.rsaKeys) == 0 {
+		return nil, ErrNoKeys
+	}
+
+	// 鍵を設定した署名方式のみを受け付ける
+	var methods []string
+	if v.hmacSecret != nil {
+		methods = append(methods, jwt.SigningMethodHS256.Alg())
+	}
+	if len(v.rsaKeys) > 0 {
+		methods = append(methods, jwt.SigningMethodRS256.Alg())
+	}
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods(methods),
+		jwt.WithExpirationRequired(),
+	}
+	if cfg.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(cfg.Issuer))
+	}
+	if cfg.Audience != "" {
+		opts = append(opts, jwt.WithAudience(cfg.Audience))
+	}
+	v.parser = jwt.NewParser(opts...)
+	return v, nil
+}
+
+// Verify はトークンの署名と有効期限を検証し、認証されたユーザーを返す
+func (v *JWTVerifier) Verify(token string) (user.UserId, error) {
+	claims := &jwt.RegisteredClaims{}
+	if _, err := v.parser.ParseWithClaims(token, claims, v.key); err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	id, err := strconv.Atoi(claims.Subject)
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("%w: invalid subject", ErrInvalidToken)
+	}
+	return user.UserId(id), nil
+}
+
+// 署名方式に応じた検証鍵を返す
+// 署名方式ごとに鍵を分けることで、公開鍵をHMACの秘密鍵として使われるのを防ぐ
+func (v *JWTVerifier) key(token *jwt.Token) (interface{}, error) {
+	switch token.Method.(type) {
+	case *jwt.SigningMethodHMAC:
+		if v.hmacSecret == nil {
+			return nil, errors.New("HS256 is not accepted")
+		}
+		return v.hmacSecret, nil
+	case *jwt.SigningMethodRSA:
+		kid, _ := token.Header["kid"].(string)
+		key, ok := v.rsaKeys[kid]
+		if !ok {
+			return nil, fmt.Errorf("unknown key id %q", kid)
+		}
+		return key, nil
+	}
+	return nil, fmt.Errorf("unexpected signing method %s", token.Method.Alg())
+}
@@ -0,0 +1,204 @@
+package auth_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fuki01/onion-architecture/domain/user"
+	"github.com/fuki01/onion-architecture/infrastructure/auth"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func claims(sub string, exp time.Duration) jwt.RegisteredClaims {
+	return jwt.RegisteredClaims{
+		Subject:   sub,
+		Issuer:    "tasks",
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(exp)),
+	}
+}
+
+func signHS256(t *testing.T, secret string, c jwt.Claims) string {
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, c).SignedString([]byte(secret))
+	require.NoError(t, err)
+	return token
+}
+
+func signRS256(t *testing.T, key *rsa.PrivateKey, kid string, c jwt.Claims) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, c)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+	return signed
+}
+
+func writeFile(t *testing.T, name string, b []byte) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, b, 0o600))
+	return path
+}
+
+func writeJWKS(t *testing.T, kid string, key *rsa.PublicKey) string {
+	b, err := json.Marshal(map[string]interface{}{
+		"keys": []map[string]string{
+			{"kty": "EC", "kid": "ignored", "crv": "P-256"},
+			{
+				"kty": "RSA",
+				"kid": kid,
+				"use": "sig",
+				"alg": "RS256",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			},
+		},
+	})
+	require.NoError(t, err)
+	return writeFile(t, "jwks.json", b)
+}
+
+func TestJWTVerifier(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	require.NoError(t, err)
+	pemFile := writeFile(t, "public.pem", pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+	jwksFile := writeJWKS(t, "key-1", &rsaKey.PublicKey)
+
+	verifier, err := auth.NewJWTVerifier(auth.Config{
+		HMACSecret:       "secret",
+		RSAPublicKeyFile: pemFile,
+		JWKSFile:         jwksFile,
+		Issuer:           "tasks",
+	})
+	require.NoError(t, err)
+
+	t.Run("HS256", func(t *testing.T) {
+		id, err := verifier.Verify(signHS256(t, "secret", claims("42", time.Hour)))
+		require.NoError(t, err)
+		assert.Equal(t, user.UserId(42), id)
+	})
+
+	t.Run("RS256 with pem", func(t *testing.T) {
+		id, err := verifier.Verify(signRS256(t, rsaKey, "", claims("7", time.Hour)))
+		require.NoError(t, err)
+		assert.Equal(t, user.UserId(7), id)
+	})
+
+	t.Run("RS256 with jwks", func(t *testing.T) {
+		id, err := verifier.Verify(signRS256(t, rsaKey, "key-1", claims("7", time.Hour)))
+		require.NoError(t, err)
+		assert.Equal(t, user.UserId(7), id)
+	})
+
+	testCases := []struct {
+		name  string
+		token func(t *testing.T) string
+	}{
+		{"wrong secret", func(t *testing.T) string { return signHS256(t, "other", claims("42", time.Hour)) }},
+		{"expired", func(t *testing.T) string { return signHS256(t, "secret", claims("42", -time.Minute)) }},
+		{"no expiry", func(t *testing.T) string {
+			return signHS256(t, "secret", jwt.RegisteredClaims{Subject: "42", Issuer: "tasks"})
+		}},
+		{"wrong issuer", func(t *testing.T) string {
+			c := claims("42", time.Hour)
+			c.Issuer = "other"
+			return signHS256(t, "secret", c)
+		}},
+		{"non numeric subject", func(t *testing.T) string { return signHS256(t, "secret", claims("taro", time.Hour)) }},
+		{"unknown kid", func(t *testing.T) string { return signRS256(t, rsaKey, "key-2", claims("7", time.Hour)) }},
+		{"public key as hmac secret", func(t *testing.T) string {
+			pemBytes, _ := os.ReadFile(pemFile)
+			return signHS256(t, string(pemBytes), claims("7", time.Hour))
+		}},
+		{"none algorithm", func(t *testing.T) string {
+			token, err := jwt.NewWithClaims(jwt.SigningMethodNone, claims("42", time.Hour)).SignedString(jwt.UnsafeAllowNoneSignatureType)
+			require.NoError(t, err)
+			return token
+		}},
+		{"malformed", func(t *testing.T) string { return "not-a-token" }},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := verifier.Verify(tc.token(t))
+			assert.ErrorIs(t, err, auth.ErrInvalidToken)
+		})
+	}
+}
+
+func TestNewJWTVerifier(t *testing.T) {
+	t.Run("no keys", func(t *testing.T) {
+		_, err := auth.NewJWTVerifier(auth.Config{})
+		assert.ErrorIs(t, err, auth.ErrNoKeys)
+	})
+
+	t.Run("hmac only rejects RS256", func(t *testing.T) {
+		rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+		require.NoError(t, err)
+		verifier, err := auth.NewJWTVerifier(auth.Config{HMACSecret: "secret"})
+		require.NoError(t, err)
+
+		_, err = verifier.Verify(signRS256(t, rsaKey, "", claims("7", time.Hour)))
+		assert.ErrorIs(t, err, auth.ErrInvalidToken)
+	})
+
+	t.Run("rsa only rejects HS256", func(t *testing.T) {
+		rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+		require.NoError(t, err)
+		verifier, err := auth.NewJWTVerifier(auth.Config{JWKSFile: writeJWKS(t, "key-1", &rsaKey.PublicKey)})
+		require.NoError(t, err)
+
+		_, err = verifier.Verify(signHS256(t, "", claims("7", time.Hour)))
+		assert.ErrorIs(t, err, auth.ErrInvalidToken)
+		id, err := verifier.Verify(signRS256(t, rsaKey, "key-1", claims("7", time.Hour)))
+		require.NoError(t, err)
+		assert.Equal(t, user.UserId(7), id)
+	})
+
+	t.Run("missing jwks file", func(t *testing.T) {
+		_, err := auth.NewJWTVerifier(auth.Config{JWKSFile: filepath.Join(t.TempDir(), "missing.json")})
+		assert.Error(t, err)
+	})
+}
+
+func TestJWTIssuer(t *testing.T) {
+	cfg := auth.Config{HMACSecret: "secret", Issuer: "tasks", Audience: "api"}
+
+	issuer, err := auth.NewJWTIssuer(cfg, time.Minute)
+	require.NoError(t, err)
+	verifier, err := auth.NewJWTVerifier(cfg)
+	require.NoError(t, err)
+
+	token, expiresAt, err := issuer.Issue(user.UserId(42))
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), expiresAt, 5*time.Second)
+
+	id, err := verifier.Verify(token)
+	require.NoError(t, err)
+	assert.Equal(t, user.UserId(42), id)
+
+	_, err = auth.NewJWTIssuer(auth.Config{JWKSFile: "jwks.json"}, time.Minute)
+	assert.ErrorIs(t, err, auth.ErrNoSigningKey)
+}
+
+func TestBcryptHasher(t *testing.T) {
+	hasher := auth.NewBcryptHasher(4)
+
+	hash, err := hasher.Hash("correct horse")
+	require.NoError(t, err)
+	assert.NotEqual(t, "correct horse", hash)
+	assert.NoError(t, hasher.Compare(hash, "correct horse"))
+	assert.Error(t, hasher.Compare(hash, "wrong"))
+}
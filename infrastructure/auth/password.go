@@ -0,0 +1,28 @@
+package auth
+
+import "golang.org/x/crypto/bcrypt"
+
+// BcryptHasher はbcryptでパスワードをハッシュ化する
+type BcryptHasher struct {
+	cost int
+}
+
+// NewBcryptHasher はcostが0の場合はbcryptの既定値を使う
+func NewBcryptHasher(cost int) *BcryptHasher {
+	if cost == 0 {
+		cost = bcrypt.DefaultCost
+	}
+	return &BcryptHasher{cost: cost}
+}
+
+func (h *BcryptHasher) Hash(password string) (string, error) {
+	b, err := bcrypt.GenerateFromPassword([]byte(password), h.cost)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+func (h *BcryptHasher) Compare(hash, password string) error {
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+}
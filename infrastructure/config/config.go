@@ -0,0 +1,361 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Profile は実行環境ごとの設定の組み合わせ
+type Profile string
+
+const (
+	ProfileLocal      Profile = "local"
+	ProfileTest       Profile = "test"
+	ProfileStaging    Profile = "staging"
+	ProfileProduction Profile = "production"
+)
+
+// IsValid は定義された実行環境かを返す
+func (p Profile) IsValid() bool {
+	switch p {
+	case ProfileLocal, ProfileTest, ProfileStaging, ProfileProduction:
+		return true
+	}
+	return false
+}
+
+// Config はアプリケーション全体の設定
+// 各項目のyamlタグは設定ファイルのキーとフラグ名、envタグは環境変数名に使う
+type Config struct {
+	Env       Profile         `yaml:"env"`
+	Server    ServerConfig    `yaml:"server"`
+	Database  DatabaseConfig  `yaml:"database"`
+	Auth      AuthConfig      `yaml:"auth"`
+	Delay     DelayConfig     `yaml:"delay"`
+	Scheduler SchedulerConfig `yaml:"scheduler"`
+	SMTP      SMTPConfig      `yaml:"smtp"`
+	Tracing   TracingConfig   `yaml:"tracing"`
+	Log       LogConfig       `yaml:"log"`
+	RateLimit RateLimitConfig `yaml:"rate_limit"`
+	// Idempotency はIdempotency-Keyヘッダーを付けたリクエストの応答を保存する期間
+	Idempotency IdempotencyConfig `yaml:"idempotency"`
+}
+
+type ServerConfig struct {
+	Addr string `yaml:"addr" env:"SERVER_ADDR" required:"true"`
+	// Mode はginの動作モード。debug、release、testのいずれか
+	Mode string `yaml:"mode" env:"GIN_MODE"`
+
+	ReadTimeout       time.Duration `yaml:"read_timeout" env:"SERVER_READ_TIMEOUT"`
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout" env:"SERVER_READ_HEADER_TIMEOUT"`
+	WriteTimeout      time.Duration `yaml:"write_timeout" env:"SERVER_WRITE_TIMEOUT"`
+	IdleTimeout       time.Duration `yaml:"idle_timeout" env:"SERVER_IDLE_TIMEOUT"`
+	MaxHeaderBytes    int           `yaml:"max_header_bytes" env:"SERVER_MAX_HEADER_BYTES"`
+	// DrainDelay は停止のシグナルを受けてからreadyzを失敗させたまま新しい接続を受け付け続ける時間
+	// ロードバランサーが振り分け先から外すまでの間にリクエストを取りこぼさないようにする
+	DrainDelay time.Duration `yaml:"drain_delay" env:"SERVER_DRAIN_DELAY"`
+	// ShutdownTimeout は停止時に処理中のリクエストとバックグラウンドの処理を待つ時間
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env:"SERVER_SHUTDOWN_TIMEOUT"`
+
+	// TrustedProxies はX-Forwarded-Forを信頼するプロキシのIPアドレスかCIDR。空の場合は接続元をクライアントとする
+	TrustedProxies []string `yaml:"trusted_proxies" env:"SERVER_TRUSTED_PROXIES"`
+
+	// MetricsAddr はメトリクスを公開する内部向けのアドレス。APIとは別に待ち受ける
+	// 空の場合はメトリクスを公開しない
+	MetricsAddr string `yaml:"metrics_addr" env:"SERVER_METRICS_ADDR"`
+
+	// TLSCertFileとTLSKeyFileを両方指定した場合はHTTPSで待ち受ける
+	TLSCertFile string `yaml:"tls_cert_file" env:"SERVER_TLS_CERT_FILE"`
+	TLSKeyFile  string `yaml:"tls_key_file" env:"SERVER_TLS_KEY_FILE"`
+}
+
+type DatabaseConfig struct {
+	User string `yaml:"user" env:"DB_USER" required:"true"`
+	Pass string `yaml:"pass" env:"DB_PASS" required:"true" secret:"true"`
+	Host string `yaml:"host" env:"DB_HOST" required:"true"`
+	Name string `yaml:"name" env:"DB_NAME" required:"true"`
+
+	// ReplicaHosts はリードレプリカのホスト。ユーザー名、パスワード、データベース名はプライマリと同じものを使う
+	ReplicaHosts []string `yaml:"replica_hosts" env:"DB_REPLICA_HOSTS"`
+	// ReplicaStickiness は書き込んだユーザーの読み込みをプライマリに固定する期間。レプリカの遅延より長くする
+	ReplicaStickiness time.Duration `yaml:"replica_stickiness" env:"DB_REPLICA_STICKINESS"`
+
+	// コネクションプールの設定。0の場合は制限しない
+	MaxOpenConns    int           `yaml:"max_open_conns" env:"DB_MAX_OPEN_CONNS"`
+	MaxIdleConns    int           `yaml:"max_idle_conns" env:"DB_MAX_IDLE_CONNS"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime" env:"DB_CONN_MAX_LIFETIME"`
+	ConnMaxIdleTime time.Duration `yaml:"conn_max_idle_time" env:"DB_CONN_MAX_IDLE_TIME"`
+
+	// 起動時の接続の再試行。ConnectTimeoutを過ぎると諦める
+	ConnectTimeout       time.Duration `yaml:"connect_timeout" env:"DB_CONNECT_TIMEOUT"`
+	RetryInitialInterval time.Duration `yaml:"retry_initial_interval" env:"DB_RETRY_INITIAL_INTERVAL"`
+	RetryMaxInterval     time.Duration `yaml:"retry_max_interval" env:"DB_RETRY_MAX_INTERVAL"`
+}
+
+// AuthConfig はトークンの検証と発行の設定
+// HMACSecret、RSAPublicKeyFile、JWKSFileの少なくとも1つが必要
+// HMACSecretを設定した場合のみ、HS256のトークンを受け付け、ローカルアカウントのログインでトークンを発行する
+type AuthConfig struct {
+	HMACSecret       string        `yaml:"hmac_secret" env:"JWT_HMAC_SECRET" secret:"true"`
+	RSAPublicKeyFile string        `yaml:"rsa_public_key_file" env:"JWT_RSA_PUBLIC_KEY_FILE"`
+	JWKSFile         string        `yaml:"jwks_file" env:"JWT_JWKS_FILE"`
+	Issuer           string        `yaml:"issuer" env:"JWT_ISSUER"`
+	Audience         string        `yaml:"audience" env:"JWT_AUDIENCE"`
+	AccessTokenTTL   time.Duration `yaml:"access_token_ttl" env:"ACCESS_TOKEN_TTL"`
+	RefreshTokenTTL  time.Duration `yaml:"refresh_token_ttl" env:"REFRESH_TOKEN_TTL"`
+}
+
+// DelayConfig は期限延長の制限。0の場合は制限しない
+type DelayConfig struct {
+	MaxExtensions int `yaml:"max_extensions" env:"DELAY_MAX_EXTENSIONS"`
+	MaxDays       int `yaml:"max_days" env:"DELAY_MAX_DAYS"`
+}
+
+type SchedulerConfig struct {
+	ReminderCron string `yaml:"reminder_cron" env:"REMINDER_CRON"`
+	OverdueCron  string `yaml:"overdue_cron" env:"OVERDUE_CRON"`
+	// IdempotencyCleanupCron は保存期間を過ぎた冪等キーの記録を削除する
+	IdempotencyCleanupCron string `yaml:"idempotency_cleanup_cron" env:"IDEMPOTENCY_CLEANUP_CRON"`
+}
+
+// SMTPConfig はメール通知の送信先。Addrが空の場合はメールを送らない
+type SMTPConfig struct {
+	Addr string `yaml:"addr" env:"SMTP_ADDR"`
+	User string `yaml:"user" env:"SMTP_USER"`
+	Pass string `yaml:"pass" env:"SMTP_PASS" secret:"true"`
+	From string `yaml:"from" env:"SMTP_FROM"`
+}
+
+// トレースの送り先
+const (
+	TraceExporterNone     = "none"
+	TraceExporterStdout   = "stdout"
+	TraceExporterFile     = "file"
+	TraceExporterOTLPHTTP = "otlp-http"
+	TraceExporterOTLPGRPC = "otlp-grpc"
+)
+
+// TracingConfig はOpenTelemetryのトレースの設定
+type TracingConfig struct {
+	// Exporter はnone、stdout、file、otlp-http、otlp-grpcのいずれか。noneの場合は記録しない
+	Exporter    string `yaml:"exporter" env:"TRACING_EXPORTER"`
+	ServiceName string `yaml:"service_name" env:"TRACING_SERVICE_NAME"`
+	// File はexporterがfileの場合の出力先
+	File string `yaml:"file" env:"TRACING_FILE"`
+	// Endpoint はOTLPの送信先のhost:port。空の場合はエクスポーターの既定値を使う
+	Endpoint string `yaml:"endpoint" env:"TRACING_OTLP_ENDPOINT"`
+	// Insecure はOTLPの送信にTLSを使わない
+	Insecure bool `yaml:"insecure" env:"TRACING_OTLP_INSECURE"`
+	// SampleRatio は記録するトレースの割合。上流で記録すると決めたトレースは常に記録する
+	SampleRatio float64 `yaml:"sample_ratio" env:"TRACING_SAMPLE_RATIO"`
+}
+
+// LogConfig はログの出力の設定
+type LogConfig struct {
+	// Level はdebug、info、warn、errorのいずれか
+	Level string `yaml:"level" env:"LOG_LEVEL"`
+	// Format はjsonかtext
+	Format string `yaml:"format" env:"LOG_FORMAT"`
+	// SlowQueryThreshold を超えたクエリは警告として出力する。0の場合は出力しない
+	SlowQueryThreshold time.Duration `yaml:"slow_query_threshold" env:"LOG_SLOW_QUERY_THRESHOLD"`
+}
+
+// RateLimitConfig はルートのグループごとのレート制限。PerMinuteが0の場合は制限しない
+type RateLimitConfig struct {
+	// Client はAPI全体に対するクライアントのIPアドレスごとの制限
+	ClientPerMinute int `yaml:"client_per_minute" env:"RATE_LIMIT_CLIENT_PER_MINUTE"`
+	ClientBurst     int `yaml:"client_burst" env:"RATE_LIMIT_CLIENT_BURST"`
+	// Auth は登録とログインなど認証なしで受け付けるAPIに対するIPアドレスごとの制限
+	AuthPerMinute int `yaml:"auth_per_minute" env:"RATE_LIMIT_AUTH_PER_MINUTE"`
+	AuthBurst     int `yaml:"auth_burst" env:"RATE_LIMIT_AUTH_BURST"`
+	// API は認証が必要なAPIに対するユーザーごとの制限
+	APIPerMinute int `yaml:"api_per_minute" env:"RATE_LIMIT_API_PER_MINUTE"`
+	APIBurst     int `yaml:"api_burst" env:"RATE_LIMIT_API_BURST"`
+}
+
+// IdempotencyConfig は冪等キーの設定
+type IdempotencyConfig struct {
+	// TTL は応答を保存し、同じキーのリクエストに保存した応答を返す期間
+	TTL time.Duration `yaml:"ttl" env:"IDEMPOTENCY_TTL"`
+}
+
+// defaults は実行環境ごとの既定値を返す
+func defaults(profile Profile) *Config {
+	cfg := &Config{
+		Env: profile,
+		Server: ServerConfig{
+			Addr:              ":8080",
+			Mode:              "debug",
+			ReadTimeout:       15 * time.Second,
+			ReadHeaderTimeout: 5 * time.Second,
+			WriteTimeout:      30 * time.Second,
+			IdleTimeout:       60 * time.Second,
+			MaxHeaderBytes:    1 << 20,
+			ShutdownTimeout:   30 * time.Second,
+		},
+		Database: DatabaseConfig{
+			ReplicaStickiness:    5 * time.Second,
+			MaxOpenConns:         25,
+			MaxIdleConns:         10,
+			ConnMaxLifetime:      30 * time.Minute,
+			ConnMaxIdleTime:      5 * time.Minute,
+			ConnectTimeout:       time.Minute,
+			RetryInitialInterval: 500 * time.Millisecond,
+			RetryMaxInterval:     10 * time.Second,
+		},
+		Auth: AuthConfig{
+			AccessTokenTTL:  15 * time.Minute,
+			RefreshTokenTTL: 30 * 24 * time.Hour,
+		},
+		Scheduler: SchedulerConfig{
+			ReminderCron: "*/15 * * * *",
+			OverdueCron:  "0 * * * *",
+			// 毎時0分の期限切れの処理と重ならないようにずらす
+			IdempotencyCleanupCron: "30 * * * *",
+		},
+		SMTP: SMTPConfig{From: "noreply@localhost"},
+		Tracing: TracingConfig{
+			Exporter:    TraceExporterNone,
+			ServiceName: "task-api",
+			SampleRatio: 1,
+		},
+		RateLimit: RateLimitConfig{
+			ClientPerMinute: 1200,
+			ClientBurst:     200,
+			AuthPerMinute:   10,
+			AuthBurst:       5,
+			APIPerMinute:    300,
+			APIBurst:        60,
+		},
+		Idempotency: IdempotencyConfig{TTL: 24 * time.Hour},
+		Log: LogConfig{
+			Level:              "debug",
+			Format:             "text",
+			SlowQueryThreshold: 200 * time.Millisecond,
+		},
+	}
+	switch profile {
+	case ProfileTest:
+		cfg.Server.Mode = "test"
+		cfg.Log.Level = "warn"
+	case ProfileStaging, ProfileProduction:
+		cfg.Server.Mode = "release"
+		cfg.Server.DrainDelay = 5 * time.Second
+		cfg.Log.Level = "info"
+		cfg.Log.Format = "json"
+	}
+	return cfg
+}
+
+const redacted = "********"
+
+// Redacted は秘密情報を伏せた設定を返す
+func (c Config) Redacted() Config {
+	for _, f := range fieldsOf(&c) {
+		if f.secret && !f.value.IsZero() {
+			f.value.SetString(redacted)
+		}
+	}
+	return c
+}
+
+// String は秘密情報を伏せた設定をYAML形式で返す
+func (c Config) String() string {
+	out, err := yaml.Marshal(c.Redacted())
+	if err != nil {
+		return fmt.Sprintf("config: %v", err)
+	}
+	return string(out)
+}
+
+// field は設定の1項目
+type field struct {
+	key      string
+	env      string
+	required bool
+	secret   bool
+	value    reflect.Value
+}
+
+// fieldsOf は環境変数名を持つ全ての項目を返す
+func fieldsOf(cfg *Config) []field {
+	return collect(reflect.ValueOf(cfg).Elem(), "")
+}
+
+func collect(v reflect.Value, prefix string) []field {
+	var fields []field
+	for i := 0; i < v.NumField(); i++ {
+		sf := v.Type().Field(i)
+		key := strings.Split(sf.Tag.Get("yaml"), ",")[0]
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		if sf.Type.Kind() == reflect.Struct {
+			fields = append(fields, collect(v.Field(i), key)...)
+			continue
+		}
+		env := sf.Tag.Get("env")
+		if env == "" {
+			continue
+		}
+		fields = append(fields, field{
+			key:      key,
+			env:      env,
+			required: sf.Tag.Get("required") == "true",
+			secret:   sf.Tag.Get("secret") == "true",
+			value:    v.Field(i),
+		})
+	}
+	return fields
+}
+
+// set は文字列の値を項目の型に変換して設定する
+func (f field) set(raw string) error {
+	switch f.value.Interface().(type) {
+	case time.Duration:
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q", raw)
+		}
+		f.value.SetInt(int64(d))
+	case []string:
+		var values []string
+		for _, v := range strings.Split(raw, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				values = append(values, v)
+			}
+		}
+		f.value.Set(reflect.ValueOf(values))
+	case bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", raw)
+		}
+		f.value.SetBool(b)
+	case float64:
+		n, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", raw)
+		}
+		f.value.SetFloat(n)
+	case int:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", raw)
+		}
+		f.value.SetInt(int64(n))
+	default:
+		f.value.SetString(raw)
+	}
+	return nil
+}
+
+// name はエラーメッセージに使う項目名
+func (f field) name() string {
+	return fmt.Sprintf("%s (%s)", f.key, f.env)
+}
@@ -0,0 +1,244 @@
+package config_test
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fuki01/onion-architecture/infrastructure/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// 必須の環境変数を設定する
+func setRequiredEnv(t *testing.T) {
+	t.Setenv("ENV", "")
+	t.Setenv("DB_USER", "root")
+	t.Setenv("DB_PASS", "password")
+	t.Setenv("DB_HOST", "db")
+	t.Setenv("DB_NAME", "taskdb")
+	t.Setenv("JWT_HMAC_SECRET", "secret")
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func load(args ...string) (*config.Config, error) {
+	return config.Load(flag.NewFlagSet("test", flag.ContinueOnError), args)
+}
+
+func TestLoad(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		setRequiredEnv(t)
+
+		cfg, err := load()
+		require.NoError(t, err)
+		assert.Equal(t, config.ProfileLocal, cfg.Env)
+		assert.Equal(t, ":8080", cfg.Server.Addr)
+		assert.Equal(t, "debug", cfg.Server.Mode)
+		assert.Equal(t, 15*time.Minute, cfg.Auth.AccessTokenTTL)
+		assert.Equal(t, "db", cfg.Database.Host)
+		assert.Equal(t, 24*time.Hour, cfg.Idempotency.TTL)
+		assert.Equal(t, "30 * * * *", cfg.Scheduler.IdempotencyCleanupCron)
+	})
+
+	t.Run("flags override env and env overrides file", func(t *testing.T) {
+		setRequiredEnv(t)
+		t.Setenv("DB_HOST", "env-db")
+		path := writeFile(t, "app.yaml", `
+server:
+  addr: ":9000"
+database:
+  host: file-db
+  name: filedb
+auth:
+  access_token_ttl: 5m
+delay:
+  max_extensions: 3
+`)
+
+		cfg, err := load("-config", path, "-database.host", "flag-db")
+		require.NoError(t, err)
+		assert.Equal(t, ":9000", cfg.Server.Addr)
+		assert.Equal(t, "flag-db", cfg.Database.Host)
+		assert.Equal(t, "taskdb", cfg.Database.Name)
+		assert.Equal(t, 5*time.Minute, cfg.Auth.AccessTokenTTL)
+		assert.Equal(t, 3, cfg.Delay.MaxExtensions)
+	})
+
+	t.Run("toml file", func(t *testing.T) {
+		setRequiredEnv(t)
+		path := writeFile(t, "app.toml", "[scheduler]\nreminder_cron = \"0 9 * * *\"\n")
+
+		cfg, err := load("-config", path)
+		require.NoError(t, err)
+		assert.Equal(t, "0 9 * * *", cfg.Scheduler.ReminderCron)
+	})
+
+	t.Run("replica hosts", func(t *testing.T) {
+		setRequiredEnv(t)
+		path := writeFile(t, "app.yaml", "database:\n  replica_hosts: [replica1, replica2]\n")
+
+		cfg, err := load("-config", path)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"replica1", "replica2"}, cfg.Database.ReplicaHosts)
+
+		t.Setenv("DB_REPLICA_HOSTS", "replica3, replica4")
+		cfg, err = load("-config", path)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"replica3", "replica4"}, cfg.Database.ReplicaHosts)
+	})
+
+	t.Run("profile from env file", func(t *testing.T) {
+		setRequiredEnv(t)
+		path := writeFile(t, "prod.env", "ENV=production\nSMTP_FROM=tasks@example.com\n")
+		t.Cleanup(func() { os.Unsetenv("ENV"); os.Unsetenv("SMTP_FROM") })
+		os.Unsetenv("ENV")
+		t.Setenv("JWT_HMAC_SECRET", "0123456789abcdef0123456789abcdef")
+
+		cfg, err := load("-env-file", path)
+		require.NoError(t, err)
+		assert.Equal(t, config.ProfileProduction, cfg.Env)
+		assert.Equal(t, "release", cfg.Server.Mode)
+		assert.Equal(t, 5*time.Second, cfg.Server.DrainDelay)
+		assert.Equal(t, "json", cfg.Log.Format)
+		assert.Equal(t, "tasks@example.com", cfg.SMTP.From)
+	})
+
+	t.Run("every problem is reported", func(t *testing.T) {
+		for _, key := range []string{"ENV", "DB_USER", "DB_PASS", "DB_HOST", "DB_NAME", "JWT_HMAC_SECRET"} {
+			t.Setenv(key, "")
+		}
+		t.Setenv("ACCESS_TOKEN_TTL", "soon")
+		path := writeFile(t, "app.yaml", "database:\n  hostname: db\n")
+
+		_, err := load("-config", path)
+		var validationErr *config.ValidationError
+		require.ErrorAs(t, err, &validationErr)
+		assert.ElementsMatch(t, []string{
+			"database.hostname: unknown key in config file",
+			`auth.access_token_ttl (ACCESS_TOKEN_TTL) from env: invalid duration "soon"`,
+			"database.user (DB_USER) is required",
+			"database.pass (DB_PASS) is required",
+			"database.host (DB_HOST) is required",
+			"database.name (DB_NAME) is required",
+			"one of auth.hmac_secret (JWT_HMAC_SECRET), auth.rsa_public_key_file (JWT_RSA_PUBLIC_KEY_FILE) or auth.jwks_file (JWT_JWKS_FILE) is required",
+		}, validationErr.Problems)
+	})
+
+	t.Run("short secret in production", func(t *testing.T) {
+		setRequiredEnv(t)
+
+		_, err := load("-env", "production")
+		var validationErr *config.ValidationError
+		require.ErrorAs(t, err, &validationErr)
+		assert.Equal(t, []string{"auth.hmac_secret (JWT_HMAC_SECRET) must be at least 32 bytes in production"}, validationErr.Problems)
+	})
+
+	t.Run("rs256 only", func(t *testing.T) {
+		setRequiredEnv(t)
+		t.Setenv("JWT_HMAC_SECRET", "")
+		t.Setenv("JWT_JWKS_FILE", "jwks.json")
+
+		cfg, err := load("-env", "production")
+		require.NoError(t, err)
+		assert.Empty(t, cfg.Auth.HMACSecret)
+	})
+
+	t.Run("idle connections exceed open connections", func(t *testing.T) {
+		setRequiredEnv(t)
+
+		_, err := load("-database.max_open_conns", "5", "-database.max_idle_conns", "10")
+		var validationErr *config.ValidationError
+		require.ErrorAs(t, err, &validationErr)
+		assert.Equal(t, []string{"database.max_idle_conns (DB_MAX_IDLE_CONNS) must not exceed database.max_open_conns (DB_MAX_OPEN_CONNS)"}, validationErr.Problems)
+	})
+
+	t.Run("tls certificate without key", func(t *testing.T) {
+		setRequiredEnv(t)
+		t.Setenv("SERVER_TLS_CERT_FILE", "server.crt")
+
+		_, err := load()
+		var validationErr *config.ValidationError
+		require.ErrorAs(t, err, &validationErr)
+		assert.Equal(t, []string{"server.tls_cert_file (SERVER_TLS_CERT_FILE) and server.tls_key_file (SERVER_TLS_KEY_FILE) must be set together"}, validationErr.Problems)
+	})
+
+	t.Run("metrics on the api address", func(t *testing.T) {
+		setRequiredEnv(t)
+		t.Setenv("SERVER_METRICS_ADDR", ":8080")
+
+		_, err := load()
+		var validationErr *config.ValidationError
+		require.ErrorAs(t, err, &validationErr)
+		assert.Equal(t, []string{"server.metrics_addr (SERVER_METRICS_ADDR) must differ from server.addr (SERVER_ADDR)"}, validationErr.Problems)
+	})
+
+	t.Run("tracing", func(t *testing.T) {
+		setRequiredEnv(t)
+		t.Setenv("TRACING_EXPORTER", "file")
+		t.Setenv("TRACING_SAMPLE_RATIO", "1.5")
+
+		_, err := load()
+		var validationErr *config.ValidationError
+		require.ErrorAs(t, err, &validationErr)
+		assert.Equal(t, []string{
+			"tracing.file (TRACING_FILE) is required when tracing.exporter is file",
+			"tracing.sample_ratio (TRACING_SAMPLE_RATIO) must be between 0 and 1",
+		}, validationErr.Problems)
+
+		cfg, err := load("-tracing.file", "traces.json", "-tracing.sample_ratio", "0.25", "-tracing.insecure", "true")
+		require.NoError(t, err)
+		assert.Equal(t, 0.25, cfg.Tracing.SampleRatio)
+		assert.True(t, cfg.Tracing.Insecure)
+	})
+
+	t.Run("unknown profile", func(t *testing.T) {
+		setRequiredEnv(t)
+		t.Setenv("ENV", "qa")
+
+		_, err := load()
+		assert.ErrorContains(t, err, `unknown profile "qa"`)
+	})
+}
+
+func TestBackoff(t *testing.T) {
+	t.Run("grows exponentially up to max", func(t *testing.T) {
+		backoff := config.Backoff{Initial: time.Second, Max: 5 * time.Second, Rand: func() float64 { return 0.999999 }}
+
+		assert.InDelta(t, time.Second, backoff.Delay(1), float64(time.Millisecond))
+		assert.InDelta(t, 2*time.Second, backoff.Delay(2), float64(time.Millisecond))
+		assert.InDelta(t, 4*time.Second, backoff.Delay(3), float64(time.Millisecond))
+		assert.InDelta(t, 5*time.Second, backoff.Delay(4), float64(time.Millisecond))
+		assert.InDelta(t, 5*time.Second, backoff.Delay(100), float64(time.Millisecond))
+	})
+
+	t.Run("jitter keeps at least half", func(t *testing.T) {
+		backoff := config.Backoff{Initial: time.Second, Max: 5 * time.Second, Rand: func() float64 { return 0 }}
+
+		assert.Equal(t, 500*time.Millisecond, backoff.Delay(1))
+		assert.Equal(t, 2*time.Second, backoff.Delay(3))
+	})
+}
+
+func TestConfigString(t *testing.T) {
+	setRequiredEnv(t)
+	t.Setenv("DB_PASS", "db-password")
+	t.Setenv("SMTP_PASS", "smtp-password")
+
+	cfg, err := load()
+	require.NoError(t, err)
+
+	out := cfg.String()
+	assert.NotContains(t, out, "db-password")
+	assert.NotContains(t, out, "smtp-password")
+	assert.Contains(t, out, "pass: '********'")
+	assert.Contains(t, out, "host: db")
+	// 元の設定は変更しない
+	assert.Equal(t, "db-password", cfg.Database.Pass)
+}
@@ -0,0 +1,269 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/joho/godotenv"
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// ValidationError は設定の読み込みで見つかった全ての問題
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid config:\n  " + strings.Join(e.Problems, "\n  ")
+}
+
+// Load は設定を読み込む
+// 既定値、設定ファイル、環境変数、フラグの順に読み込み、後から読み込んだ値を優先する
+//
+// 実行環境は-envフラグか環境変数ENVで指定し、未指定の場合はlocalになる
+// 環境変数は-env-fileフラグかENV_FILEで指定したファイルと.envからも読み込むが、既に設定されている値は上書きしない
+// 設定ファイルは-configフラグかCONFIG_FILEで指定し、未指定の場合はconfig/<実行環境>.yamlがあれば読み込む
+// 各項目のフラグ名は設定ファイルのキーと同じで、-database.hostのように指定する
+func Load(flags *flag.FlagSet, args []string) (*Config, error) {
+	configFile := flags.String("config", "", "設定ファイルのパス（.yaml、.yml、.toml）")
+	envFile := flags.String("env-file", "", "読み込む環境変数ファイルのパス。カンマ区切りで複数指定できる")
+	profile := flags.String("env", "", "実行環境（local、test、staging、production）")
+
+	overrides := map[string]*string{}
+	for _, f := range fieldsOf(defaults(ProfileLocal)) {
+		overrides[f.key] = flags.String(f.key, "", fmt.Sprintf("%sを上書きする", f.env))
+	}
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+
+	if err := loadEnvFiles(firstNonEmpty(*envFile, os.Getenv("ENV_FILE"))); err != nil {
+		return nil, err
+	}
+
+	p := Profile(firstNonEmpty(*profile, os.Getenv("ENV"), string(ProfileLocal)))
+	if !p.IsValid() {
+		return nil, &ValidationError{Problems: []string{fmt.Sprintf("env (ENV): unknown profile %q", p)}}
+	}
+	cfg := defaults(p)
+	fields := fieldsOf(cfg)
+	var problems []string
+
+	// 設定ファイル
+	values, err := readConfigFile(firstNonEmpty(*configFile, os.Getenv("CONFIG_FILE")), p)
+	if err != nil {
+		return nil, err
+	}
+	if v, ok := values["env"]; ok && Profile(v) != p {
+		problems = append(problems, fmt.Sprintf("env: config file is for profile %q but running as %q", v, p))
+	}
+	known := map[string]bool{"env": true}
+	for _, f := range fields {
+		known[f.key] = true
+	}
+	for _, key := range sortedKeys(values) {
+		if !known[key] {
+			problems = append(problems, fmt.Sprintf("%s: unknown key in config file", key))
+		}
+	}
+
+	// フラグは指定されたものだけを反映する
+	visited := map[string]bool{}
+	flags.Visit(func(f *flag.Flag) { visited[f.Name] = true })
+
+	for _, f := range fields {
+		if v, ok := values[f.key]; ok {
+			problems = appendProblem(problems, f, "config file", v)
+		}
+		if v := os.Getenv(f.env); v != "" {
+			problems = appendProblem(problems, f, "env", v)
+		}
+		if visited[f.key] {
+			problems = appendProblem(problems, f, "flag", *overrides[f.key])
+		}
+	}
+
+	problems = append(problems, cfg.validate(fields)...)
+	if len(problems) > 0 {
+		return nil, &ValidationError{Problems: problems}
+	}
+	return cfg, nil
+}
+
+// validate は必須項目と値の範囲を確認する
+func (c *Config) validate(fields []field) []string {
+	var problems []string
+	for _, f := range fields {
+		if f.required && f.value.IsZero() {
+			problems = append(problems, f.name()+" is required")
+		}
+	}
+	if c.Server.MetricsAddr != "" && c.Server.MetricsAddr == c.Server.Addr {
+		problems = append(problems, "server.metrics_addr (SERVER_METRICS_ADDR) must differ from server.addr (SERVER_ADDR)")
+	}
+	if (c.Server.TLSCertFile == "") != (c.Server.TLSKeyFile == "") {
+		problems = append(problems, "server.tls_cert_file (SERVER_TLS_CERT_FILE) and server.tls_key_file (SERVER_TLS_KEY_FILE) must be set together")
+	}
+	if c.Server.DrainDelay < 0 {
+		problems = append(problems, "server.drain_delay (SERVER_DRAIN_DELAY) must not be negative")
+	}
+	if c.Server.ShutdownTimeout <= 0 {
+		problems = append(problems, "server.shutdown_timeout (SERVER_SHUTDOWN_TIMEOUT) must be positive")
+	}
+	if c.Database.MaxOpenConns < 0 || c.Database.MaxIdleConns < 0 {
+		problems = append(problems, "database.max_open_conns (DB_MAX_OPEN_CONNS) and database.max_idle_conns (DB_MAX_IDLE_CONNS) must not be negative")
+	}
+	if c.Database.MaxOpenConns > 0 && c.Database.MaxIdleConns > c.Database.MaxOpenConns {
+		problems = append(problems, "database.max_idle_conns (DB_MAX_IDLE_CONNS) must not exceed database.max_open_conns (DB_MAX_OPEN_CONNS)")
+	}
+	if c.Database.ConnectTimeout <= 0 {
+		problems = append(problems, "database.connect_timeout (DB_CONNECT_TIMEOUT) must be positive")
+	}
+	if c.Database.RetryInitialInterval <= 0 || c.Database.RetryMaxInterval < c.Database.RetryInitialInterval {
+		problems = append(problems, "database.retry_max_interval (DB_RETRY_MAX_INTERVAL) must not be shorter than database.retry_initial_interval (DB_RETRY_INITIAL_INTERVAL), which must be positive")
+	}
+	if c.Delay.MaxExtensions < 0 {
+		problems = append(problems, "delay.max_extensions (DELAY_MAX_EXTENSIONS) must not be negative")
+	}
+	if c.Delay.MaxDays < 0 {
+		problems = append(problems, "delay.max_days (DELAY_MAX_DAYS) must not be negative")
+	}
+	switch c.Tracing.Exporter {
+	case TraceExporterNone, TraceExporterStdout, TraceExporterOTLPHTTP, TraceExporterOTLPGRPC:
+	case TraceExporterFile:
+		if c.Tracing.File == "" {
+			problems = append(problems, "tracing.file (TRACING_FILE) is required when tracing.exporter is file")
+		}
+	default:
+		problems = append(problems, fmt.Sprintf("tracing.exporter (TRACING_EXPORTER) must be one of none, stdout, file, otlp-http, otlp-grpc, got %q", c.Tracing.Exporter))
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		problems = append(problems, "tracing.sample_ratio (TRACING_SAMPLE_RATIO) must be between 0 and 1")
+	}
+	if c.RateLimit.ClientPerMinute < 0 || c.RateLimit.ClientBurst < 0 ||
+		c.RateLimit.AuthPerMinute < 0 || c.RateLimit.AuthBurst < 0 ||
+		c.RateLimit.APIPerMinute < 0 || c.RateLimit.APIBurst < 0 {
+		problems = append(problems, "rate_limit values must not be negative")
+	}
+	if c.Idempotency.TTL <= 0 {
+		problems = append(problems, "idempotency.ttl (IDEMPOTENCY_TTL) must be positive")
+	}
+	switch c.Log.Level {
+	case "debug", "info", "warn", "error":
+	default:
+		problems = append(problems, fmt.Sprintf("log.level (LOG_LEVEL) must be one of debug, info, warn, error, got %q", c.Log.Level))
+	}
+	if c.Log.Format != "json" && c.Log.Format != "text" {
+		problems = append(problems, fmt.Sprintf("log.format (LOG_FORMAT) must be json or text, got %q", c.Log.Format))
+	}
+	if c.Auth.HMACSecret == "" && c.Auth.RSAPublicKeyFile == "" && c.Auth.JWKSFile == "" {
+		problems = append(problems, "one of auth.hmac_secret (JWT_HMAC_SECRET), auth.rsa_public_key_file (JWT_RSA_PUBLIC_KEY_FILE) or auth.jwks_file (JWT_JWKS_FILE) is required")
+	}
+	if c.Env == ProfileProduction && c.Auth.HMACSecret != "" && len(c.Auth.HMACSecret) < 32 {
+		problems = append(problems, "auth.hmac_secret (JWT_HMAC_SECRET) must be at least 32 bytes in production")
+	}
+	return problems
+}
+
+func appendProblem(problems []string, f field, source, raw string) []string {
+	if err := f.set(raw); err != nil {
+		return append(problems, fmt.Sprintf("%s from %s: %v", f.name(), source, err))
+	}
+	return problems
+}
+
+// loadEnvFiles は指定したファイルと.envを環境変数に読み込む
+// 指定したファイルは存在しない場合にエラーを返し、.envは存在しない場合は無視する
+func loadEnvFiles(paths string) error {
+	for _, path := range strings.Split(paths, ",") {
+		if path = strings.TrimSpace(path); path == "" {
+			continue
+		}
+		if err := godotenv.Load(path); err != nil {
+			return fmt.Errorf("load env file %s: %w", path, err)
+		}
+	}
+	if err := godotenv.Load(".env"); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("load env file .env: %w", err)
+	}
+	return nil
+}
+
+// readConfigFile は設定ファイルを読み込み、ドット区切りのキーと値の組を返す
+// pathが空の場合は実行環境ごとの設定ファイルを探し、存在しなければ空を返す
+func readConfigFile(path string, profile Profile) (map[string]string, error) {
+	explicit := path != ""
+	if !explicit {
+		path = filepath.Join("config", string(profile)+".yaml")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !explicit && errors.Is(err, fs.ErrNotExist) {
+			return map[string]string{}, nil
+		}
+		return nil, fmt.Errorf("read config file: %w", err)
+	}
+
+	var tree map[string]any
+	switch ext := filepath.Ext(path); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &tree)
+	case ".toml":
+		err = toml.Unmarshal(data, &tree)
+	default:
+		return nil, fmt.Errorf("read config file: unsupported format %q", ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read config file %s: %w", path, err)
+	}
+
+	values := map[string]string{}
+	flatten(values, "", tree)
+	return values, nil
+}
+
+func flatten(values map[string]string, prefix string, tree map[string]any) {
+	for k, v := range tree {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+		switch v := v.(type) {
+		case map[string]any:
+			flatten(values, key, v)
+		case []any:
+			items := make([]string, len(v))
+			for i, item := range v {
+				items[i] = fmt.Sprint(item)
+			}
+			values[key] = strings.Join(items, ",")
+		default:
+			values[key] = fmt.Sprint(v)
+		}
+	}
+}
+
+func sortedKeys(values map[string]string) []string {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
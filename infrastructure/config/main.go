@@ -1,8 +1,10 @@
 package config
 
 import (
+	"context"
 	"fmt"
-	"log"
+	"log/slog"
+	"math/rand"
 	"time"
 
 	"gorm.io/driver/mysql"
@@ -10,45 +12,93 @@ import (
 )
 
 type Database struct {
-	User   string
-	Pass   string
-	Host   string
-	DBName string
-}
-
-func NewDatabase(user, pass, host, dbname string) *Database {
-	return &Database{
-		User:   user,
-		Pass:   pass,
-		Host:   host,
-		DBName: dbname,
-	}
+	config DatabaseConfig
 }
 
-func (d *Database) Connect() (*gorm.DB, error) {
-	connection := fmt.Sprintf("%s:%s@tcp(%s)/%s?charset=utf8&parseTime=True&loc=Local", d.User, d.Pass, d.Host, d.DBName)
-	db, err := connectToDatabase(connection)
-	if err != nil {
-		return nil, err
-	}
+func NewDatabase(cfg DatabaseConfig) *Database {
+	return &Database{config: cfg}
+}
 
-	return db, nil
+// Connect はプライマリに接続し、コネクションプールを設定する
+// 接続に失敗した場合は間隔を伸ばしながら再試行し、ConnectTimeoutを過ぎるかctxが終了すると諦める
+func (d *Database) Connect(ctx context.Context) (*gorm.DB, error) {
+	return d.connect(ctx, d.config.Host)
 }
 
-func connectToDatabase(connection string) (*gorm.DB, error) {
-	var db *gorm.DB
-	var err error
-	maxAttempts := 5
-	interval := time.Second * 2
+// ConnectReplicas は全てのリードレプリカに接続する
+func (d *Database) ConnectReplicas(ctx context.Context) ([]*gorm.DB, error) {
+	replicas := make([]*gorm.DB, 0, len(d.config.ReplicaHosts))
+	for _, host := range d.config.ReplicaHosts {
+		db, err := d.connect(ctx, host)
+		if err != nil {
+			return nil, fmt.Errorf("replica %s: %w", host, err)
+		}
+		replicas = append(replicas, db)
+	}
+	return replicas, nil
+}
+
+func (d *Database) connect(ctx context.Context, host string) (*gorm.DB, error) {
+	connection := fmt.Sprintf("%s:%s@tcp(%s)/%s?charset=utf8&parseTime=True&loc=Local", d.config.User, d.config.Pass, host, d.config.Name)
+
+	ctx, cancel := context.WithTimeout(ctx, d.config.ConnectTimeout)
+	defer cancel()
 
-	for attempts := 1; attempts <= maxAttempts; attempts++ {
-		db, err = gorm.Open(mysql.Open(connection), &gorm.Config{})
+	backoff := Backoff{Initial: d.config.RetryInitialInterval, Max: d.config.RetryMaxInterval}
+	for attempt := 1; ; attempt++ {
+		db, err := gorm.Open(mysql.Open(connection), &gorm.Config{})
 		if err == nil {
+			if err := d.configurePool(db); err != nil {
+				return nil, err
+			}
 			return db, nil
 		}
-		log.Printf("Failed to connect to database (attempt %d): %v", attempts, err)
-		time.Sleep(interval)
+
+		wait := backoff.Delay(attempt)
+		slog.WarnContext(ctx, "failed to connect to database", slog.String("host", host), slog.Int("attempt", attempt), slog.Duration("retry_in", wait), slog.Any("error", err))
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("failed to connect to database after %d attempts: %w", attempt, err)
+		case <-time.After(wait):
+		}
 	}
+}
+
+func (d *Database) configurePool(db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	sqlDB.SetMaxOpenConns(d.config.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(d.config.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(d.config.ConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(d.config.ConnMaxIdleTime)
+	return nil
+}
 
-	return nil, fmt.Errorf("failed to connect to database after %d attempts", maxAttempts)
+// Backoff は再試行の間隔を指数的に伸ばす
+// 同時に起動した複数のプロセスが同じ間隔で再試行しないよう、間隔の半分を上限に揺らぎを加える
+type Backoff struct {
+	Initial time.Duration
+	Max     time.Duration
+	// Rand は[0,1)の乱数を返す。nilの場合はmath/randを使う
+	Rand func() float64
+}
+
+// Delay はattempt回目の失敗の後に待つ時間を返す
+func (b Backoff) Delay(attempt int) time.Duration {
+	delay := b.Initial
+	for i := 1; i < attempt && delay < b.Max; i++ {
+		delay *= 2
+	}
+	if delay > b.Max {
+		delay = b.Max
+	}
+
+	random := rand.Float64
+	if b.Rand != nil {
+		random = b.Rand
+	}
+	half := delay / 2
+	return half + time.Duration(random()*float64(delay-half))
 }
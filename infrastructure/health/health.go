@@ -0,0 +1,122 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrShuttingDown は停止処理が始まり、新しいリクエストを受け付けるべきでないことを表す
+var ErrShuttingDown = errors.New("shutting down")
+
+// Check は依存先の状態を確認し、利用できない場合はエラーを返す
+type Check func(ctx context.Context) error
+
+type namedCheck struct {
+	name  string
+	check Check
+}
+
+// Registry はリクエストを受け付けられるかの判定に使うチェックを集める
+type Registry struct {
+	timeout time.Duration
+
+	mu       sync.RWMutex
+	checks   []namedCheck
+	draining atomic.Bool
+}
+
+// NewRegistry はチェックごとにtimeoutを上限に実行するRegistryを返す
+func NewRegistry(timeout time.Duration) *Registry {
+	return &Registry{timeout: timeout}
+}
+
+// Register はチェックを追加する。同じ名前のチェックは置き換える
+func (r *Registry) Register(name string, check Check) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, c := range r.checks {
+		if c.name == name {
+			r.checks[i].check = check
+			return
+		}
+	}
+	r.checks = append(r.checks, namedCheck{name: name, check: check})
+}
+
+// Drain は停止処理の開始を記録し、以降のCheckを失敗させる
+func (r *Registry) Drain() {
+	r.draining.Store(true)
+}
+
+// Check は全てのチェックを並行して実行し、名前ごとの結果を返す
+// 成功したチェックの結果はnilになる。停止処理中は"shutdown"の結果にErrShuttingDownを含める
+func (r *Registry) Check(ctx context.Context) map[string]error {
+	r.mu.RLock()
+	checks := append([]namedCheck(nil), r.checks...)
+	r.mu.RUnlock()
+
+	results := make(map[string]error, len(checks)+1)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, c := range checks {
+		wg.Add(1)
+		go func(c namedCheck) {
+			defer wg.Done()
+			err := r.run(ctx, c.check)
+			mu.Lock()
+			results[c.name] = err
+			mu.Unlock()
+		}(c)
+	}
+	wg.Wait()
+
+	if r.draining.Load() {
+		results["shutdown"] = ErrShuttingDown
+	}
+	return results
+}
+
+func (r *Registry) run(ctx context.Context, check Check) (err error) {
+	if r.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.timeout)
+		defer cancel()
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("check panicked: %v", p)
+		}
+	}()
+	return check(ctx)
+}
+
+// Pinger はデータベースへの疎通を確認する。*sql.DBが実装する
+type Pinger interface {
+	PingContext(ctx context.Context) error
+}
+
+// Ping はデータベースに疎通できるかを確認するチェックを返す
+func Ping(db Pinger) Check {
+	return func(ctx context.Context) error {
+		return db.PingContext(ctx)
+	}
+}
+
+// Runner は常駐して処理を行うワーカー
+type Runner interface {
+	Running() bool
+}
+
+// Running はワーカーが動いているかを確認するチェックを返す
+func Running(w Runner) Check {
+	return func(ctx context.Context) error {
+		if !w.Running() {
+			return errors.New("not running")
+		}
+		return nil
+	}
+}
@@ -0,0 +1,60 @@
+package health_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/fuki01/onion-architecture/infrastructure/health"
+	"github.com/stretchr/testify/assert"
+)
+
+type stubRunner bool
+
+func (r stubRunner) Running() bool {
+	return bool(r)
+}
+
+func TestRegistry(t *testing.T) {
+	t.Run("reports each check", func(t *testing.T) {
+		checks := health.NewRegistry(time.Second)
+		checks.Register("database", func(ctx context.Context) error { return nil })
+		checks.Register("scheduler", health.Running(stubRunner(false)))
+
+		results := checks.Check(context.Background())
+		assert.Len(t, results, 2)
+		assert.NoError(t, results["database"])
+		assert.EqualError(t, results["scheduler"], "not running")
+	})
+
+	t.Run("slow checks time out", func(t *testing.T) {
+		checks := health.NewRegistry(10 * time.Millisecond)
+		checks.Register("database", func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+
+		results := checks.Check(context.Background())
+		assert.ErrorIs(t, results["database"], context.DeadlineExceeded)
+	})
+
+	t.Run("register replaces a check with the same name", func(t *testing.T) {
+		checks := health.NewRegistry(time.Second)
+		checks.Register("database", func(ctx context.Context) error { return errors.New("down") })
+		checks.Register("database", func(ctx context.Context) error { return nil })
+
+		results := checks.Check(context.Background())
+		assert.Equal(t, map[string]error{"database": nil}, results)
+	})
+
+	t.Run("fails while draining", func(t *testing.T) {
+		checks := health.NewRegistry(time.Second)
+		checks.Register("database", func(ctx context.Context) error { return nil })
+		checks.Drain()
+
+		results := checks.Check(context.Background())
+		assert.NoError(t, results["database"])
+		assert.ErrorIs(t, results["shutdown"], health.ErrShuttingDown)
+	})
+}
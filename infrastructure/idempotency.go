@@ -0,0 +1,68 @@
+package infrastructure
+
+// idempotency_repositoryの実装
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"gorm.io/gorm"
+
+	"github.com/fuki01/onion-architecture/domain/idempotency"
+	"github.com/fuki01/onion-architecture/domain/repository"
+	"github.com/fuki01/onion-architecture/domain/user"
+)
+
+type idempotencyPersistence struct {
+	db *gorm.DB
+}
+
+func NewIdempotencyPersistence(db *gorm.DB) repository.IdempotencyRepository {
+	return &idempotencyPersistence{
+		db: db,
+	}
+}
+
+// Insert は記録を登録する
+// 主キーの一意制約で、同じキーのリクエストを同時に受け付けても一方のみ成功する
+func (ip *idempotencyPersistence) Insert(ctx context.Context, r *idempotency.Record) error {
+	err := ip.db.WithContext(ctx).Create(r).Error
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlDuplicateEntry {
+		return repository.ErrAlreadyExists
+	}
+	return err
+}
+
+// Find は指定したユーザーとキーの記録を取得する
+func (ip *idempotencyPersistence) Find(ctx context.Context, userId user.UserId, keyHash string) (*idempotency.Record, error) {
+	var r idempotency.Record
+	if err := ip.db.WithContext(ctx).First(&r, "user_id = ? AND key_hash = ?", userId, keyHash).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, repository.ErrNotFound
+		}
+		return nil, err
+	}
+	return &r, nil
+}
+
+// Update は記録を更新する
+func (ip *idempotencyPersistence) Update(ctx context.Context, r *idempotency.Record) error {
+	return ip.db.WithContext(ctx).Save(r).Error
+}
+
+// Delete は記録を削除する
+// 同時に別のリクエストが記録し直した場合に消さないよう、作成日時が一致するものだけを削除する
+func (ip *idempotencyPersistence) Delete(ctx context.Context, r *idempotency.Record) error {
+	return ip.db.WithContext(ctx).
+		Where("user_id = ? AND key_hash = ? AND created_at = ?", r.UserId, r.KeyHash, r.CreatedAt).
+		Delete(&idempotency.Record{}).Error
+}
+
+// DeleteExpired は保存期間を過ぎた記録を削除する
+func (ip *idempotencyPersistence) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	result := ip.db.WithContext(ctx).Where("expires_at <= ?", now).Delete(&idempotency.Record{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,66 @@
+package logging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// gormLogger はGORMのログをslogに出力する
+// 失敗したクエリと遅いクエリを出力し、デバッグレベルでは全てのクエリを出力する
+type gormLogger struct {
+	logger        *slog.Logger
+	slowThreshold time.Duration
+}
+
+// NewGormLogger はslogに出力するGORMのロガーを返す。db.Loggerに設定する
+func NewGormLogger(l *slog.Logger, slowThreshold time.Duration) logger.Interface {
+	return &gormLogger{logger: l, slowThreshold: slowThreshold}
+}
+
+// LogMode は出力するレベルをslogのロガーの設定に任せるため、何も変えない
+func (g *gormLogger) LogMode(logger.LogLevel) logger.Interface {
+	return g
+}
+
+func (g *gormLogger) Info(ctx context.Context, msg string, args ...any) {
+	g.logger.InfoContext(ctx, fmt.Sprintf(msg, args...))
+}
+
+func (g *gormLogger) Warn(ctx context.Context, msg string, args ...any) {
+	g.logger.WarnContext(ctx, fmt.Sprintf(msg, args...))
+}
+
+func (g *gormLogger) Error(ctx context.Context, msg string, args ...any) {
+	g.logger.ErrorContext(ctx, fmt.Sprintf(msg, args...))
+}
+
+func (g *gormLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	elapsed := time.Since(begin)
+	level := slog.LevelDebug
+	msg := "query"
+	switch {
+	// 見つからないことは異常ではないため、エラーとして出力しない
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound):
+		level = slog.LevelError
+		msg = "query failed"
+	case g.slowThreshold > 0 && elapsed > g.slowThreshold:
+		level = slog.LevelWarn
+		msg = "slow query"
+	}
+	if !g.logger.Enabled(ctx, level) {
+		return
+	}
+
+	sql, rows := fc()
+	attrs := []any{slog.String("sql", sql), slog.Int64("rows", rows), slog.Duration("elapsed", elapsed)}
+	if level == slog.LevelError {
+		attrs = append(attrs, slog.Any("error", err))
+	}
+	g.logger.Log(ctx, level, msg, attrs...)
+}
@@ -0,0 +1,74 @@
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+
+	"github.com/fuki01/onion-architecture/infrastructure/config"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Extractor はコンテキストからログに加える属性を取り出す
+type Extractor func(ctx context.Context) []slog.Attr
+
+// StringAttr はfromで取り出した文字列をkeyの属性にするExtractorを返す
+func StringAttr(key string, from func(ctx context.Context) (string, bool)) Extractor {
+	return func(ctx context.Context) []slog.Attr {
+		if v, ok := from(ctx); ok {
+			return []slog.Attr{slog.String(key, v)}
+		}
+		return nil
+	}
+}
+
+// New は設定に従ってwに出力するロガーを返す
+// *Contextのメソッドで出力したログには、extractorsで取り出した属性とトレースIDを加える
+func New(cfg config.LogConfig, w io.Writer, extractors ...Extractor) *slog.Logger {
+	opts := &slog.HandlerOptions{Level: ParseLevel(cfg.Level)}
+	var h slog.Handler
+	if cfg.Format == "json" {
+		h = slog.NewJSONHandler(w, opts)
+	} else {
+		h = slog.NewTextHandler(w, opts)
+	}
+	return slog.New(&contextHandler{Handler: h, extractors: extractors})
+}
+
+// ParseLevel はログレベルの名前を変換する。不明な名前はinfoとして扱う
+func ParseLevel(name string) slog.Level {
+	switch strings.ToLower(name) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	}
+	return slog.LevelInfo
+}
+
+// contextHandler はログにコンテキストの属性を加える
+type contextHandler struct {
+	slog.Handler
+	extractors []Extractor
+}
+
+func (h *contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	for _, extract := range h.extractors {
+		r.AddAttrs(extract(ctx)...)
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		r.AddAttrs(slog.String("trace_id", sc.TraceID().String()), slog.String("span_id", sc.SpanID().String()))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithAttrs(attrs), extractors: h.extractors}
+}
+
+func (h *contextHandler) WithGroup(name string) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithGroup(name), extractors: h.extractors}
+}
@@ -0,0 +1,91 @@
+package logging_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/fuki01/onion-architecture/infrastructure/config"
+	"github.com/fuki01/onion-architecture/infrastructure/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+type requestIDKey struct{}
+
+func requestIDFromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(requestIDKey{}).(string)
+	return id, ok
+}
+
+// 出力したJSONのログを1行ずつ読み込む
+func records(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	var out []map[string]any
+	dec := json.NewDecoder(buf)
+	for dec.More() {
+		var r map[string]any
+		require.NoError(t, dec.Decode(&r))
+		out = append(out, r)
+	}
+	return out
+}
+
+func TestNew(t *testing.T) {
+	t.Run("adds request and trace ids from context", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := logging.New(config.LogConfig{Level: "info", Format: "json"}, &buf, logging.StringAttr("request_id", requestIDFromContext))
+
+		traceId, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+		spanId, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+		ctx := trace.ContextWithSpanContext(context.WithValue(context.Background(), requestIDKey{}, "req-1"),
+			trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceId, SpanID: spanId}))
+		logger.With(slog.String("component", "test")).InfoContext(ctx, "hello")
+		logger.Info("without context")
+
+		got := records(t, &buf)
+		require.Len(t, got, 2)
+		assert.Equal(t, "hello", got[0]["msg"])
+		assert.Equal(t, "test", got[0]["component"])
+		assert.Equal(t, "req-1", got[0]["request_id"])
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", got[0]["trace_id"])
+		assert.Equal(t, "00f067aa0ba902b7", got[0]["span_id"])
+		assert.NotContains(t, got[1], "request_id")
+	})
+
+	t.Run("level", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := logging.New(config.LogConfig{Level: "warn", Format: "text"}, &buf)
+
+		logger.Info("dropped")
+		logger.Warn("kept")
+
+		assert.NotContains(t, buf.String(), "dropped")
+		assert.Contains(t, buf.String(), "level=WARN msg=kept")
+	})
+}
+
+func TestGormLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := logging.NewGormLogger(logging.New(config.LogConfig{Level: "info", Format: "json"}, &buf), 100*time.Millisecond)
+	query := func() (string, int64) { return "SELECT * FROM tasks", 1 }
+
+	logger.Trace(context.Background(), time.Now(), query, nil)
+	logger.Trace(context.Background(), time.Now(), query, gorm.ErrRecordNotFound)
+	logger.Trace(context.Background(), time.Now().Add(-time.Second), query, nil)
+	logger.Trace(context.Background(), time.Now(), query, errors.New("deadlock"))
+
+	// 速く成功したクエリと見つからなかったクエリはinfoでは出力しない
+	got := records(t, &buf)
+	require.Len(t, got, 2)
+	assert.Equal(t, "slow query", got[0]["msg"])
+	assert.Equal(t, "WARN", got[0]["level"])
+	assert.Equal(t, "query failed", got[1]["msg"])
+	assert.Equal(t, "deadlock", got[1]["error"])
+	assert.Equal(t, "SELECT * FROM tasks", got[1]["sql"])
+}
@@ -0,0 +1,92 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/fuki01/onion-architecture/domain/webhook"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Metrics はアプリケーションのメトリクスを集め、Prometheusの形式で公開する
+type Metrics struct {
+	registry *prometheus.Registry
+
+	requests        *prometheus.CounterVec
+	requestDuration *prometheus.HistogramVec
+	taskOperations  *prometheus.CounterVec
+	queryDuration   *prometheus.HistogramVec
+}
+
+// タスクの操作のうち数えるもの
+var taskOperations = map[webhook.EventType]string{
+	webhook.EventTaskCreated:   "created",
+	webhook.EventTaskCompleted: "completed",
+	webhook.EventTaskExtended:  "extended",
+}
+
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_requests_total",
+			Help: "処理したHTTPリクエストの数",
+		}, []string{"method", "route", "status"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "HTTPリクエストの処理にかかった時間",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		taskOperations: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "task_operations_total",
+			Help: "タスクの操作の数",
+		}, []string{"operation"}),
+		queryDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "db_query_duration_seconds",
+			Help:    "データベースのクエリにかかった時間",
+			Buckets: []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+		}, []string{"operation", "table"}),
+	}
+	// 一度も操作がなくても0として公開する
+	for _, op := range taskOperations {
+		m.taskOperations.WithLabelValues(op)
+	}
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.requests,
+		m.requestDuration,
+		m.taskOperations,
+		m.queryDuration,
+	)
+	return m
+}
+
+// Handler はメトリクスを公開するハンドラーを返す
+// 一部のメトリクスの収集に失敗しても、収集できたものは返す
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{ErrorHandling: promhttp.ContinueOnError})
+}
+
+// Register はメトリクスを追加する
+func (m *Metrics) Register(c prometheus.Collector) {
+	m.registry.MustRegister(c)
+}
+
+// ObserveRequest はHTTPリクエストの処理結果を記録する
+// routeはパスではなくルーティングのパターンで、値の種類が増え続けないようにする
+func (m *Metrics) ObserveRequest(method, route string, status int, duration time.Duration) {
+	code := strconv.Itoa(status)
+	m.requests.WithLabelValues(method, route, code).Inc()
+	m.requestDuration.WithLabelValues(method, route, code).Observe(duration.Seconds())
+}
+
+// ObserveTaskOperation はタスクの操作を数える。TaskMetricsとしてTaskUsecaseに渡す
+func (m *Metrics) ObserveTaskOperation(eventType webhook.EventType) {
+	if op, ok := taskOperations[eventType]; ok {
+		m.taskOperations.WithLabelValues(op).Inc()
+	}
+}
@@ -0,0 +1,84 @@
+package metrics_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/fuki01/onion-architecture/domain/webhook"
+	"github.com/fuki01/onion-architecture/infrastructure/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubTaskCounter struct {
+	today         string
+	open, overdue int64
+	err           error
+}
+
+func (s *stubTaskCounter) CountIncomplete(ctx context.Context, today string) (int64, int64, error) {
+	s.today = today
+	return s.open, s.overdue, s.err
+}
+
+// 公開されたメトリクスを取得する
+func scrape(t *testing.T, m *metrics.Metrics) string {
+	w := httptest.NewRecorder()
+	m.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	body, err := io.ReadAll(w.Body)
+	require.NoError(t, err)
+	return string(body)
+}
+
+func TestMetrics(t *testing.T) {
+	t.Run("requests by route and status", func(t *testing.T) {
+		m := metrics.New()
+		m.ObserveRequest("GET", "/api/v1/tasks/:id", http.StatusOK, 20*time.Millisecond)
+		m.ObserveRequest("GET", "/api/v1/tasks/:id", http.StatusOK, 30*time.Millisecond)
+		m.ObserveRequest("GET", "/api/v1/tasks/:id", http.StatusNotFound, time.Millisecond)
+
+		out := scrape(t, m)
+		assert.Contains(t, out, `http_requests_total{method="GET",route="/api/v1/tasks/:id",status="200"} 2`)
+		assert.Contains(t, out, `http_requests_total{method="GET",route="/api/v1/tasks/:id",status="404"} 1`)
+		assert.Contains(t, out, `http_request_duration_seconds_count{method="GET",route="/api/v1/tasks/:id",status="200"} 2`)
+	})
+
+	t.Run("task operations", func(t *testing.T) {
+		m := metrics.New()
+		m.ObserveTaskOperation(webhook.EventTaskCreated)
+		m.ObserveTaskOperation(webhook.EventTaskCreated)
+		m.ObserveTaskOperation(webhook.EventTaskCompleted)
+
+		out := scrape(t, m)
+		assert.Contains(t, out, `task_operations_total{operation="created"} 2`)
+		assert.Contains(t, out, `task_operations_total{operation="completed"} 1`)
+		assert.Contains(t, out, `task_operations_total{operation="extended"} 0`)
+	})
+
+	t.Run("task counts", func(t *testing.T) {
+		m := metrics.New()
+		counter := &stubTaskCounter{open: 7, overdue: 2}
+		now := func() time.Time { return time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC) }
+		m.Register(metrics.NewTaskCollector(counter, now, time.Second))
+
+		out := scrape(t, m)
+		assert.Equal(t, "2024-05-01", counter.today)
+		assert.Contains(t, out, "tasks_open 7")
+		assert.Contains(t, out, "tasks_overdue 2")
+	})
+
+	t.Run("failed task counts do not hide other metrics", func(t *testing.T) {
+		m := metrics.New()
+		m.Register(metrics.NewTaskCollector(&stubTaskCounter{err: errors.New("connection refused")}, time.Now, time.Second))
+		m.ObserveRequest("GET", "/healthz", http.StatusOK, time.Millisecond)
+
+		out := scrape(t, m)
+		assert.NotContains(t, out, "tasks_open")
+		assert.Contains(t, out, `http_requests_total{method="GET",route="/healthz",status="200"} 1`)
+	})
+}
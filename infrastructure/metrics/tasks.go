@@ -0,0 +1,52 @@
+package metrics
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/fuki01/onion-architecture/domain/task"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// TaskCounter は未完了と期限切れのタスクを数える。TaskRepositoryが実装する
+type TaskCounter interface {
+	CountIncomplete(ctx context.Context, today string) (open int64, overdue int64, err error)
+}
+
+var (
+	openTasksDesc    = prometheus.NewDesc("tasks_open", "未完了のタスクの数", nil, nil)
+	overdueTasksDesc = prometheus.NewDesc("tasks_overdue", "期限切れの未完了のタスクの数", nil, nil)
+)
+
+// taskCollector は収集のたびにタスクの数を数える
+type taskCollector struct {
+	counter TaskCounter
+	now     func() time.Time
+	timeout time.Duration
+}
+
+// NewTaskCollector は未完了と期限切れのタスクの数を公開するCollectorを返す
+func NewTaskCollector(counter TaskCounter, now func() time.Time, timeout time.Duration) prometheus.Collector {
+	return &taskCollector{counter: counter, now: now, timeout: timeout}
+}
+
+func (c *taskCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- openTasksDesc
+	ch <- overdueTasksDesc
+}
+
+func (c *taskCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	open, overdue, err := c.counter.CountIncomplete(ctx, c.now().Format(task.DueDateLayout))
+	if err != nil {
+		slog.WarnContext(ctx, "failed to count tasks for metrics", slog.Any("error", err))
+		ch <- prometheus.NewInvalidMetric(openTasksDesc, err)
+		ch <- prometheus.NewInvalidMetric(overdueTasksDesc, err)
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(openTasksDesc, prometheus.GaugeValue, float64(open))
+	ch <- prometheus.MustNewConstMetric(overdueTasksDesc, prometheus.GaugeValue, float64(overdue))
+}
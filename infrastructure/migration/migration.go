@@ -0,0 +1,85 @@
+package migration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/fuki01/onion-architecture/domain/idempotency"
+	"github.com/fuki01/onion-architecture/domain/task"
+	"github.com/fuki01/onion-architecture/domain/user"
+	"github.com/fuki01/onion-architecture/domain/webhook"
+	"github.com/fuki01/onion-architecture/domain/workspace"
+	"github.com/fuki01/onion-architecture/infrastructure/scheduler"
+	"gorm.io/gorm"
+)
+
+// Version はこのバージョンのアプリケーションが必要とするスキーマのバージョン
+// モデルを変更したら1つ上げる
+const Version = 3
+
+// SchemaVersion は適用済みのスキーマのバージョン。1行だけを持つ
+type SchemaVersion struct {
+	Id         int `gorm:"primaryKey"`
+	Version    int
+	MigratedAt time.Time
+}
+
+func models() []any {
+	return []any{
+		&task.Task{}, &task.Tag{}, &task.Dependency{}, &task.Share{}, &task.Assignment{},
+		&workspace.Workspace{}, &workspace.Member{},
+		&scheduler.JobLease{}, &scheduler.JobRun{},
+		&user.User{}, &user.RefreshToken{}, &user.ApiKey{}, &user.NotificationSettings{}, &user.NotificationPreference{},
+		&webhook.Subscription{}, &webhook.Delivery{},
+		&idempotency.Record{},
+		&SchemaVersion{},
+	}
+}
+
+// Migrate はテーブルを作成・更新し、適用したバージョンを記録する
+// 新しいバージョンのプロセスが記録したバージョンは下げない
+func Migrate(db *gorm.DB) error {
+	if err := db.SetupJoinTable(&task.Task{}, "BlockedBy", &task.Dependency{}); err != nil {
+		return fmt.Errorf("setup join table: %w", err)
+	}
+	if err := db.AutoMigrate(models()...); err != nil {
+		return fmt.Errorf("migrate: %w", err)
+	}
+
+	current, err := currentVersion(db)
+	if err != nil {
+		return err
+	}
+	if current >= Version {
+		return nil
+	}
+	return db.Save(&SchemaVersion{Id: 1, Version: Version, MigratedAt: time.Now()}).Error
+}
+
+// Check はスキーマがこのバージョンのアプリケーションに必要なバージョン以上かを確認する
+func Check(db *gorm.DB) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		current, err := currentVersion(db.WithContext(ctx))
+		if err != nil {
+			return err
+		}
+		if current < Version {
+			return fmt.Errorf("schema version %d is older than %d", current, Version)
+		}
+		return nil
+	}
+}
+
+func currentVersion(db *gorm.DB) (int, error) {
+	var v SchemaVersion
+	err := db.First(&v, 1).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("read schema version: %w", err)
+	}
+	return v.Version, nil
+}
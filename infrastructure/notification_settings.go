@@ -0,0 +1,54 @@
+package infrastructure
+
+// notification_repositoryの実装
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+
+	"github.com/fuki01/onion-architecture/domain/repository"
+	"github.com/fuki01/onion-architecture/domain/user"
+)
+
+type notificationSettingsPersistence struct {
+	db *gorm.DB
+}
+
+func NewNotificationSettingsPersistence(db *gorm.DB) repository.NotificationSettingsRepository {
+	return &notificationSettingsPersistence{
+		db: db,
+	}
+}
+
+// FindByUserId は指定したユーザーの通知設定を取得する
+func (np *notificationSettingsPersistence) FindByUserId(userId user.UserId) (*user.NotificationSettings, error) {
+	var s user.NotificationSettings
+	if err := np.db.Preload("Preferences").First(&s, "user_id = ?", userId).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, repository.ErrNotFound
+		}
+		return nil, err
+	}
+	return &s, nil
+}
+
+// Save は通知設定を保存し、通知先を置き換える
+func (np *notificationSettingsPersistence) Save(s *user.NotificationSettings) error {
+	return np.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Preferences").Save(s).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", s.UserId).Delete(&user.NotificationPreference{}).Error; err != nil {
+			return err
+		}
+		for i := range s.Preferences {
+			s.Preferences[i].Id = 0
+			s.Preferences[i].UserId = s.UserId
+		}
+		if len(s.Preferences) == 0 {
+			return nil
+		}
+		return tx.Create(&s.Preferences).Error
+	})
+}
@@ -0,0 +1,84 @@
+package notifier_test
+
+import (
+	"context"
+	"encoding/json"
+	"mime"
+	"net/http"
+	"testing"
+
+	"github.com/fuki01/onion-architecture/domain/user"
+	"github.com/fuki01/onion-architecture/infrastructure/notifier"
+	"github.com/fuki01/onion-architecture/infrastructure/notifier/notifiertest"
+	"github.com/fuki01/onion-architecture/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSMTPNotifier(t *testing.T) {
+	server, err := notifiertest.NewSMTPServer()
+	require.NoError(t, err)
+	defer server.Close()
+
+	n := notifier.NewSMTPNotifier(server.Addr, "noreply@example.com", nil)
+	err = n.Notify(context.Background(), usecase.Notification{
+		Channel: user.ChannelEmail,
+		Target:  "taro@example.com",
+		Subject: "【リマインド】タスク「資料作成」の期限が近づいています",
+		Body:    "タスク「資料作成」の期限は2024-01-10です。\n",
+	})
+	require.NoError(t, err)
+
+	mails := server.Mails()
+	require.Len(t, mails, 1)
+	assert.Equal(t, "noreply@example.com", mails[0].From)
+	assert.Equal(t, []string{"taro@example.com"}, mails[0].To)
+
+	subject, err := new(mime.WordDecoder).DecodeHeader(mails[0].Message.Header.Get("Subject"))
+	require.NoError(t, err)
+	assert.Equal(t, "【リマインド】タスク「資料作成」の期限が近づいています", subject)
+	assert.Contains(t, mails[0].Raw, "タスク「資料作成」の期限は2024-01-10です。")
+}
+
+func TestWebhookNotifier(t *testing.T) {
+	server := notifiertest.NewHTTPServer(http.StatusNoContent)
+	defer server.Close()
+
+	n := notifier.NewWebhookNotifier(server.Client())
+	err := n.Notify(context.Background(), usecase.Notification{
+		Channel: user.ChannelWebhook,
+		Target:  server.URL + "/hooks/tasks",
+		Subject: "subject",
+		Body:    "body",
+	})
+	require.NoError(t, err)
+
+	requests := server.Requests()
+	require.Len(t, requests, 1)
+	assert.Equal(t, "/hooks/tasks", requests[0].Path)
+	assert.Equal(t, "application/json", requests[0].Header.Get("Content-Type"))
+	assert.JSONEq(t, `{"subject":"subject","body":"body"}`, string(requests[0].Body))
+
+	server.SetStatus(http.StatusInternalServerError)
+	assert.Error(t, n.Notify(context.Background(), usecase.Notification{Target: server.URL}))
+}
+
+func TestSlackNotifier(t *testing.T) {
+	server := notifiertest.NewHTTPServer(http.StatusOK)
+	defer server.Close()
+
+	n := notifier.NewSlackNotifier(server.Client())
+	err := n.Notify(context.Background(), usecase.Notification{
+		Channel: user.ChannelSlack,
+		Target:  server.URL,
+		Subject: "Overdue",
+		Body:    "The task is overdue.",
+	})
+	require.NoError(t, err)
+
+	requests := server.Requests()
+	require.Len(t, requests, 1)
+	var payload map[string]string
+	require.NoError(t, json.Unmarshal(requests[0].Body, &payload))
+	assert.Equal(t, "*Overdue*\nThe task is overdue.", payload["text"])
+}
@@ -0,0 +1,62 @@
+package notifiertest
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+)
+
+// Request は受信したHTTPリクエスト
+type Request struct {
+	Method string
+	Path   string
+	Header http.Header
+	Body   []byte
+}
+
+// HTTPServer は受信したリクエストを記録するHTTPサーバー
+type HTTPServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	requests []Request
+	status   int
+}
+
+// NewHTTPServer はstatusを返すHTTPサーバーを起動する
+func NewHTTPServer(status int) *HTTPServer {
+	s := &HTTPServer{status: status}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+// Requests は受信したリクエストを返す
+func (s *HTTPServer) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// SetStatus は以降のリクエストに返すステータスコードを変更する
+func (s *HTTPServer) SetStatus(status int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status = status
+}
+
+func (s *HTTPServer) handle(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	s.mu.Lock()
+	s.requests = append(s.requests, Request{
+		Method: r.Method,
+		Path:   r.URL.Path,
+		Header: r.Header.Clone(),
+		Body:   body,
+	})
+	status := s.status
+	s.mu.Unlock()
+
+	w.WriteHeader(status)
+}
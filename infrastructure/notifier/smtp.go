@@ -0,0 +1,48 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"github.com/fuki01/onion-architecture/usecase"
+)
+
+// SMTPNotifier はメールで通知する
+type SMTPNotifier struct {
+	Addr string
+	From string
+	// Authがnilの場合は認証しない
+	Auth smtp.Auth
+}
+
+func NewSMTPNotifier(addr, from string, auth smtp.Auth) *SMTPNotifier {
+	return &SMTPNotifier{
+		Addr: addr,
+		From: from,
+		Auth: auth,
+	}
+}
+
+// Notify はTargetのアドレスにメールを送る
+func (n *SMTPNotifier) Notify(ctx context.Context, notification usecase.Notification) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "From: %s\r\n", n.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", notification.Target)
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.BEncoding.Encode("UTF-8", notification.Subject))
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	msg.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	msg.WriteString("\r\n")
+	msg.WriteString(strings.ReplaceAll(notification.Body, "\n", "\r\n"))
+
+	return smtp.SendMail(n.Addr, n.Auth, n.From, []string{notification.Target}, []byte(msg.String()))
+}
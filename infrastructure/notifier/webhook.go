@@ -0,0 +1,77 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/fuki01/onion-architecture/usecase"
+)
+
+// WebhookNotifier は汎用のWebhookにJSONをPOSTして通知する
+type WebhookNotifier struct {
+	Client *http.Client
+}
+
+func NewWebhookNotifier(client *http.Client) *WebhookNotifier {
+	return &WebhookNotifier{Client: client}
+}
+
+type webhookPayload struct {
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+}
+
+// Notify はTargetのURLに件名と本文をPOSTする
+func (n *WebhookNotifier) Notify(ctx context.Context, notification usecase.Notification) error {
+	return postJSON(ctx, n.Client, notification.Target, webhookPayload{
+		Subject: notification.Subject,
+		Body:    notification.Body,
+	})
+}
+
+// SlackNotifier はSlack互換のIncoming Webhookで通知する
+type SlackNotifier struct {
+	Client *http.Client
+}
+
+func NewSlackNotifier(client *http.Client) *SlackNotifier {
+	return &SlackNotifier{Client: client}
+}
+
+type slackPayload struct {
+	Text string `json:"text"`
+}
+
+// Notify はTargetのIncoming Webhook URLにメッセージをPOSTする
+func (n *SlackNotifier) Notify(ctx context.Context, notification usecase.Notification) error {
+	return postJSON(ctx, n.Client, notification.Target, slackPayload{
+		Text: fmt.Sprintf("*%s*\n%s", notification.Subject, notification.Body),
+	})
+}
+
+func postJSON(ctx context.Context, client *http.Client, url string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status %d", res.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// sweepInterval ごとに満杯に戻ったバケットを削除し、送り手が増えてもメモリが増え続けないようにする
+const sweepInterval = time.Minute
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	rate    float64
+	burst   float64
+}
+
+// refill はnowまでに補充されるトークンを加える
+func (b *bucket) refill(now time.Time) {
+	b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.updated).Seconds()*b.rate)
+	b.updated = now
+}
+
+// MemoryStore はプロセス内でトークンバケットを保持する
+// 上限はプロセスごとになるため、複数のプロセスで動かす場合は共有のストアを使うこと
+type MemoryStore struct {
+	now func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return NewMemoryStoreWithClock(time.Now)
+}
+
+// NewMemoryStoreWithClock は現在時刻の取得方法を指定したMemoryStoreを返す
+func NewMemoryStoreWithClock(now func() time.Time) *MemoryStore {
+	return &MemoryStore{
+		now:       now,
+		buckets:   map[string]*bucket{},
+		lastSweep: now(),
+	}
+}
+
+// Take はキーのバケットからトークンを1つ取り出す
+// 初めてのキーのバケットは満杯から始める
+func (s *MemoryStore) Take(ctx context.Context, key string, rate float64, burst int) (bool, float64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(burst), updated: now}
+		s.buckets[key] = b
+	}
+	// 設定が変わった場合も新しい設定で補充する
+	b.rate, b.burst = rate, float64(burst)
+	b.refill(now)
+
+	if b.tokens < 1 {
+		return false, b.tokens, nil
+	}
+	b.tokens--
+	return true, b.tokens, nil
+}
+
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+	for key, b := range s.buckets {
+		b.refill(now)
+		if b.tokens >= b.burst {
+			delete(s.buckets, key)
+		}
+	}
+}
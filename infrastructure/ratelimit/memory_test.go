@@ -0,0 +1,49 @@
+package ratelimit_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fuki01/onion-architecture/infrastructure/ratelimit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("allows a burst then refills at rate", func(t *testing.T) {
+		now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		store := ratelimit.NewMemoryStoreWithClock(func() time.Time { return now })
+
+		for i := 0; i < 3; i++ {
+			allowed, _, err := store.Take(ctx, "ip:1", 1, 3)
+			require.NoError(t, err)
+			assert.True(t, allowed)
+		}
+		allowed, tokens, err := store.Take(ctx, "ip:1", 1, 3)
+		require.NoError(t, err)
+		assert.False(t, allowed)
+		assert.Equal(t, 0.0, tokens)
+
+		// 他のキーは別に数える
+		allowed, _, _ = store.Take(ctx, "ip:2", 1, 3)
+		assert.True(t, allowed)
+
+		now = now.Add(1500 * time.Millisecond)
+		allowed, tokens, _ = store.Take(ctx, "ip:1", 1, 3)
+		assert.True(t, allowed)
+		assert.InDelta(t, 0.5, tokens, 1e-9)
+	})
+
+	t.Run("refill does not exceed burst", func(t *testing.T) {
+		now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		store := ratelimit.NewMemoryStoreWithClock(func() time.Time { return now })
+
+		store.Take(ctx, "ip:1", 1, 3)
+		now = now.Add(time.Hour)
+		_, tokens, _ := store.Take(ctx, "ip:1", 1, 3)
+		assert.Equal(t, 2.0, tokens)
+	})
+}
@@ -0,0 +1,62 @@
+package infrastructure
+
+// refresh_token_repositoryの実装
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/fuki01/onion-architecture/domain/repository"
+	"github.com/fuki01/onion-architecture/domain/user"
+)
+
+type refreshTokenPersistence struct {
+	db *gorm.DB
+}
+
+func NewRefreshTokenPersistence(db *gorm.DB) repository.RefreshTokenRepository {
+	return &refreshTokenPersistence{
+		db: db,
+	}
+}
+
+// FindByHash は指定したハッシュのトークンを取得する
+func (rp *refreshTokenPersistence) FindByHash(hash string) (*user.RefreshToken, error) {
+	var t user.RefreshToken
+	if err := rp.db.First(&t, "token_hash = ?", hash).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, repository.ErrNotFound
+		}
+		return nil, err
+	}
+	return &t, nil
+}
+
+// Insert はトークンを登録する
+func (rp *refreshTokenPersistence) Insert(t *user.RefreshToken) error {
+	return rp.db.Create(t).Error
+}
+
+// Revoke は有効なトークンを失効させる
+// 失効済みの行は更新しないため、同時に交換された場合は一方のみ成功する
+func (rp *refreshTokenPersistence) Revoke(t *user.RefreshToken) error {
+	result := rp.db.Model(&user.RefreshToken{}).
+		Where("id = ? AND revoked_at IS NULL", t.Id).
+		Update("revoked_at", t.RevokedAt)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+// RevokeFamily は同じログインから発行された有効なトークンを全て失効させる
+func (rp *refreshTokenPersistence) RevokeFamily(familyId string, at time.Time) error {
+	return rp.db.Model(&user.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyId).
+		Update("revoked_at", at).Error
+}
@@ -0,0 +1,169 @@
+package replica
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/fuki01/onion-architecture/domain/user"
+	"gorm.io/gorm"
+)
+
+// Pool は書き込みをプライマリへ、読み込みをリードレプリカへ振り分ける
+// レプリカがない場合や、全てのレプリカに疎通できない場合はプライマリを使う
+type Pool struct {
+	primary  *gorm.DB
+	replicas []*gorm.DB
+	healthy  []atomic.Bool
+	next     atomic.Uint64
+}
+
+func NewPool(primary *gorm.DB, replicas ...*gorm.DB) *Pool {
+	p := &Pool{
+		primary:  primary,
+		replicas: replicas,
+		healthy:  make([]atomic.Bool, len(replicas)),
+	}
+	for i := range p.healthy {
+		p.healthy[i].Store(true)
+	}
+	return p
+}
+
+// Writer は書き込みに使う接続を返す
+// 同じセッションのそれ以降の読み込みはプライマリから行う
+func (p *Pool) Writer(ctx context.Context) *gorm.DB {
+	if s, ok := ctx.Value(sessionKey{}).(*session); ok {
+		s.wrote.Store(true)
+	}
+	return p.primary.WithContext(ctx)
+}
+
+// Primary は書き込みの前の読み込みなど、最新の状態が必要な読み込みに使う接続を返す
+func (p *Pool) Primary(ctx context.Context) *gorm.DB {
+	return p.primary.WithContext(ctx)
+}
+
+// Reader は読み込みに使う接続を返す
+// セッション内で書き込んだ、または直前に書き込んだユーザーはレプリカの遅延を避けるためプライマリを使う
+// 疎通できないレプリカは飛ばす
+func (p *Pool) Reader(ctx context.Context) *gorm.DB {
+	if len(p.replicas) == 0 {
+		return p.primary.WithContext(ctx)
+	}
+	if s, ok := ctx.Value(sessionKey{}).(*session); ok && (s.sticky || s.wrote.Load()) {
+		return p.primary.WithContext(ctx)
+	}
+	n := uint64(len(p.replicas))
+	start := p.next.Add(1)
+	for i := uint64(0); i < n; i++ {
+		j := (start + i) % n
+		if p.healthy[j].Load() {
+			return p.replicas[j].WithContext(ctx)
+		}
+	}
+	return p.primary.WithContext(ctx)
+}
+
+// CheckReplicas は各レプリカに疎通できるかを確認し、疎通できないレプリカを読み込みに使わないようにする
+// 再び疎通できるようになったレプリカは読み込みに戻す
+func (p *Pool) CheckReplicas(ctx context.Context) {
+	for i, r := range p.replicas {
+		sqlDB, err := r.DB()
+		if err == nil {
+			err = sqlDB.PingContext(ctx)
+		}
+		if p.healthy[i].Swap(err == nil) == (err == nil) {
+			continue
+		}
+		if err != nil {
+			slog.WarnContext(ctx, "replica is unavailable, reading from primary", slog.Int("replica", i), slog.Any("error", err))
+		} else {
+			slog.InfoContext(ctx, "replica is available again", slog.Int("replica", i))
+		}
+	}
+}
+
+// Monitor はctxが終了するまでintervalごとにCheckReplicasを実行する
+func (p *Pool) Monitor(ctx context.Context, interval time.Duration) {
+	if len(p.replicas) == 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			checkCtx, cancel := context.WithTimeout(ctx, interval)
+			p.CheckReplicas(checkCtx)
+			cancel()
+		}
+	}
+}
+
+type sessionKey struct{}
+
+// session は1つのリクエストの間に書き込んだかを記録する
+type session struct {
+	sticky bool
+	wrote  atomic.Bool
+}
+
+// Tracker はユーザーごとに最後に書き込んだ時刻を記録し、windowの間はそのユーザーの読み込みをプライマリに固定する
+// 記録はプロセスごとに持つため、複数のプロセスで動かす場合はロードバランサーでユーザーを振り分けること
+type Tracker struct {
+	window time.Duration
+
+	mu        sync.Mutex
+	lastWrite map[user.UserId]time.Time
+}
+
+func NewTracker(window time.Duration) *Tracker {
+	return &Tracker{
+		window:    window,
+		lastWrite: map[user.UserId]time.Time{},
+	}
+}
+
+// Begin はユーザーのリクエストの開始を記録し、セッションを持つコンテキストと終了時に呼ぶ関数を返す
+func (t *Tracker) Begin(ctx context.Context, userId user.UserId) (context.Context, func()) {
+	s := &session{sticky: t.recentlyWrote(userId)}
+	return context.WithValue(ctx, sessionKey{}, s), func() {
+		if s.wrote.Load() {
+			t.recordWrite(userId)
+		}
+	}
+}
+
+func (t *Tracker) recentlyWrote(userId user.UserId) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	at, ok := t.lastWrite[userId]
+	if !ok {
+		return false
+	}
+	if time.Since(at) >= t.window {
+		delete(t.lastWrite, userId)
+		return false
+	}
+	return true
+}
+
+func (t *Tracker) recordWrite(userId user.UserId) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := time.Now()
+	t.lastWrite[userId] = now
+	// 期限の切れた記録が溜まらないよう、件数が多くなったら掃除する
+	if len(t.lastWrite) > 1024 {
+		for id, at := range t.lastWrite {
+			if now.Sub(at) >= t.window {
+				delete(t.lastWrite, id)
+			}
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package replica_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fuki01/onion-architecture/domain/user"
+	"github.com/fuki01/onion-architecture/infrastructure/replica"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// 接続先を区別できるよう名前を付けたDBを返す
+func namedDB(t *testing.T, name string) *gorm.DB {
+	db, err := gorm.Open(nil, &gorm.Config{})
+	require.NoError(t, err)
+	return db.Set("name", name)
+}
+
+// 停止できる接続先に繋がる名前を付けたDBを返す
+func pingableDB(t *testing.T, name string, down *atomic.Bool) *gorm.DB {
+	db, err := gorm.Open(nil, &gorm.Config{ConnPool: sql.OpenDB(connector{down: down})})
+	require.NoError(t, err)
+	return db.Set("name", name)
+}
+
+// connector はdownがtrueの間は接続に失敗する
+type connector struct {
+	down *atomic.Bool
+}
+
+func (c connector) Connect(context.Context) (driver.Conn, error) {
+	if c.down.Load() {
+		return nil, errors.New("connection refused")
+	}
+	return conn(c), nil
+}
+
+func (c connector) Driver() driver.Driver {
+	return nil
+}
+
+type conn connector
+
+func (c conn) Ping(context.Context) error {
+	if c.down.Load() {
+		return driver.ErrBadConn
+	}
+	return nil
+}
+
+func (conn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (conn) Close() error                        { return nil }
+func (conn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+
+func nameOf(db *gorm.DB) string {
+	name, _ := db.Get("name")
+	return name.(string)
+}
+
+func TestPool(t *testing.T) {
+	primary := namedDB(t, "primary")
+	pool := replica.NewPool(primary, namedDB(t, "replica1"), namedDB(t, "replica2"))
+
+	t.Run("reads are spread across replicas", func(t *testing.T) {
+		ctx := context.Background()
+
+		names := map[string]bool{}
+		for i := 0; i < 4; i++ {
+			names[nameOf(pool.Reader(ctx))] = true
+		}
+		assert.Equal(t, map[string]bool{"replica1": true, "replica2": true}, names)
+		assert.Equal(t, "primary", nameOf(pool.Primary(ctx)))
+	})
+
+	t.Run("reads after a write in the same session use primary", func(t *testing.T) {
+		tracker := replica.NewTracker(time.Hour)
+		ctx, done := tracker.Begin(context.Background(), user.UserId(1))
+
+		assert.NotEqual(t, "primary", nameOf(pool.Reader(ctx)))
+		assert.Equal(t, "primary", nameOf(pool.Writer(ctx)))
+		assert.Equal(t, "primary", nameOf(pool.Reader(ctx)))
+		done()
+
+		// 同じユーザーの次のリクエストもプライマリから読む
+		next, done := tracker.Begin(context.Background(), user.UserId(1))
+		defer done()
+		assert.Equal(t, "primary", nameOf(pool.Reader(next)))
+
+		// 他のユーザーはレプリカから読む
+		other, done := tracker.Begin(context.Background(), user.UserId(2))
+		defer done()
+		assert.NotEqual(t, "primary", nameOf(pool.Reader(other)))
+	})
+
+	t.Run("stickiness expires", func(t *testing.T) {
+		tracker := replica.NewTracker(0)
+		ctx, done := tracker.Begin(context.Background(), user.UserId(1))
+		pool.Writer(ctx)
+		done()
+
+		next, done := tracker.Begin(context.Background(), user.UserId(1))
+		defer done()
+		assert.NotEqual(t, "primary", nameOf(pool.Reader(next)))
+	})
+
+	t.Run("unavailable replicas are skipped", func(t *testing.T) {
+		var down1, down2 atomic.Bool
+		pool := replica.NewPool(primary, pingableDB(t, "replica1", &down1), pingableDB(t, "replica2", &down2))
+		ctx := context.Background()
+
+		down1.Store(true)
+		pool.CheckReplicas(ctx)
+		for i := 0; i < 4; i++ {
+			assert.Equal(t, "replica2", nameOf(pool.Reader(ctx)))
+		}
+
+		// 全てのレプリカに疎通できない場合はプライマリから読む
+		down2.Store(true)
+		pool.CheckReplicas(ctx)
+		assert.Equal(t, "primary", nameOf(pool.Reader(ctx)))
+
+		// 疎通できるようになったレプリカは読み込みに戻す
+		down1.Store(false)
+		pool.CheckReplicas(ctx)
+		assert.Equal(t, "replica1", nameOf(pool.Reader(ctx)))
+	})
+
+	t.Run("without replicas", func(t *testing.T) {
+		pool := replica.NewPool(primary)
+
+		assert.Equal(t, "primary", nameOf(pool.Reader(context.Background())))
+	})
+}
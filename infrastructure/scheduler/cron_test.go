@@ -0,0 +1,49 @@
+package scheduler_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fuki01/onion-architecture/infrastructure/scheduler"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCron(t *testing.T) {
+	for _, spec := range []string{"* * * * *", "*/15 9-18 * * 1-5", "0 0 1,15 * *", "@daily", "30 2 * * 7"} {
+		_, err := scheduler.ParseCron(spec)
+		assert.NoError(t, err, spec)
+	}
+
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "*/0 * * * *", "5-1 * * * *", "a * * * *"} {
+		_, err := scheduler.ParseCron(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestScheduleNext(t *testing.T) {
+	base := time.Date(2024, 1, 31, 10, 7, 30, 0, time.UTC)
+
+	testCases := []struct {
+		spec     string
+		expected time.Time
+	}{
+		{spec: "* * * * *", expected: time.Date(2024, 1, 31, 10, 8, 0, 0, time.UTC)},
+		{spec: "*/15 * * * *", expected: time.Date(2024, 1, 31, 10, 15, 0, 0, time.UTC)},
+		{spec: "0 * * * *", expected: time.Date(2024, 1, 31, 11, 0, 0, 0, time.UTC)},
+		{spec: "@daily", expected: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{spec: "0 9 * * 1-5", expected: time.Date(2024, 2, 1, 9, 0, 0, 0, time.UTC)},
+		{spec: "0 0 30 * *", expected: time.Date(2024, 3, 30, 0, 0, 0, 0, time.UTC)},
+		{spec: "0 0 29 2 *", expected: time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{spec: "0 0 * * 0", expected: time.Date(2024, 2, 4, 0, 0, 0, 0, time.UTC)},
+		// 日と曜日の両方を指定した場合はどちらかを満たせばよい
+		{spec: "0 0 15 * 5", expected: time.Date(2024, 2, 2, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.spec, func(t *testing.T) {
+			schedule, err := scheduler.ParseCron(tc.spec)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, schedule.Next(base))
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Job は定期実行する処理
+type Job func(ctx context.Context) error
+
+type entry struct {
+	name     string
+	schedule *Schedule
+	job      Job
+}
+
+// Scheduler はcron式に従ってジョブを実行する
+// 複数のプロセスで動かしてもLockerのリースを取得したプロセスだけが実行する
+type Scheduler struct {
+	locker   Locker
+	recorder Recorder
+	holder   string
+	entries  []entry
+
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	started atomic.Bool
+	alive   atomic.Int64
+}
+
+func NewScheduler(locker Locker, recorder Recorder) *Scheduler {
+	hostname, _ := os.Hostname()
+	return &Scheduler{
+		locker:   locker,
+		recorder: recorder,
+		holder:   fmt.Sprintf("%s-%d", hostname, os.Getpid()),
+	}
+}
+
+// Register はジョブを登録する
+// Startの後に登録したジョブは実行されない
+func (s *Scheduler) Register(name, spec string, job Job) error {
+	schedule, err := ParseCron(spec)
+	if err != nil {
+		return fmt.Errorf("job %s: %w", name, err)
+	}
+	s.entries = append(s.entries, entry{name: name, schedule: schedule, job: job})
+	return nil
+}
+
+// Start は登録済みのジョブの実行を開始する
+func (s *Scheduler) Start(ctx context.Context) {
+	ctx, s.cancel = context.WithCancel(ctx)
+	for _, e := range s.entries {
+		s.wg.Add(1)
+		s.alive.Add(1)
+		go func(e entry) {
+			defer s.wg.Done()
+			defer s.alive.Add(-1)
+			s.loop(ctx, e)
+		}(e)
+	}
+	s.started.Store(true)
+}
+
+// Running は開始済みで、登録した全てのジョブの実行ループが動いているかを返す
+func (s *Scheduler) Running() bool {
+	return s.started.Load() && s.alive.Load() == int64(len(s.entries))
+}
+
+// Stop は新たな実行を止め、実行中のジョブの終了を待つ
+// ctxの期限までに終わらない場合はエラーを返す
+func (s *Scheduler) Stop(ctx context.Context) error {
+	if s.cancel != nil {
+		s.cancel()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *Scheduler) loop(ctx context.Context, e entry) {
+	for {
+		next := e.schedule.Next(time.Now())
+		if next.IsZero() {
+			return
+		}
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		s.run(ctx, e, next)
+	}
+}
+
+func (s *Scheduler) run(ctx context.Context, e entry, scheduledAt time.Time) {
+	// 次回の実行予定時刻までリースを保持し、他のプロセスが同じ回を実行しないようにする
+	until := e.schedule.Next(scheduledAt)
+	acquired, err := s.locker.TryAcquire(e.name, s.holder, until)
+	if err != nil {
+		slog.Error("failed to acquire job lease", slog.String("job", e.name), slog.Any("error", err))
+		return
+	}
+	if !acquired {
+		return
+	}
+
+	run := &JobRun{
+		Name:        e.name,
+		Holder:      s.holder,
+		ScheduledAt: scheduledAt,
+		StartedAt:   time.Now(),
+		Status:      RunSucceeded,
+	}
+	// 停止要求を受けても実行中のジョブは最後まで走らせる
+	if err := e.job(context.WithoutCancel(ctx)); err != nil {
+		run.Status = RunFailed
+		run.Error = err.Error()
+		slog.Error("job failed", slog.String("job", e.name), slog.Any("error", err))
+	}
+	run.FinishedAt = time.Now()
+
+	if err := s.recorder.Record(run); err != nil {
+		slog.Error("failed to record job run", slog.String("job", e.name), slog.Any("error", err))
+	}
+}
@@ -0,0 +1,95 @@
+package scheduler
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Locker は複数のプロセスのうち1つだけがジョブを実行するためのリース
+type Locker interface {
+	// TryAcquire はリースを取得できた場合にtrueを返す
+	// 他の保持者のリースが有効な間は取得できない
+	TryAcquire(name, holder string, until time.Time) (bool, error)
+}
+
+// Recorder はジョブの実行履歴を記録する
+type Recorder interface {
+	Record(run *JobRun) error
+}
+
+// JobLease はジョブごとのリース
+type JobLease struct {
+	Name      string    `gorm:"primaryKey;size:191"`
+	Holder    string    `gorm:"size:191"`
+	ExpiresAt time.Time `gorm:"index"`
+}
+
+const (
+	RunSucceeded = "succeeded"
+	RunFailed    = "failed"
+)
+
+// JobRun はジョブの実行履歴
+type JobRun struct {
+	Id          int       `json:"id" gorm:"primaryKey"`
+	Name        string    `json:"name" gorm:"index;size:191"`
+	Holder      string    `json:"holder"`
+	ScheduledAt time.Time `json:"scheduled_at"`
+	StartedAt   time.Time `json:"started_at"`
+	FinishedAt  time.Time `json:"finished_at"`
+	Status      string    `json:"status"`
+	Error       string    `json:"error" gorm:"type:text"`
+}
+
+type gormStore struct {
+	db *gorm.DB
+}
+
+// NewGormStore はDBのテーブルでリースと実行履歴を管理するストアを返す
+func NewGormStore(db *gorm.DB) interface {
+	Locker
+	Recorder
+} {
+	return &gormStore{db: db}
+}
+
+// TryAcquire は期限切れか自身が保持しているリースを更新し、なければ作成する
+func (s *gormStore) TryAcquire(name, holder string, until time.Time) (bool, error) {
+	now := time.Now()
+	result := s.db.Model(&JobLease{}).
+		Where("name = ? AND (expires_at < ? OR holder = ?)", name, now, holder).
+		Updates(map[string]interface{}{"holder": holder, "expires_at": until})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected > 0 {
+		return true, nil
+	}
+
+	result = s.db.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&JobLease{Name: name, Holder: holder, ExpiresAt: until})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected > 0 {
+		return true, nil
+	}
+
+	// 同時に作成された場合に備えて保持者を確認する
+	var lease JobLease
+	if err := s.db.First(&lease, "name = ?", name).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return lease.Holder == holder, nil
+}
+
+// Record は実行履歴を保存する
+func (s *gormStore) Record(run *JobRun) error {
+	return s.db.Create(run).Error
+}
@@ -0,0 +1,95 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/fuki01/onion-architecture/infrastructure/config"
+)
+
+// Server はタイムアウトを設定したHTTPサーバー
+type Server struct {
+	http            *http.Server
+	tlsCertFile     string
+	tlsKeyFile      string
+	drainDelay      time.Duration
+	shutdownTimeout time.Duration
+	onDrain         []func()
+}
+
+func New(cfg config.ServerConfig, handler http.Handler) *Server {
+	return &Server{
+		http: &http.Server{
+			Addr:              cfg.Addr,
+			Handler:           handler,
+			ReadTimeout:       cfg.ReadTimeout,
+			ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+			WriteTimeout:      cfg.WriteTimeout,
+			IdleTimeout:       cfg.IdleTimeout,
+			MaxHeaderBytes:    cfg.MaxHeaderBytes,
+		},
+		tlsCertFile:     cfg.TLSCertFile,
+		tlsKeyFile:      cfg.TLSKeyFile,
+		drainDelay:      cfg.DrainDelay,
+		shutdownTimeout: cfg.ShutdownTimeout,
+	}
+}
+
+// OnDrain は停止を始めるときに呼ぶ関数を登録する
+// 呼び出しの後もdrainDelayの間は新しいリクエストを受け付ける
+func (s *Server) OnDrain(f func()) {
+	s.onDrain = append(s.onDrain, f)
+}
+
+// Run は設定したアドレスで待ち受け、ctxが終了するまでリクエストを処理する
+func (s *Server) Run(ctx context.Context) error {
+	ln, err := net.Listen("tcp", s.http.Addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(ctx, ln)
+}
+
+// Serve はlnで受け付けたリクエストを処理する
+// ctxが終了するとOnDrainで登録した関数を呼び、drainDelayの後に新しい接続の受け付けをやめ、処理中のリクエストの完了を待ってから戻る
+// shutdownTimeoutを過ぎても完了しない接続は切断する
+func (s *Server) Serve(ctx context.Context, ln net.Listener) error {
+	errCh := make(chan error, 1)
+	go func() {
+		var err error
+		if s.tlsCertFile != "" {
+			err = s.http.ServeTLS(ln, s.tlsCertFile, s.tlsKeyFile)
+		} else {
+			err = s.http.Serve(ln)
+		}
+		errCh <- err
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+
+	for _, f := range s.onDrain {
+		f()
+	}
+	if s.drainDelay > 0 {
+		time.Sleep(s.drainDelay)
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), s.shutdownTimeout)
+	defer cancel()
+	if err := s.http.Shutdown(shutdownCtx); err != nil {
+		s.http.Close()
+		return fmt.Errorf("failed to drain connections: %w", err)
+	}
+	if err := <-errCh; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
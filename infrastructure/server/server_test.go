@@ -0,0 +1,97 @@
+package server_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/fuki01/onion-architecture/infrastructure/config"
+	"github.com/fuki01/onion-architecture/infrastructure/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// 処理の開始を通知し、releaseが閉じられるまで応答を待たせるハンドラー
+func slowHandler(started chan<- struct{}, release <-chan struct{}) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+		<-release
+		io.WriteString(w, "done")
+	})
+}
+
+func TestServe(t *testing.T) {
+	t.Run("drains in-flight requests", func(t *testing.T) {
+		started, release := make(chan struct{}), make(chan struct{})
+		srv := server.New(config.ServerConfig{ShutdownTimeout: 5 * time.Second}, slowHandler(started, release))
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		stopped := make(chan error, 1)
+		go func() { stopped <- srv.Serve(ctx, ln) }()
+
+		body := make(chan string, 1)
+		go func() {
+			res, err := http.Get("http://" + ln.Addr().String())
+			if err != nil {
+				body <- err.Error()
+				return
+			}
+			defer res.Body.Close()
+			b, _ := io.ReadAll(res.Body)
+			body <- string(b)
+		}()
+
+		<-started
+		cancel()
+		// 停止を始めた後も処理中のリクエストには応答する
+		time.Sleep(50 * time.Millisecond)
+		close(release)
+
+		assert.Equal(t, "done", <-body)
+		assert.NoError(t, <-stopped)
+	})
+
+	t.Run("gives up after shutdown timeout", func(t *testing.T) {
+		started, release := make(chan struct{}), make(chan struct{})
+		defer close(release)
+		srv := server.New(config.ServerConfig{ShutdownTimeout: 50 * time.Millisecond}, slowHandler(started, release))
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		stopped := make(chan error, 1)
+		go func() { stopped <- srv.Serve(ctx, ln) }()
+		go http.Get("http://" + ln.Addr().String())
+
+		<-started
+		cancel()
+
+		assert.ErrorIs(t, <-stopped, context.DeadlineExceeded)
+	})
+	t.Run("keeps accepting requests during drain delay", func(t *testing.T) {
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { io.WriteString(w, "ok") })
+		srv := server.New(config.ServerConfig{DrainDelay: 200 * time.Millisecond, ShutdownTimeout: time.Second}, handler)
+		drained := make(chan struct{})
+		srv.OnDrain(func() { close(drained) })
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		stopped := make(chan error, 1)
+		go func() { stopped <- srv.Serve(ctx, ln) }()
+
+		cancel()
+		<-drained
+		res, err := http.Get("http://" + ln.Addr().String())
+		require.NoError(t, err)
+		res.Body.Close()
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+
+		assert.NoError(t, <-stopped)
+	})
+}
@@ -3,55 +3,304 @@ package infrastructure
 // task_repositoryの実装
 
 import (
+	"context"
+	"errors"
+
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"github.com/fuki01/onion-architecture/domain/repository"
 	"github.com/fuki01/onion-architecture/domain/task"
 	"github.com/fuki01/onion-architecture/domain/user"
+	"github.com/fuki01/onion-architecture/domain/workspace"
+	"github.com/fuki01/onion-architecture/infrastructure/replica"
 )
 
+// 一覧の取得はリードレプリカから行う
+// 1件の取得は変更の前に読み込むため、常にプライマリから行う
 type taskPersistence struct {
-	db *gorm.DB
+	db *replica.Pool
 }
 
-func NewArticlePersistence(db *gorm.DB) repository.TaskRepository {
+func NewArticlePersistence(db *replica.Pool) repository.TaskRepository {
 	return &taskPersistence{
 		db: db,
 	}
 }
 
 // FindById は指定したIDのタスクを取得する
-func (tr *taskPersistence) FindById(id task.TaskId) (*task.Task, error) {
+func (tr *taskPersistence) FindById(ctx context.Context, id task.TaskId) (*task.Task, error) {
 	var t task.Task
-	if err := tr.db.First(&t, id).Error; err != nil {
+	if err := tr.preload(tr.db.Primary(ctx)).First(&t, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, repository.ErrNotFound
+		}
 		return nil, err
 	}
 	return &t, nil
 }
 
-// FindByUserId は指定したユーザーIDのタスクを取得する
-func (tr *taskPersistence) FindByUserId(userId user.UserId) ([]*task.Task, error) {
+// FindByCreatedBy は指定したユーザーが作成したタスクを絞り込み条件に従って取得する
+func (tr *taskPersistence) FindByCreatedBy(ctx context.Context, userId user.UserId, filter task.Filter) ([]*task.Task, error) {
+	db := tr.db.Reader(ctx)
+	return tr.findFiltered(db, tr.visibleTo(db, userId).Where("user_id = ?", userId), filter)
+}
+
+// FindByAssigneeId は指定したユーザーが担当するタスクを絞り込み条件に従って取得する
+func (tr *taskPersistence) FindByAssigneeId(ctx context.Context, userId user.UserId, filter task.Filter) ([]*task.Task, error) {
+	db := tr.db.Reader(ctx)
+	return tr.findFiltered(db, tr.visibleTo(db, userId).Where("assignee_id = ?", userId), filter)
+}
+
+// FindByWorkspaceId は指定したワークスペースのタスクを絞り込み条件に従って取得する
+func (tr *taskPersistence) FindByWorkspaceId(ctx context.Context, workspaceId workspace.WorkspaceId, filter task.Filter) ([]*task.Task, error) {
+	db := tr.db.Reader(ctx)
+	return tr.findFiltered(db, db.Where("workspace_id = ?", workspaceId), filter)
+}
+
+// visibleTo はユーザーが参照できるタスクに限定する
+// ワークスペースのタスクはメンバーであるワークスペースのもの、それ以外のタスクは所有者か共有されたもので、子タスクは親タスクの所有者と共有されたユーザーも含む
+func (tr *taskPersistence) visibleTo(db *gorm.DB, userId user.UserId) *gorm.DB {
+	joined := db.Model(&workspace.Member{}).Select("workspace_id").Where("user_id = ?", userId)
+	shared := db.Model(&task.Share{}).Select("task_id").Where("user_id = ?", userId)
+	owned := db.Model(&task.Task{}).Select("id").Where("user_id = ?", userId)
+	return db.Where(
+		"workspace_id IN (?) OR (workspace_id IS NULL AND (user_id = ? OR id IN (?) OR parent_id IN (?) OR parent_id IN (?)))",
+		joined, userId, shared, owned, shared,
+	)
+}
+
+// findFiltered は絞り込み条件を加えてタスクを取得する
+func (tr *taskPersistence) findFiltered(db *gorm.DB, query *gorm.DB, filter task.Filter) ([]*task.Task, error) {
+	var tasks []*task.Task
+	query = tr.preload(query)
+	if filter.Priority != "" {
+		query = query.Where("priority = ?", filter.Priority)
+	}
+	if filter.Overdue {
+		query = query.Where("status = ? AND due_date < ?", task.StatusIncomplete, filter.Now.Format(task.DueDateLayout))
+	}
+	if filter.Tag != "" {
+		tagged := db.Table("task_tags").
+			Select("task_tags.task_id").
+			Joins("JOIN tags ON tags.id = task_tags.tag_id").
+			Where("tags.name = ?", filter.Tag)
+		query = query.Where("id IN (?)", tagged)
+	}
+	if err := query.Find(&tasks).Error; err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+// FindIncompleteDueBy は期限日が指定日以前の未完了タスクを取得する
+// 関連は読み込まないため、取得したタスクはUpdateではなくUpdateReminderStateで更新する
+func (tr *taskPersistence) FindIncompleteDueBy(ctx context.Context, dueDate string) ([]*task.Task, error) {
 	var tasks []*task.Task
-	if err := tr.db.Where("user_id = ?", userId).Find(&tasks).Error; err != nil {
+	if err := tr.db.Primary(ctx).Where("status = ? AND due_date <= ?", task.StatusIncomplete, dueDate).Find(&tasks).Error; err != nil {
 		return nil, err
 	}
 	return tasks, nil
 }
 
+// CountIncomplete は未完了のタスクと期限切れのタスクを数える
+func (tr *taskPersistence) CountIncomplete(ctx context.Context, today string) (int64, int64, error) {
+	var counts struct {
+		Open    int64
+		Overdue int64
+	}
+	err := tr.db.Reader(ctx).Model(&task.Task{}).
+		Select("COUNT(*) AS open, COALESCE(SUM(CASE WHEN due_date < ? THEN 1 ELSE 0 END), 0) AS overdue", today).
+		Where("status = ?", task.StatusIncomplete).
+		Scan(&counts).Error
+	if err != nil {
+		return 0, 0, err
+	}
+	return counts.Open, counts.Overdue, nil
+}
+
 // Insert はタスクを登録する
-func (tr *taskPersistence) Insert(t *task.Task) (task.TaskId, error) {
-	if err := tr.db.Create(t).Error; err != nil {
+func (tr *taskPersistence) Insert(ctx context.Context, t *task.Task) (task.TaskId, error) {
+	err := tr.db.Writer(ctx).Transaction(func(tx *gorm.DB) error {
+		return insertTask(tx, t)
+	})
+	if err != nil {
 		return 0, err
 	}
 	return t.Id, nil
 }
 
 // Update はタスクを更新する
-func (tr *taskPersistence) Update(t *task.Task) error {
-	return tr.db.Save(t).Error
+func (tr *taskPersistence) Update(ctx context.Context, t *task.Task) error {
+	return tr.db.Writer(ctx).Transaction(func(tx *gorm.DB) error {
+		return updateTask(tx, t)
+	})
+}
+
+// UpdateWithNext はタスクを更新し、次回分のタスクを登録する
+// 片方のみが反映されて繰り返しが途切れたり重複したりしないよう、同じトランザクションで行う
+func (tr *taskPersistence) UpdateWithNext(ctx context.Context, t *task.Task, next *task.Task) error {
+	return tr.db.Writer(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := updateTask(tx, t); err != nil {
+			return err
+		}
+		return insertTask(tx, next)
+	})
+}
+
+// insertTask はトランザクション内でタスクとタグを登録する
+func insertTask(tx *gorm.DB, t *task.Task) error {
+	if err := resolveTags(tx, t.Tags); err != nil {
+		return err
+	}
+	return tx.Create(t).Error
+}
+
+// updateTask はトランザクション内でタスクを更新し、タグを置き換える
+func updateTask(tx *gorm.DB, t *task.Task) error {
+	if err := resolveTags(tx, t.Tags); err != nil {
+		return err
+	}
+	if err := tx.Omit("Tags", "Subtasks", "BlockedBy", "Shares").Save(t).Error; err != nil {
+		return err
+	}
+	return tx.Model(t).Association("Tags").Replace(t.Tags)
+}
+
+// UpdatePositions は子タスクの表示順のみを更新する
+// 並べ替えの途中で失敗しても表示順が重複しないよう、全ての子タスクを同じトランザクションで更新する
+func (tr *taskPersistence) UpdatePositions(ctx context.Context, tasks []*task.Task) error {
+	return tr.db.Writer(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, t := range tasks {
+			if err := tx.Model(t).UpdateColumn("position", t.Position).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// UpdateReminderState はリマインドした日時と期限切れになった日時のみを更新する
+// 定期処理は関連を読み込まずにタスクを取得するため、他の列と関連は変更しない
+func (tr *taskPersistence) UpdateReminderState(ctx context.Context, t *task.Task) error {
+	return tr.db.Writer(ctx).Model(t).UpdateColumns(map[string]any{
+		"reminded_at": t.RemindedAt,
+		"overdue_at":  t.OverdueAt,
+	}).Error
 }
 
 // Delete はタスクを削除する
-func (tr *taskPersistence) Delete(t *task.Task) error {
-	return tr.db.Delete(t).Error
+func (tr *taskPersistence) Delete(ctx context.Context, t *task.Task) error {
+	return tr.db.Writer(ctx).Select("Tags", "Shares").Delete(t).Error
+}
+
+// FindDependencies は指定したタスクから到達できる依存関係を取得する
+func (tr *taskPersistence) FindDependencies(ctx context.Context, id task.TaskId) ([]task.Dependency, error) {
+	return findReachableDependencies(tr.db.Primary(ctx), id)
+}
+
+// AddDependency は依存関係を登録する
+// 依存先から辿れる依存関係を行ロックを取って読み込むため、同時に逆向きの依存関係を登録しても循環しない
+func (tr *taskPersistence) AddDependency(ctx context.Context, dep task.Dependency) error {
+	return tr.db.Writer(ctx).Transaction(func(tx *gorm.DB) error {
+		deps, err := findReachableDependencies(tx.Clauses(clause.Locking{Strength: "UPDATE"}), dep.BlockedById)
+		if err != nil {
+			return err
+		}
+		if err := task.NewDependencyGraph(deps).Add(dep); err != nil {
+			return err
+		}
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&dep).Error
+	})
+}
+
+// findReachableDependencies はfromから依存先を幅優先で辿り、到達した依存関係を取得する
+func findReachableDependencies(db *gorm.DB, from task.TaskId) ([]task.Dependency, error) {
+	var deps []task.Dependency
+	visited := map[task.TaskId]bool{from: true}
+	frontier := []task.TaskId{from}
+	for len(frontier) > 0 {
+		var found []task.Dependency
+		if err := db.Where("task_id IN ?", frontier).Order("task_id, blocked_by_id").Find(&found).Error; err != nil {
+			return nil, err
+		}
+		frontier = nil
+		for _, dep := range found {
+			deps = append(deps, dep)
+			if !visited[dep.BlockedById] {
+				visited[dep.BlockedById] = true
+				frontier = append(frontier, dep.BlockedById)
+			}
+		}
+	}
+	return deps, nil
+}
+
+// RemoveDependency は依存関係を削除する
+func (tr *taskPersistence) RemoveDependency(ctx context.Context, dep task.Dependency) error {
+	result := tr.db.Writer(ctx).Where(&dep).Delete(&task.Dependency{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+// AddShare はタスクの共有を登録する
+func (tr *taskPersistence) AddShare(ctx context.Context, share task.Share) error {
+	return tr.db.Writer(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&share).Error
+}
+
+// RemoveShare はタスクの共有を削除する
+func (tr *taskPersistence) RemoveShare(ctx context.Context, share task.Share) error {
+	result := tr.db.Writer(ctx).Where(&share).Delete(&task.Share{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+// Reassign はタスクの担当者を更新し、変更履歴を登録する
+func (tr *taskPersistence) Reassign(ctx context.Context, t *task.Task, a *task.Assignment) error {
+	return tr.db.Writer(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(t).Update("assignee_id", t.AssigneeId).Error; err != nil {
+			return err
+		}
+		return tx.Create(a).Error
+	})
+}
+
+// FindAssignments はタスクの担当者の変更履歴を古い順に取得する
+func (tr *taskPersistence) FindAssignments(ctx context.Context, id task.TaskId) ([]task.Assignment, error) {
+	var assignments []task.Assignment
+	if err := tr.db.Reader(ctx).Where("task_id = ?", id).Order("changed_at, id").Find(&assignments).Error; err != nil {
+		return nil, err
+	}
+	return assignments, nil
+}
+
+// preload はタスクの関連を読み込む
+func (tr *taskPersistence) preload(db *gorm.DB) *gorm.DB {
+	return db.Preload("Tags").
+		Preload("Subtasks", func(db *gorm.DB) *gorm.DB {
+			return db.Order("position")
+		}).
+		Preload("Subtasks.Tags").
+		Preload("BlockedBy").
+		Preload("Shares")
+}
+
+// resolveTags はタグ名に対応するタグを取得し、存在しなければ作成してIDを埋める
+func resolveTags(tx *gorm.DB, tags []task.Tag) error {
+	for i := range tags {
+		if err := tx.Where(task.Tag{Name: tags[i].Name}).FirstOrCreate(&tags[i]).Error; err != nil {
+			return err
+		}
+	}
+	return nil
 }
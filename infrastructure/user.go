@@ -0,0 +1,111 @@
+package infrastructure
+
+// user_repositoryの実装
+
+import (
+	"errors"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/fuki01/onion-architecture/domain/repository"
+	"github.com/fuki01/onion-architecture/domain/user"
+)
+
+// MySQLの一意制約違反のエラー番号
+const mysqlDuplicateEntry = 1062
+
+type userPersistence struct {
+	db *gorm.DB
+}
+
+func NewUserPersistence(db *gorm.DB) repository.UserRepository {
+	return &userPersistence{
+		db: db,
+	}
+}
+
+// FindById は指定したIDのユーザーを取得する
+func (up *userPersistence) FindById(id user.UserId) (*user.User, error) {
+	var u user.User
+	if err := up.db.First(&u, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, repository.ErrNotFound
+		}
+		return nil, err
+	}
+	return &u, nil
+}
+
+// FindByEmail は指定したメールアドレスのユーザーを取得する
+func (up *userPersistence) FindByEmail(email string) (*user.User, error) {
+	var u user.User
+	if err := up.db.First(&u, "email = ?", email).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, repository.ErrNotFound
+		}
+		return nil, err
+	}
+	return &u, nil
+}
+
+// Insert はユーザーを登録する
+// メールアドレスが登録済みの場合はErrEmailTakenを返す
+func (up *userPersistence) Insert(u *user.User) error {
+	err := up.db.Create(u).Error
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlDuplicateEntry {
+		return user.ErrEmailTaken
+	}
+	return err
+}
+
+// UpdatePasswordHash はパスワードのハッシュを更新し、ユーザーのリフレッシュトークンを失効させる
+// 同時に記録されたログインの失敗を上書きしないよう、パスワードの列のみを更新する
+func (up *userPersistence) UpdatePasswordHash(id user.UserId, hash string, at time.Time) error {
+	return up.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&user.User{}).Where("id = ?", id).UpdateColumn("password_hash", hash)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return repository.ErrNotFound
+		}
+		return tx.Model(&user.RefreshToken{}).
+			Where("user_id = ? AND revoked_at IS NULL", id).
+			Update("revoked_at", at).Error
+	})
+}
+
+// RecordLoginFailure はログインの失敗を記録する
+// 同時に失敗した場合も回数を失わないよう、行をロックしてから読み直して更新する
+func (up *userPersistence) RecordLoginFailure(id user.UserId, now time.Time, policy user.LockoutPolicy) (*user.User, error) {
+	var u user.User
+	err := up.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&u, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return repository.ErrNotFound
+			}
+			return err
+		}
+		u.RecordLoginFailure(now, policy)
+		return tx.Model(&u).UpdateColumns(map[string]any{
+			"failed_logins": u.FailedLogins,
+			"locked_until":  u.LockedUntil,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+// ResetLoginFailures はログインの失敗回数とロックを解除する
+func (up *userPersistence) ResetLoginFailures(id user.UserId) error {
+	return up.db.Model(&user.User{}).Where("id = ?", id).UpdateColumns(map[string]any{
+		"failed_logins": 0,
+		"locked_until":  nil,
+	}).Error
+}
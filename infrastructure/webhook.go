@@ -0,0 +1,114 @@
+package infrastructure
+
+// webhook_repositoryの実装
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+
+	"github.com/fuki01/onion-architecture/domain/repository"
+	"github.com/fuki01/onion-architecture/domain/user"
+	"github.com/fuki01/onion-architecture/domain/webhook"
+)
+
+type webhookPersistence struct {
+	db *gorm.DB
+}
+
+func NewWebhookPersistence(db *gorm.DB) repository.WebhookRepository {
+	return &webhookPersistence{
+		db: db,
+	}
+}
+
+// FindSubscriptionById は指定したIDの送信先を取得する
+func (wp *webhookPersistence) FindSubscriptionById(id webhook.SubscriptionId) (*webhook.Subscription, error) {
+	var s webhook.Subscription
+	if err := wp.db.First(&s, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, repository.ErrNotFound
+		}
+		return nil, err
+	}
+	return &s, nil
+}
+
+// HasSubscriptionsFor は指定したイベントを購読している送信先があるかを返す
+// 購読するイベントはJSONの配列で保存しているため、引用符を含めた文字列で一致させる
+func (wp *webhookPersistence) HasSubscriptionsFor(eventType webhook.EventType) (bool, error) {
+	var count int64
+	pattern := "%\"" + string(eventType) + "\"%"
+	if err := wp.db.Model(&webhook.Subscription{}).Where("event_types LIKE ?", pattern).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// FindSubscriptionsByUserIds は指定したユーザーが登録した送信先を取得する
+func (wp *webhookPersistence) FindSubscriptionsByUserIds(userIds []user.UserId) ([]*webhook.Subscription, error) {
+	var subs []*webhook.Subscription
+	if len(userIds) == 0 {
+		return subs, nil
+	}
+	if err := wp.db.Where("user_id IN ?", userIds).Order("id").Find(&subs).Error; err != nil {
+		return nil, err
+	}
+	return subs, nil
+}
+
+// InsertSubscription は送信先を登録する
+func (wp *webhookPersistence) InsertSubscription(s *webhook.Subscription) error {
+	return wp.db.Create(s).Error
+}
+
+// DeleteSubscription は送信先と配送記録を削除する
+func (wp *webhookPersistence) DeleteSubscription(s *webhook.Subscription) error {
+	return wp.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("subscription_id = ?", s.Id).Delete(&webhook.Delivery{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(s).Error
+	})
+}
+
+// FindDeliveryById は指定したIDの配送記録を取得する
+func (wp *webhookPersistence) FindDeliveryById(id webhook.DeliveryId) (*webhook.Delivery, error) {
+	var d webhook.Delivery
+	if err := wp.db.First(&d, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, repository.ErrNotFound
+		}
+		return nil, err
+	}
+	return &d, nil
+}
+
+// FindDeliveriesBySubscriptionId は送信先の配送記録を新しい順に取得する
+func (wp *webhookPersistence) FindDeliveriesBySubscriptionId(id webhook.SubscriptionId) ([]*webhook.Delivery, error) {
+	var deliveries []*webhook.Delivery
+	if err := wp.db.Where("subscription_id = ?", id).Order("id DESC").Find(&deliveries).Error; err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// InsertDelivery は配送記録を登録する
+func (wp *webhookPersistence) InsertDelivery(d *webhook.Delivery) error {
+	return wp.db.Create(d).Error
+}
+
+// UpdateDelivery は配送の試行結果を更新する
+// 削除された配送記録を登録し直さないよう、既存の行のみを更新する
+func (wp *webhookPersistence) UpdateDelivery(d *webhook.Delivery) error {
+	result := wp.db.Model(d).
+		Select("attempts", "status_code", "error", "succeeded", "last_attempt_at").
+		Updates(d)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package webhookclient_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/fuki01/onion-architecture/domain/webhook"
+	"github.com/fuki01/onion-architecture/infrastructure/notifier/notifiertest"
+	"github.com/fuki01/onion-architecture/infrastructure/webhookclient"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSender(t *testing.T) {
+	server := notifiertest.NewHTTPServer(http.StatusAccepted)
+	defer server.Close()
+
+	sub := &webhook.Subscription{Id: 1, URL: server.URL + "/hooks", Secret: "s3cret"}
+	d := &webhook.Delivery{Id: 7, EventType: webhook.EventTaskCreated, Payload: `{"type":"task.created"}`}
+
+	status, err := webhookclient.NewSender(server.Client()).Send(context.Background(), sub, d)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusAccepted, status)
+
+	requests := server.Requests()
+	require.Len(t, requests, 1)
+	header := requests[0].Header
+	assert.Equal(t, "task.created", header.Get(webhookclient.HeaderEvent))
+	assert.Equal(t, "7", header.Get(webhookclient.HeaderDelivery))
+	assert.Equal(t, `{"type":"task.created"}`, string(requests[0].Body))
+
+	expected := webhookclient.Sign("s3cret", header.Get(webhookclient.HeaderTimestamp), requests[0].Body)
+	assert.Equal(t, expected, header.Get(webhookclient.HeaderSignature))
+}
+
+func TestSign(t *testing.T) {
+	// echo -n '1700000000.{}' | openssl dgst -sha256 -hmac key
+	assert.Equal(t,
+		"sha256=9d713ed406bb7076d4123f0dc2c39d2df5c654ed4b0cd56b52c8b4c940bd63ae",
+		webhookclient.Sign("key", "1700000000", []byte("{}")))
+}
+
+func TestCheckURL(t *testing.T) {
+	testCases := []struct {
+		name        string
+		url         string
+		expectedErr error
+	}{
+		{name: "public", url: "https://93.184.216.34/hook"},
+		{name: "loopback", url: "http://127.0.0.1:9090/metrics", expectedErr: webhook.ErrNonPublicURL},
+		{name: "private", url: "http://10.0.0.5/hook", expectedErr: webhook.ErrNonPublicURL},
+		{name: "metadata", url: "http://169.254.169.254/latest/meta-data", expectedErr: webhook.ErrNonPublicURL},
+		{name: "ipv6 loopback", url: "http://[::1]/hook", expectedErr: webhook.ErrNonPublicURL},
+		{name: "ipv4 mapped", url: "http://[::ffff:192.168.0.1]/hook", expectedErr: webhook.ErrNonPublicURL},
+		{name: "shared address space", url: "http://100.64.0.1/hook", expectedErr: webhook.ErrNonPublicURL},
+	}
+
+	sender := webhookclient.NewSender(http.DefaultClient)
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := sender.CheckURL(context.Background(), tc.url)
+			if tc.expectedErr == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, tc.expectedErr)
+			}
+		})
+	}
+}
+
+func TestNewClient(t *testing.T) {
+	server := notifiertest.NewHTTPServer(http.StatusAccepted)
+	defer server.Close()
+
+	// 接続する時点で内部のアドレスを拒否する
+	sub := &webhook.Subscription{Id: 1, URL: server.URL + "/hooks", Secret: "s3cret"}
+	d := &webhook.Delivery{Id: 7, EventType: webhook.EventTaskCreated, Payload: `{}`}
+	_, err := webhookclient.NewSender(webhookclient.NewClient(time.Second)).Send(context.Background(), sub, d)
+	assert.ErrorIs(t, err, webhook.ErrNonPublicURL)
+	assert.Empty(t, server.Requests())
+}
@@ -0,0 +1,74 @@
+package infrastructure
+
+// workspace_repositoryの実装
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+
+	"github.com/fuki01/onion-architecture/domain/repository"
+	"github.com/fuki01/onion-architecture/domain/user"
+	"github.com/fuki01/onion-architecture/domain/workspace"
+)
+
+type workspacePersistence struct {
+	db *gorm.DB
+}
+
+func NewWorkspacePersistence(db *gorm.DB) repository.WorkspaceRepository {
+	return &workspacePersistence{
+		db: db,
+	}
+}
+
+// FindById はメンバーを含めてワークスペースを取得する
+func (wp *workspacePersistence) FindById(id workspace.WorkspaceId) (*workspace.Workspace, error) {
+	var w workspace.Workspace
+	if err := wp.db.Preload("Members").First(&w, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, repository.ErrNotFound
+		}
+		return nil, err
+	}
+	return &w, nil
+}
+
+// FindByUserId はユーザーが参加しているワークスペースを取得する
+func (wp *workspacePersistence) FindByUserId(userId user.UserId) ([]*workspace.Workspace, error) {
+	var workspaces []*workspace.Workspace
+	joined := wp.db.Model(&workspace.Member{}).Select("workspace_id").Where("user_id = ?", userId)
+	if err := wp.db.Preload("Members").Where("id IN (?)", joined).Order("id").Find(&workspaces).Error; err != nil {
+		return nil, err
+	}
+	return workspaces, nil
+}
+
+// Insert はワークスペースをメンバーと一緒に登録する
+func (wp *workspacePersistence) Insert(w *workspace.Workspace) error {
+	return wp.db.Create(w).Error
+}
+
+// AddMember はメンバーを追加する
+func (wp *workspacePersistence) AddMember(m workspace.Member) error {
+	return wp.db.Create(&m).Error
+}
+
+// UpdateMember はメンバーの役割を更新する
+func (wp *workspacePersistence) UpdateMember(m workspace.Member) error {
+	return wp.db.Model(&workspace.Member{}).
+		Where("workspace_id = ? AND user_id = ?", m.WorkspaceId, m.UserId).
+		Update("role", m.Role).Error
+}
+
+// RemoveMember はメンバーを削除する
+func (wp *workspacePersistence) RemoveMember(m workspace.Member) error {
+	result := wp.db.Where("workspace_id = ? AND user_id = ?", m.WorkspaceId, m.UserId).Delete(&workspace.Member{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
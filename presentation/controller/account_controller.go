@@ -0,0 +1,113 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/fuki01/onion-architecture/presentation/request"
+	"github.com/fuki01/onion-architecture/presentation/response"
+	"github.com/fuki01/onion-architecture/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AccountController struct {
+	accountusecase usecase.AccountUsecase
+}
+
+func NewAccountController(accountusecase usecase.AccountUsecase) *AccountController {
+	return &AccountController{
+		accountusecase: accountusecase,
+	}
+}
+
+// ユーザーを登録する
+func (ac *AccountController) Register(c *gin.Context) {
+	var input request.RegisterRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	u, err := ac.accountusecase.Register(usecase.RegisterInput{
+		Name:     input.Name,
+		Email:    input.Email,
+		Password: input.Password,
+	})
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, u)
+}
+
+// ログインしてトークンを発行する
+func (ac *AccountController) Login(c *gin.Context) {
+	var input request.LoginRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tokens, err := ac.accountusecase.Login(input.Email, input.Password)
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.NewTokenResponse(tokens))
+}
+
+// リフレッシュトークンを交換してトークンを再発行する
+func (ac *AccountController) Refresh(c *gin.Context) {
+	var input request.RefreshTokenRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tokens, err := ac.accountusecase.Refresh(input.RefreshToken)
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.NewTokenResponse(tokens))
+}
+
+// ログアウトする
+func (ac *AccountController) Logout(c *gin.Context) {
+	var input request.RefreshTokenRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := ac.accountusecase.Logout(input.RefreshToken); err != nil {
+		errorResponse(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// パスワードを変更する
+func (ac *AccountController) ChangePassword(c *gin.Context) {
+	actor, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var input request.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := ac.accountusecase.ChangePassword(actor, input.CurrentPassword, input.NewPassword); err != nil {
+		errorResponse(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
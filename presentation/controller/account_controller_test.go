@@ -0,0 +1,214 @@
+package controller_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/fuki01/onion-architecture/domain/user"
+	"github.com/fuki01/onion-architecture/presentation/controller"
+	"github.com/fuki01/onion-architecture/usecase"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAccountUsecase struct {
+	mock.Mock
+}
+
+func (m *MockAccountUsecase) Register(input usecase.RegisterInput) (*user.User, error) {
+	args := m.Called(input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*user.User), args.Error(1)
+}
+
+func (m *MockAccountUsecase) Login(email, password string) (*usecase.Tokens, error) {
+	args := m.Called(email, password)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*usecase.Tokens), args.Error(1)
+}
+
+func (m *MockAccountUsecase) Refresh(refreshToken string) (*usecase.Tokens, error) {
+	args := m.Called(refreshToken)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*usecase.Tokens), args.Error(1)
+}
+
+func (m *MockAccountUsecase) Logout(refreshToken string) error {
+	args := m.Called(refreshToken)
+	return args.Error(0)
+}
+
+func (m *MockAccountUsecase) ChangePassword(actor user.UserId, currentPassword, newPassword string) error {
+	args := m.Called(actor, currentPassword, newPassword)
+	return args.Error(0)
+}
+
+func TestAccountController(t *testing.T) {
+	tokens := &usecase.Tokens{
+		AccessToken:           "access",
+		AccessTokenExpiresAt:  time.Date(2024, 1, 1, 9, 15, 0, 0, time.UTC),
+		RefreshToken:          "refresh",
+		RefreshTokenExpiresAt: time.Date(2024, 1, 31, 9, 0, 0, 0, time.UTC),
+	}
+
+	testCases := []struct {
+		name           string
+		mockSetup      func(m *MockAccountUsecase)
+		method         string
+		path           string
+		reqBody        string
+		anonymous      bool
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "Register Success",
+			mockSetup: func(m *MockAccountUsecase) {
+				m.On("Register", usecase.RegisterInput{Name: "太郎", Email: "taro@example.com", Password: "correct horse"}).
+					Return(&user.User{Id: 1, Name: "太郎", Email: "taro@example.com", PasswordHash: "hash"}, nil)
+			},
+			method:         "POST",
+			path:           "/auth/register",
+			reqBody:        `{"name":"太郎","email":"taro@example.com","password":"correct horse"}`,
+			anonymous:      true,
+			expectedStatus: http.StatusCreated,
+			expectedBody:   `{"id":1,"name":"太郎","email":"taro@example.com","created_at":"0001-01-01T00:00:00Z"}`,
+		},
+		{
+			name: "Register Email Taken",
+			mockSetup: func(m *MockAccountUsecase) {
+				m.On("Register", mock.Anything).Return(nil, user.ErrEmailTaken)
+			},
+			method:         "POST",
+			path:           "/auth/register",
+			reqBody:        `{"name":"太郎","email":"taro@example.com","password":"correct horse"}`,
+			anonymous:      true,
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:           "Register Invalid Email",
+			mockSetup:      func(m *MockAccountUsecase) {},
+			method:         "POST",
+			path:           "/auth/register",
+			reqBody:        `{"name":"太郎","email":"taro","password":"correct horse"}`,
+			anonymous:      true,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Login Success",
+			mockSetup: func(m *MockAccountUsecase) {
+				m.On("Login", "taro@example.com", "correct horse").Return(tokens, nil)
+			},
+			method:         "POST",
+			path:           "/auth/login",
+			reqBody:        `{"email":"taro@example.com","password":"correct horse"}`,
+			anonymous:      true,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"access_token":"access","token_type":"Bearer","expires_at":"2024-01-01T09:15:00Z","refresh_token":"refresh","refresh_token_expires_at":"2024-01-31T09:00:00Z"}`,
+		},
+		{
+			name: "Login Invalid Credentials",
+			mockSetup: func(m *MockAccountUsecase) {
+				m.On("Login", "taro@example.com", "wrong").Return(nil, user.ErrInvalidCredentials)
+			},
+			method:         "POST",
+			path:           "/auth/login",
+			reqBody:        `{"email":"taro@example.com","password":"wrong"}`,
+			anonymous:      true,
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name: "Login Locked",
+			mockSetup: func(m *MockAccountUsecase) {
+				m.On("Login", "taro@example.com", "correct horse").Return(nil, user.ErrAccountLocked)
+			},
+			method:         "POST",
+			path:           "/auth/login",
+			reqBody:        `{"email":"taro@example.com","password":"correct horse"}`,
+			anonymous:      true,
+			expectedStatus: http.StatusLocked,
+		},
+		{
+			name: "Refresh Reused",
+			mockSetup: func(m *MockAccountUsecase) {
+				m.On("Refresh", "old").Return(nil, user.ErrInvalidRefreshToken)
+			},
+			method:         "POST",
+			path:           "/auth/refresh",
+			reqBody:        `{"refresh_token":"old"}`,
+			anonymous:      true,
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name: "Logout Success",
+			mockSetup: func(m *MockAccountUsecase) {
+				m.On("Logout", "refresh").Return(nil)
+			},
+			method:         "POST",
+			path:           "/auth/logout",
+			reqBody:        `{"refresh_token":"refresh"}`,
+			anonymous:      true,
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name: "Change Password Success",
+			mockSetup: func(m *MockAccountUsecase) {
+				m.On("ChangePassword", user.UserId(1), "correct horse", "battery staple").Return(nil)
+			},
+			method:         "PUT",
+			path:           "/auth/password",
+			reqBody:        `{"current_password":"correct horse","new_password":"battery staple"}`,
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:           "Change Password Unauthenticated",
+			mockSetup:      func(m *MockAccountUsecase) {},
+			method:         "PUT",
+			path:           "/auth/password",
+			reqBody:        `{"current_password":"correct horse","new_password":"battery staple"}`,
+			anonymous:      true,
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockUsecase := new(MockAccountUsecase)
+			tc.mockSetup(mockUsecase)
+
+			controller := controller.NewAccountController(mockUsecase)
+
+			req, _ := http.NewRequest(tc.method, tc.path, bytes.NewBufferString(tc.reqBody))
+			req.Header.Set("Content-Type", "application/json")
+
+			w := httptest.NewRecorder()
+
+			r := gin.Default()
+			if !tc.anonymous {
+				r.Use(authenticated(user.UserId(1)))
+			}
+			r.POST("/auth/register", controller.Register)
+			r.POST("/auth/login", controller.Login)
+			r.POST("/auth/refresh", controller.Refresh)
+			r.POST("/auth/logout", controller.Logout)
+			r.PUT("/auth/password", controller.ChangePassword)
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			if tc.expectedBody != "" {
+				assert.JSONEq(t, tc.expectedBody, w.Body.String())
+			}
+			mockUsecase.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/fuki01/onion-architecture/presentation/request"
+	"github.com/fuki01/onion-architecture/presentation/response"
+	"github.com/fuki01/onion-architecture/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ApiKeyController struct {
+	apikeyusecase usecase.ApiKeyUsecase
+}
+
+func NewApiKeyController(apikeyusecase usecase.ApiKeyUsecase) *ApiKeyController {
+	return &ApiKeyController{
+		apikeyusecase: apikeyusecase,
+	}
+}
+
+// APIキーを作成する
+func (ac *ApiKeyController) CreateApiKey(c *gin.Context) {
+	actor, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var input request.CreateApiKeyRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	key, k, err := ac.apikeyusecase.CreateApiKey(actor, usecase.CreateApiKeyInput{
+		Name:      input.Name,
+		Scopes:    input.Scopes,
+		ExpiresAt: input.ExpiresAt,
+	})
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, response.NewCreateApiKeyResponse(key, k))
+}
+
+// APIキーの一覧を取得する
+func (ac *ApiKeyController) GetApiKeys(c *gin.Context) {
+	actor, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	keys, err := ac.apikeyusecase.GetApiKeys(actor)
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, keys)
+}
+
+// APIキーを失効させる
+func (ac *ApiKeyController) RevokeApiKey(c *gin.Context) {
+	actor, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := ac.apikeyusecase.RevokeApiKey(actor, id); err != nil {
+		errorResponse(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
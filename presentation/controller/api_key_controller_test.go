@@ -0,0 +1,159 @@
+package controller_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/fuki01/onion-architecture/domain/repository"
+	"github.com/fuki01/onion-architecture/domain/user"
+	"github.com/fuki01/onion-architecture/presentation/controller"
+	"github.com/fuki01/onion-architecture/usecase"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockApiKeyUsecase struct {
+	mock.Mock
+}
+
+func (m *MockApiKeyUsecase) CreateApiKey(actor user.UserId, input usecase.CreateApiKeyInput) (string, *user.ApiKey, error) {
+	args := m.Called(actor, input)
+	if args.Get(1) == nil {
+		return "", nil, args.Error(2)
+	}
+	return args.String(0), args.Get(1).(*user.ApiKey), args.Error(2)
+}
+
+func (m *MockApiKeyUsecase) GetApiKeys(actor user.UserId) ([]*user.ApiKey, error) {
+	args := m.Called(actor)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*user.ApiKey), args.Error(1)
+}
+
+func (m *MockApiKeyUsecase) RevokeApiKey(actor user.UserId, id int) error {
+	args := m.Called(actor, id)
+	return args.Error(0)
+}
+
+func (m *MockApiKeyUsecase) Authenticate(key string) (user.UserId, []user.Scope, error) {
+	args := m.Called(key)
+	if args.Get(1) == nil {
+		return args.Get(0).(user.UserId), nil, args.Error(2)
+	}
+	return args.Get(0).(user.UserId), args.Get(1).([]user.Scope), args.Error(2)
+}
+
+func TestApiKeyController(t *testing.T) {
+	createdAt := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	key := &user.ApiKey{
+		Id:        1,
+		UserId:    1,
+		Name:      "ci",
+		Prefix:    "tk_0a1b2c3d",
+		KeyHash:   "hash",
+		Scopes:    []user.Scope{user.ScopeTasksWrite},
+		CreatedAt: createdAt,
+	}
+
+	testCases := []struct {
+		name           string
+		mockSetup      func(m *MockApiKeyUsecase)
+		method         string
+		path           string
+		reqBody        string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "Create Success",
+			mockSetup: func(m *MockApiKeyUsecase) {
+				m.On("CreateApiKey", user.UserId(1), usecase.CreateApiKeyInput{Name: "ci", Scopes: []string{"tasks:write"}}).
+					Return("tk_0a1b2c3d_secret", key, nil)
+			},
+			method:         "POST",
+			path:           "/api_keys",
+			reqBody:        `{"name":"ci","scopes":["tasks:write"]}`,
+			expectedStatus: http.StatusCreated,
+			expectedBody:   `{"id":1,"user_id":1,"name":"ci","prefix":"tk_0a1b2c3d","scopes":["tasks:write"],"expires_at":null,"last_used_at":null,"revoked_at":null,"created_at":"2024-01-01T09:00:00Z","key":"tk_0a1b2c3d_secret"}`,
+		},
+		{
+			name:           "Create Unknown Scope",
+			mockSetup:      func(m *MockApiKeyUsecase) {},
+			method:         "POST",
+			path:           "/api_keys",
+			reqBody:        `{"name":"ci","scopes":["admin"]}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Create Expired",
+			mockSetup: func(m *MockApiKeyUsecase) {
+				m.On("CreateApiKey", user.UserId(1), mock.Anything).Return("", nil, user.ErrInvalidApiKeyTTL)
+			},
+			method:         "POST",
+			path:           "/api_keys",
+			reqBody:        `{"name":"ci","scopes":["tasks:read"],"expires_at":"2000-01-01T00:00:00Z"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "List Success",
+			mockSetup: func(m *MockApiKeyUsecase) {
+				m.On("GetApiKeys", user.UserId(1)).Return([]*user.ApiKey{key}, nil)
+			},
+			method:         "GET",
+			path:           "/api_keys",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `[{"id":1,"user_id":1,"name":"ci","prefix":"tk_0a1b2c3d","scopes":["tasks:write"],"expires_at":null,"last_used_at":null,"revoked_at":null,"created_at":"2024-01-01T09:00:00Z"}]`,
+		},
+		{
+			name: "Revoke Success",
+			mockSetup: func(m *MockApiKeyUsecase) {
+				m.On("RevokeApiKey", user.UserId(1), 1).Return(nil)
+			},
+			method:         "DELETE",
+			path:           "/api_keys/1",
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name: "Revoke Not Found",
+			mockSetup: func(m *MockApiKeyUsecase) {
+				m.On("RevokeApiKey", user.UserId(1), 2).Return(repository.ErrNotFound)
+			},
+			method:         "DELETE",
+			path:           "/api_keys/2",
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockUsecase := new(MockApiKeyUsecase)
+			tc.mockSetup(mockUsecase)
+
+			controller := controller.NewApiKeyController(mockUsecase)
+
+			req, _ := http.NewRequest(tc.method, tc.path, bytes.NewBufferString(tc.reqBody))
+			req.Header.Set("Content-Type", "application/json")
+
+			w := httptest.NewRecorder()
+
+			r := gin.Default()
+			r.Use(authenticated(user.UserId(1)))
+			r.POST("/api_keys", controller.CreateApiKey)
+			r.GET("/api_keys", controller.GetApiKeys)
+			r.DELETE("/api_keys/:id", controller.RevokeApiKey)
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			if tc.expectedBody != "" {
+				assert.JSONEq(t, tc.expectedBody, w.Body.String())
+			}
+			mockUsecase.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,20 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/fuki01/onion-architecture/domain/user"
+	"github.com/fuki01/onion-architecture/presentation/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// currentUser は認証されたユーザーを返す
+// 認証されていない場合は401を返す
+func currentUser(c *gin.Context) (user.UserId, bool) {
+	id, ok := middleware.UserIdFromContext(c.Request.Context())
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthenticated"})
+	}
+	return id, ok
+}
@@ -0,0 +1,69 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/fuki01/onion-architecture/domain/repository"
+	"github.com/fuki01/onion-architecture/domain/task"
+	"github.com/fuki01/onion-architecture/domain/user"
+	"github.com/fuki01/onion-architecture/domain/webhook"
+	"github.com/fuki01/onion-architecture/domain/workspace"
+
+	"github.com/gin-gonic/gin"
+)
+
+// errorResponse はエラーの種類に応じたステータスコードでエラーを返す
+func errorResponse(c *gin.Context, err error) {
+	var validationErr *task.ValidationError
+	var delayErr *task.DelayPolicyError
+	switch {
+	case errors.As(err, &validationErr):
+		c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Message, "field": validationErr.Field})
+	case errors.As(err, &delayErr):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": delayErr.Error(), "rule": delayErr.Rule, "limit": delayErr.Limit})
+	case errors.Is(err, task.ErrCannotRevert):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	case errors.Is(err, user.ErrInvalidCredentials),
+		errors.Is(err, user.ErrInvalidRefreshToken),
+		errors.Is(err, user.ErrInvalidApiKey):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	case errors.Is(err, user.ErrAccountLocked):
+		c.JSON(http.StatusLocked, gin.H{"error": err.Error()})
+	case errors.Is(err, task.ErrForbidden),
+		errors.Is(err, workspace.ErrForbidden),
+		errors.Is(err, user.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, repository.ErrNotFound),
+		errors.Is(err, workspace.ErrNotMember):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, task.ErrInvalidOrder),
+		errors.Is(err, task.ErrNestedSubtask),
+		errors.Is(err, task.ErrSelfDependency),
+		errors.Is(err, task.ErrAssigneeNotFound),
+		errors.Is(err, user.ErrInvalidLocale),
+		errors.Is(err, user.ErrInvalidEmail),
+		errors.Is(err, user.ErrInvalidWebhookURL),
+		errors.Is(err, user.ErrInvalidChannel),
+		errors.Is(err, user.ErrWeakPassword),
+		errors.Is(err, user.ErrInvalidScope),
+		errors.Is(err, user.ErrInvalidApiKeyTTL),
+		errors.Is(err, webhook.ErrInvalidURL),
+		errors.Is(err, webhook.ErrNonPublicURL),
+		errors.Is(err, webhook.ErrInvalidEventType),
+		errors.Is(err, workspace.ErrInvalidName),
+		errors.Is(err, workspace.ErrInvalidRole):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, task.ErrCompletedTask),
+		errors.Is(err, task.ErrAlreadyCompleted),
+		errors.Is(err, task.ErrIncompleteSubtasks),
+		errors.Is(err, task.ErrOpenBlockers),
+		errors.Is(err, task.ErrDependencyCycle),
+		errors.Is(err, user.ErrEmailTaken),
+		errors.Is(err, workspace.ErrAlreadyMember),
+		errors.Is(err, workspace.ErrLastOwner):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,41 @@
+package controller
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/fuki01/onion-architecture/presentation/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ReadinessChecker は依存先の状態をチェックごとに返す。成功したチェックはnilになる
+type ReadinessChecker interface {
+	Check(ctx context.Context) map[string]error
+}
+
+type HealthController struct {
+	readiness ReadinessChecker
+}
+
+func NewHealthController(readiness ReadinessChecker) *HealthController {
+	return &HealthController{
+		readiness: readiness,
+	}
+}
+
+// プロセスが応答できるかを返す
+// 依存先の障害で再起動されないよう、依存先は確認しない
+func (hc *HealthController) Live(c *gin.Context) {
+	c.JSON(http.StatusOK, response.HealthResponse{Status: response.HealthOK})
+}
+
+// リクエストを受け付けられるかを返す
+func (hc *HealthController) Ready(c *gin.Context) {
+	res := response.NewHealthResponse(hc.readiness.Check(c.Request.Context()))
+	if res.Status != response.HealthOK {
+		c.JSON(http.StatusServiceUnavailable, res)
+		return
+	}
+	c.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,68 @@
+package controller_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fuki01/onion-architecture/presentation/controller"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type stubReadiness map[string]error
+
+func (s stubReadiness) Check(ctx context.Context) map[string]error {
+	return s
+}
+
+func TestHealthController(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		results  stubReadiness
+		wantCode int
+		wantBody string
+	}{
+		{
+			name:     "live",
+			path:     "/healthz",
+			results:  stubReadiness{"database": errors.New("connection refused")},
+			wantCode: http.StatusOK,
+			wantBody: `{"status": "ok"}`,
+		},
+		{
+			name:     "ready",
+			path:     "/readyz",
+			results:  stubReadiness{"database": nil, "migration": nil},
+			wantCode: http.StatusOK,
+			wantBody: `{"status": "ok", "checks": {"database": {"status": "ok"}, "migration": {"status": "ok"}}}`,
+		},
+		{
+			name:     "not ready",
+			path:     "/readyz",
+			results:  stubReadiness{"database": errors.New("connection refused"), "migration": nil},
+			wantCode: http.StatusServiceUnavailable,
+			wantBody: `{"status": "fail", "checks": {"database": {"status": "fail", "error": "connection refused"}, "migration": {"status": "ok"}}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			controller := controller.NewHealthController(tt.results)
+
+			req, _ := http.NewRequest("GET", tt.path, nil)
+			w := httptest.NewRecorder()
+
+			r := gin.Default()
+			r.GET("/healthz", controller.Live)
+			r.GET("/readyz", controller.Ready)
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantCode, w.Code)
+			assert.JSONEq(t, tt.wantBody, w.Body.String())
+		})
+	}
+}
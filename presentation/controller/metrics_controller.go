@@ -0,0 +1,38 @@
+package controller
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/fuki01/onion-architecture/presentation/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DBStatsProvider はコネクションプールの状態を返す。*sql.DBが実装する
+type DBStatsProvider interface {
+	Stats() sql.DBStats
+}
+
+type MetricsController struct {
+	db         DBStatsProvider
+	exposition http.Handler
+}
+
+// expositionはPrometheusの形式でメトリクスを返すハンドラー
+func NewMetricsController(db DBStatsProvider, exposition http.Handler) *MetricsController {
+	return &MetricsController{
+		db:         db,
+		exposition: exposition,
+	}
+}
+
+// Prometheusの形式でメトリクスを取得する
+func (mc *MetricsController) Prometheus(c *gin.Context) {
+	mc.exposition.ServeHTTP(c.Writer, c.Request)
+}
+
+// データベースのコネクションプールの状態を取得する
+func (mc *MetricsController) DBStats(c *gin.Context) {
+	c.JSON(http.StatusOK, response.NewDBStatsResponse(mc.db.Stats()))
+}
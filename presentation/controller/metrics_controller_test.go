@@ -0,0 +1,50 @@
+package controller_test
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/fuki01/onion-architecture/presentation/controller"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type stubDBStats sql.DBStats
+
+func (s stubDBStats) Stats() sql.DBStats {
+	return sql.DBStats(s)
+}
+
+func TestMetricsControllerDBStats(t *testing.T) {
+	controller := controller.NewMetricsController(stubDBStats{
+		MaxOpenConnections: 25,
+		OpenConnections:    3,
+		InUse:              2,
+		Idle:               1,
+		WaitCount:          4,
+		WaitDuration:       1500 * time.Millisecond,
+	}, http.NotFoundHandler())
+
+	req, _ := http.NewRequest("GET", "/metrics/db", nil)
+	w := httptest.NewRecorder()
+
+	r := gin.Default()
+	r.GET("/metrics/db", controller.DBStats)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{
+		"max_open_connections": 25,
+		"open_connections": 3,
+		"in_use": 2,
+		"idle": 1,
+		"wait_count": 4,
+		"wait_duration_ms": 1500,
+		"max_idle_closed": 0,
+		"max_idle_time_closed": 0,
+		"max_lifetime_closed": 0
+	}`, w.Body.String())
+}
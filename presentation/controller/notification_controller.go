@@ -0,0 +1,86 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/fuki01/onion-architecture/domain/user"
+	"github.com/fuki01/onion-architecture/presentation/request"
+	"github.com/fuki01/onion-architecture/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+type NotificationController struct {
+	notificationusecase usecase.NotificationUsecase
+}
+
+func NewNotificationController(notificationusecase usecase.NotificationUsecase) *NotificationController {
+	return &NotificationController{
+		notificationusecase: notificationusecase,
+	}
+}
+
+// 通知設定を取得する
+// 他のユーザーの設定は取得できない
+func (nc *NotificationController) GetSettings(c *gin.Context) {
+	actor, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	userID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	settings, err := nc.notificationusecase.GetSettings(actor, user.UserId(userID))
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
+
+// 通知設定を更新する
+// 他のユーザーの設定は更新できない
+func (nc *NotificationController) UpdateSettings(c *gin.Context) {
+	actor, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	userID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var input request.UpdateNotificationSettingsRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	settings := &user.NotificationSettings{
+		UserId: user.UserId(userID),
+		Locale: input.Locale,
+	}
+	for _, p := range input.Preferences {
+		settings.Preferences = append(settings.Preferences, user.NotificationPreference{
+			UserId:  settings.UserId,
+			Channel: p.Channel,
+			Target:  p.Target,
+			Enabled: p.Enabled,
+		})
+	}
+
+	if err := nc.notificationusecase.UpdateSettings(actor, settings); err != nil {
+		errorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
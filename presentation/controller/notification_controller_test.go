@@ -0,0 +1,138 @@
+package controller_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fuki01/onion-architecture/domain/task"
+	"github.com/fuki01/onion-architecture/domain/user"
+	"github.com/fuki01/onion-architecture/presentation/controller"
+	"github.com/fuki01/onion-architecture/usecase"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockNotificationUsecase struct {
+	mock.Mock
+}
+
+func (m *MockNotificationUsecase) Notify(ctx context.Context, kind usecase.NotificationKind, tasks []*task.Task) error {
+	args := m.Called(kind, tasks)
+	return args.Error(0)
+}
+
+func (m *MockNotificationUsecase) GetSettings(actor user.UserId, userId user.UserId) (*user.NotificationSettings, error) {
+	args := m.Called(actor, userId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*user.NotificationSettings), args.Error(1)
+}
+
+func (m *MockNotificationUsecase) UpdateSettings(actor user.UserId, settings *user.NotificationSettings) error {
+	args := m.Called(actor, settings)
+	return args.Error(0)
+}
+
+func TestNotificationControllerSettings(t *testing.T) {
+	testCases := []struct {
+		name           string
+		mockSetup      func(m *MockNotificationUsecase)
+		method         string
+		path           string
+		reqBody        string
+		expectedStatus int
+	}{
+		{
+			name: "Get Success",
+			mockSetup: func(m *MockNotificationUsecase) {
+				m.On("GetSettings", user.UserId(1), user.UserId(1)).Return(user.NewNotificationSettings(1), nil)
+			},
+			method:         "GET",
+			path:           "/users/1/notification_settings",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "Update Success",
+			mockSetup: func(m *MockNotificationUsecase) {
+				m.On("UpdateSettings", user.UserId(1), &user.NotificationSettings{
+					UserId: 1,
+					Locale: user.LocaleEn,
+					Preferences: []user.NotificationPreference{
+						{UserId: 1, Channel: user.ChannelEmail, Target: "taro@example.com", Enabled: true},
+					},
+				}).Return(nil)
+			},
+			method:         "PUT",
+			path:           "/users/1/notification_settings",
+			reqBody:        `{"locale":"en","preferences":[{"channel":"email","target":"taro@example.com","enabled":true}]}`,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Update Unknown Channel",
+			mockSetup:      func(m *MockNotificationUsecase) {},
+			method:         "PUT",
+			path:           "/users/1/notification_settings",
+			reqBody:        `{"preferences":[{"channel":"sms","target":"090","enabled":true}]}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Update Invalid Target",
+			mockSetup: func(m *MockNotificationUsecase) {
+				m.On("UpdateSettings", user.UserId(1), mock.Anything).Return(user.ErrInvalidEmail)
+			},
+			method:         "PUT",
+			path:           "/users/1/notification_settings",
+			reqBody:        `{"preferences":[{"channel":"email","target":"taro","enabled":true}]}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Get Other User",
+			mockSetup: func(m *MockNotificationUsecase) {
+				m.On("GetSettings", user.UserId(1), user.UserId(2)).Return(nil, user.ErrForbidden)
+			},
+			method:         "GET",
+			path:           "/users/2/notification_settings",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name: "Update Other User",
+			mockSetup: func(m *MockNotificationUsecase) {
+				m.On("UpdateSettings", user.UserId(1), mock.MatchedBy(func(s *user.NotificationSettings) bool {
+					return s.UserId == 2
+				})).Return(user.ErrForbidden)
+			},
+			method:         "PUT",
+			path:           "/users/2/notification_settings",
+			reqBody:        `{"preferences":[{"channel":"email","target":"attacker@example.com","enabled":true}]}`,
+			expectedStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockUsecase := new(MockNotificationUsecase)
+			tc.mockSetup(mockUsecase)
+
+			controller := controller.NewNotificationController(mockUsecase)
+
+			req, _ := http.NewRequest(tc.method, tc.path, bytes.NewBufferString(tc.reqBody))
+			req.Header.Set("Content-Type", "application/json")
+
+			w := httptest.NewRecorder()
+
+			r := gin.Default()
+			r.Use(authenticated(user.UserId(1)))
+			r.GET("/users/:id/notification_settings", controller.GetSettings)
+			r.PUT("/users/:id/notification_settings", controller.UpdateSettings)
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			mockUsecase.AssertExpectations(t)
+		})
+	}
+}
@@ -4,8 +4,11 @@ import (
 	"net/http"
 	"strconv"
 
+	"github.com/fuki01/onion-architecture/domain/task"
 	"github.com/fuki01/onion-architecture/domain/user"
+	"github.com/fuki01/onion-architecture/domain/workspace"
 	"github.com/fuki01/onion-architecture/presentation/request"
+	"github.com/fuki01/onion-architecture/presentation/response"
 	"github.com/fuki01/onion-architecture/usecase"
 
 	"github.com/gin-gonic/gin"
@@ -23,6 +26,11 @@ func NewTaskController(taskusecase usecase.TaskUsecase) *TaskController {
 
 // タスクを登録する
 func (tc *TaskController) CreateTask(c *gin.Context) {
+	actor, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
 	var input request.CreateTaskRequest
 
 	if err := c.ShouldBindJSON(&input); err != nil {
@@ -30,10 +38,21 @@ func (tc *TaskController) CreateTask(c *gin.Context) {
 		return
 	}
 
-	taskID, err := tc.taskusecase.CreateTask(input.Name, input.UserId, input.DueDate)
+	// 作成者はリクエストボディではなくトークンから決める
+	taskID, err := tc.taskusecase.CreateTask(c.Request.Context(), usecase.CreateTaskInput{
+		Name:            input.Name,
+		CreatedBy:       actor,
+		DueDate:         input.DueDate,
+		Description:     input.Description,
+		Priority:        input.Priority,
+		EstimateMinutes: input.EstimateMinutes,
+		Tags:            input.Tags,
+		Recurrence:      input.Recurrence,
+		WorkspaceId:     input.WorkspaceID,
+	})
 
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		errorResponse(c, err)
 		return
 	}
 
@@ -42,6 +61,11 @@ func (tc *TaskController) CreateTask(c *gin.Context) {
 
 // タスクの期限を延長する
 func (tc *TaskController) ExtendDueDate(c *gin.Context) {
+	actor, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
 	var input request.ExtendDueDateRequest
 
 	if err := c.ShouldBindJSON(&input); err != nil {
@@ -49,9 +73,9 @@ func (tc *TaskController) ExtendDueDate(c *gin.Context) {
 		return
 	}
 
-	err := tc.taskusecase.ExtendDueDate(input.ID, input.DueDate)
+	err := tc.taskusecase.ExtendDueDate(c.Request.Context(), actor, input.ID, input.DueDate)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		errorResponse(c, err)
 		return
 	}
 
@@ -60,6 +84,11 @@ func (tc *TaskController) ExtendDueDate(c *gin.Context) {
 
 // タスクのステータスを変更する
 func (tc *TaskController) ChangeStatus(c *gin.Context) {
+	actor, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
 	var input request.ChangeStatusRequest
 
 	if err := c.ShouldBindJSON(&input); err != nil {
@@ -67,28 +96,404 @@ func (tc *TaskController) ChangeStatus(c *gin.Context) {
 		return
 	}
 
-	err := tc.taskusecase.ChangeStatus(input.ID, input.NewStatus)
+	err := tc.taskusecase.ChangeStatus(c.Request.Context(), actor, input.ID, input.NewStatus)
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "success"})
+}
+
+// タスクを部分更新する
+func (tc *TaskController) UpdateTask(c *gin.Context) {
+	actor, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	taskID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	body, err := c.GetRawData()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	patch, err := request.ParseTaskPatch(body)
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+
+	updated, err := tc.taskusecase.UpdateTask(c.Request.Context(), actor, task.TaskId(taskID), patch)
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.NewGetTaskResponse(updated))
+}
+
+// 子タスクを登録する
+func (tc *TaskController) AddSubtask(c *gin.Context) {
+	actor, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	parentID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var input request.CreateSubtaskRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	taskID, err := tc.taskusecase.AddSubtask(c.Request.Context(), actor, task.TaskId(parentID), usecase.CreateTaskInput{
+		Name:            input.Name,
+		DueDate:         input.DueDate,
+		Description:     input.Description,
+		Priority:        input.Priority,
+		EstimateMinutes: input.EstimateMinutes,
+		Tags:            input.Tags,
+	})
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"task_id": taskID})
+}
+
+// 子タスクを並べ替える
+func (tc *TaskController) ReorderSubtasks(c *gin.Context) {
+	actor, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	parentID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var input request.ReorderSubtasksRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := tc.taskusecase.ReorderSubtasks(c.Request.Context(), actor, task.TaskId(parentID), input.IDs); err != nil {
+		errorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "success"})
+}
+
+// 子タスク一覧を取得する
+func (tc *TaskController) GetSubtasks(c *gin.Context) {
+	actor, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	parentID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	subtasks, err := tc.taskusecase.GetSubtasks(c.Request.Context(), actor, task.TaskId(parentID))
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.NewGetTaskResponses(subtasks))
+}
+
+// タスク間の依存関係を登録する
+func (tc *TaskController) AddDependency(c *gin.Context) {
+	actor, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	taskID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var input request.AddDependencyRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := tc.taskusecase.AddDependency(c.Request.Context(), actor, task.TaskId(taskID), input.BlockedByID); err != nil {
+		errorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "success"})
+}
+
+// タスク間の依存関係を削除する
+func (tc *TaskController) RemoveDependency(c *gin.Context) {
+	actor, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	taskID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	blockedByID, err := strconv.ParseInt(c.Param("blocked_by_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := tc.taskusecase.RemoveDependency(c.Request.Context(), actor, task.TaskId(taskID), task.TaskId(blockedByID)); err != nil {
+		errorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "success"})
+}
+
+// タスクが依存するタスクを完了すべき順に取得する
+func (tc *TaskController) GetDependencyChain(c *gin.Context) {
+	actor, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	taskID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	chain, err := tc.taskusecase.GetDependencyChain(c.Request.Context(), actor, task.TaskId(taskID))
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.NewGetTaskResponses(chain))
+}
+
+// タスクを他のユーザーに共有する
+func (tc *TaskController) ShareTask(c *gin.Context) {
+	actor, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	taskID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var input request.ShareTaskRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := tc.taskusecase.ShareTask(c.Request.Context(), actor, task.TaskId(taskID), input.UserID); err != nil {
+		errorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "success"})
+}
+
+// タスクの共有を解除する
+func (tc *TaskController) UnshareTask(c *gin.Context) {
+	actor, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	taskID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, err := strconv.ParseInt(c.Param("user_id"), 10, 64)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := tc.taskusecase.UnshareTask(c.Request.Context(), actor, task.TaskId(taskID), user.UserId(userID)); err != nil {
+		errorResponse(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "success"})
 }
 
-// タスク一覧をユーザーIDで取得する
+// タスクの担当者を変更する
+func (tc *TaskController) ReassignTask(c *gin.Context) {
+	actor, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	taskID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var input request.ReassignTaskRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := tc.taskusecase.ReassignTask(c.Request.Context(), actor, task.TaskId(taskID), input.AssigneeID); err != nil {
+		errorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "success"})
+}
+
+// タスクの担当者の変更履歴を取得する
+func (tc *TaskController) GetAssignmentHistory(c *gin.Context) {
+	actor, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	taskID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	assignments, err := tc.taskusecase.GetAssignmentHistory(c.Request.Context(), actor, task.TaskId(taskID))
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, assignments)
+}
+
+// ワークスペースのタスク一覧を取得する
+func (tc *TaskController) GetWorkspaceTasks(c *gin.Context) {
+	actor, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	workspaceID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var query request.ListTasksQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tasks, err := tc.taskusecase.GetWorkspaceTasks(c.Request.Context(), actor, workspace.WorkspaceId(workspaceID), task.Filter{
+		Tag:      query.Tag,
+		Priority: query.Priority,
+		Overdue:  query.Overdue,
+	})
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.NewGetTaskResponses(tasks))
+}
+
+// 作成したタスク一覧をユーザーIDで取得する
 func (tc *TaskController) GetTask(c *gin.Context) {
+	actor, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
 	userID, err := strconv.ParseInt(c.Param("id"), 10, 64)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	task, err := tc.taskusecase.GetTasksByUserId(user.UserId(userID))
+	var query request.ListTasksQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tasks, err := tc.taskusecase.GetTasksByUserId(c.Request.Context(), actor, user.UserId(userID), task.Filter{
+		Tag:      query.Tag,
+		Priority: query.Priority,
+		Overdue:  query.Overdue,
+	})
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.NewGetTaskResponses(tasks))
+}
+
+// 担当するタスク一覧をユーザーIDで取得する
+func (tc *TaskController) GetAssignedTasks(c *gin.Context) {
+	actor, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	userID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var query request.ListTasksQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tasks, err := tc.taskusecase.GetAssignedTasks(c.Request.Context(), actor, user.UserId(userID), task.Filter{
+		Tag:      query.Tag,
+		Priority: query.Priority,
+		Overdue:  query.Overdue,
+	})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		errorResponse(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, task)
+	c.JSON(http.StatusOK, response.NewGetTaskResponses(tasks))
 }
@@ -1,44 +1,144 @@
 package controller_test
 
 import (
+	"context"
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
+	"github.com/fuki01/onion-architecture/domain/repository"
 	"github.com/fuki01/onion-architecture/domain/task"
 	"github.com/fuki01/onion-architecture/domain/user"
+	"github.com/fuki01/onion-architecture/domain/workspace"
 	"github.com/fuki01/onion-architecture/presentation/controller"
+	"github.com/fuki01/onion-architecture/presentation/middleware"
+	"github.com/fuki01/onion-architecture/usecase"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
 
+// authenticated は認証済みのリクエストとして扱うミドルウェア
+func authenticated(id user.UserId) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Request = c.Request.WithContext(middleware.WithUserId(c.Request.Context(), id))
+		c.Next()
+	}
+}
+
 type MockTaskUsecase struct {
 	mock.Mock
 }
 
-func (m *MockTaskUsecase) CreateTask(name string, userId user.UserId, dueDate string) (task.TaskId, error) {
-	args := m.Called(name, userId, dueDate)
+func (m *MockTaskUsecase) CreateTask(ctx context.Context, input usecase.CreateTaskInput) (task.TaskId, error) {
+	args := m.Called(input)
 	return args.Get(0).(task.TaskId), args.Error(1)
 }
 
-func (m *MockTaskUsecase) ExtendDueDate(id task.TaskId, dueDate string) error {
-	args := m.Called(id, dueDate)
+func (m *MockTaskUsecase) ExtendDueDate(ctx context.Context, actor user.UserId, id task.TaskId, dueDate string) error {
+	args := m.Called(actor, id, dueDate)
 	return args.Error(0)
 }
 
-func (m *MockTaskUsecase) ChangeStatus(id task.TaskId, newStatus task.TaskStatus) error {
-	args := m.Called(id, newStatus)
+func (m *MockTaskUsecase) ChangeStatus(ctx context.Context, actor user.UserId, id task.TaskId, newStatus task.TaskStatus) error {
+	args := m.Called(actor, id, newStatus)
 	return args.Error(0)
 }
 
-func (m *MockTaskUsecase) GetTasksByUserId(userId user.UserId) ([]*task.Task, error) {
-	args := m.Called(userId)
+func (m *MockTaskUsecase) UpdateTask(ctx context.Context, actor user.UserId, id task.TaskId, patch task.Patch) (*task.Task, error) {
+	args := m.Called(actor, id, patch)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*task.Task), args.Error(1)
+}
+
+func (m *MockTaskUsecase) AddSubtask(ctx context.Context, actor user.UserId, parentId task.TaskId, input usecase.CreateTaskInput) (task.TaskId, error) {
+	args := m.Called(actor, parentId, input)
+	return args.Get(0).(task.TaskId), args.Error(1)
+}
+
+func (m *MockTaskUsecase) ReorderSubtasks(ctx context.Context, actor user.UserId, parentId task.TaskId, ids []task.TaskId) error {
+	args := m.Called(actor, parentId, ids)
+	return args.Error(0)
+}
+
+func (m *MockTaskUsecase) GetSubtasks(ctx context.Context, actor user.UserId, parentId task.TaskId) ([]*task.Task, error) {
+	args := m.Called(actor, parentId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
 	return args.Get(0).([]*task.Task), args.Error(1)
 }
 
+func (m *MockTaskUsecase) AddDependency(ctx context.Context, actor user.UserId, id task.TaskId, blockedById task.TaskId) error {
+	args := m.Called(actor, id, blockedById)
+	return args.Error(0)
+}
+
+func (m *MockTaskUsecase) RemoveDependency(ctx context.Context, actor user.UserId, id task.TaskId, blockedById task.TaskId) error {
+	args := m.Called(actor, id, blockedById)
+	return args.Error(0)
+}
+
+func (m *MockTaskUsecase) GetDependencyChain(ctx context.Context, actor user.UserId, id task.TaskId) ([]*task.Task, error) {
+	args := m.Called(actor, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*task.Task), args.Error(1)
+}
+
+func (m *MockTaskUsecase) GetTasksByUserId(ctx context.Context, actor user.UserId, userId user.UserId, filter task.Filter) ([]*task.Task, error) {
+	args := m.Called(actor, userId, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*task.Task), args.Error(1)
+}
+
+func (m *MockTaskUsecase) GetWorkspaceTasks(ctx context.Context, actor user.UserId, workspaceId workspace.WorkspaceId, filter task.Filter) ([]*task.Task, error) {
+	args := m.Called(actor, workspaceId, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*task.Task), args.Error(1)
+}
+
+func (m *MockTaskUsecase) GetAssignedTasks(ctx context.Context, actor user.UserId, userId user.UserId, filter task.Filter) ([]*task.Task, error) {
+	args := m.Called(actor, userId, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*task.Task), args.Error(1)
+}
+
+func (m *MockTaskUsecase) ReassignTask(ctx context.Context, actor user.UserId, id task.TaskId, assigneeId *user.UserId) error {
+	args := m.Called(actor, id, assigneeId)
+	return args.Error(0)
+}
+
+func (m *MockTaskUsecase) GetAssignmentHistory(ctx context.Context, actor user.UserId, id task.TaskId) ([]task.Assignment, error) {
+	args := m.Called(actor, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]task.Assignment), args.Error(1)
+}
+
+func (m *MockTaskUsecase) ShareTask(ctx context.Context, actor user.UserId, id task.TaskId, userId user.UserId) error {
+	args := m.Called(actor, id, userId)
+	return args.Error(0)
+}
+
+func (m *MockTaskUsecase) UnshareTask(ctx context.Context, actor user.UserId, id task.TaskId, userId user.UserId) error {
+	args := m.Called(actor, id, userId)
+	return args.Error(0)
+}
+
 func TestNewTaskController(t *testing.T) {
 	mockUsecase := new(MockTaskUsecase)
 	tc := controller.NewTaskController(mockUsecase)
@@ -50,24 +150,62 @@ func TestTaskControllerCreateTask(t *testing.T) {
 		name           string
 		mockSetup      func(m *MockTaskUsecase)
 		reqBody        string
+		anonymous      bool
 		expectedStatus int
 	}{
 		{
 			name: "Success",
 			mockSetup: func(m *MockTaskUsecase) {
-				m.On("CreateTask", "タスク名", user.UserId(1), "2021-01-01").Return(task.TaskId(1), nil)
+				m.On("CreateTask", usecase.CreateTaskInput{Name: "タスク名", CreatedBy: user.UserId(1), DueDate: "2021-01-01"}).Return(task.TaskId(1), nil)
 			},
-			reqBody:        `{"name":"タスク名","user_id":1,"due_date":"2021-01-01"}`,
+			reqBody:        `{"name":"タスク名","due_date":"2021-01-01"}`,
 			expectedStatus: http.StatusCreated,
 		},
+		{
+			name: "Success With Details",
+			mockSetup: func(m *MockTaskUsecase) {
+				m.On("CreateTask", usecase.CreateTaskInput{
+					Name:            "タスク名",
+					CreatedBy:       user.UserId(1),
+					DueDate:         "2021-01-01",
+					Description:     "# 詳細",
+					Priority:        task.PriorityHigh,
+					EstimateMinutes: 30,
+					Tags:            []string{"work"},
+				}).Return(task.TaskId(1), nil)
+			},
+			reqBody:        `{"name":"タスク名","due_date":"2021-01-01","description":"# 詳細","priority":"high","estimate_minutes":30,"tags":["work"]}`,
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "Invalid Priority",
+			mockSetup:      func(m *MockTaskUsecase) {},
+			reqBody:        `{"name":"タスク名","due_date":"2021-01-01","priority":"critical"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
 		{
 			name: "Usecase Error",
 			mockSetup: func(m *MockTaskUsecase) {
-				m.On("CreateTask", "タスク名", user.UserId(1), "2021-01-01").Return(task.TaskId(0), fmt.Errorf("error"))
+				m.On("CreateTask", usecase.CreateTaskInput{Name: "タスク名", CreatedBy: user.UserId(1), DueDate: "2021-01-01"}).Return(task.TaskId(0), fmt.Errorf("error"))
 			},
-			reqBody:        `{"name":"タスク名","user_id":1,"due_date":"2021-01-01"}`,
+			reqBody:        `{"name":"タスク名","due_date":"2021-01-01"}`,
 			expectedStatus: http.StatusInternalServerError,
 		},
+		{
+			name: "Owner From Token",
+			mockSetup: func(m *MockTaskUsecase) {
+				m.On("CreateTask", usecase.CreateTaskInput{Name: "タスク名", CreatedBy: user.UserId(1), DueDate: "2021-01-01"}).Return(task.TaskId(1), nil)
+			},
+			reqBody:        `{"name":"タスク名","user_id":2,"due_date":"2021-01-01"}`,
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "Unauthenticated",
+			mockSetup:      func(m *MockTaskUsecase) {},
+			reqBody:        `{"name":"タスク名","due_date":"2021-01-01"}`,
+			anonymous:      true,
+			expectedStatus: http.StatusUnauthorized,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -83,6 +221,9 @@ func TestTaskControllerCreateTask(t *testing.T) {
 			w := httptest.NewRecorder()
 
 			r := gin.Default()
+			if !tc.anonymous {
+				r.Use(authenticated(user.UserId(1)))
+			}
 			r.POST("/tasks", controller.CreateTask)
 			r.ServeHTTP(w, req)
 
@@ -102,7 +243,7 @@ func TestTaskControllerExtendDueDate(t *testing.T) {
 		{
 			name: "Success",
 			mockSetup: func(m *MockTaskUsecase) {
-				m.On("ExtendDueDate", task.TaskId(1), "2021-01-01").Return(nil)
+				m.On("ExtendDueDate", user.UserId(1), task.TaskId(1), "2021-01-01").Return(nil)
 			},
 			reqBody:        `{"id":1,"due_date":"2021-01-01"}`,
 			expectedStatus: http.StatusOK,
@@ -110,11 +251,27 @@ func TestTaskControllerExtendDueDate(t *testing.T) {
 		{
 			name: "Usecase Error",
 			mockSetup: func(m *MockTaskUsecase) {
-				m.On("ExtendDueDate", task.TaskId(1), "2021-01-01").Return(fmt.Errorf("error"))
+				m.On("ExtendDueDate", user.UserId(1), task.TaskId(1), "2021-01-01").Return(fmt.Errorf("error"))
 			},
 			reqBody:        `{"id":1,"due_date":"2021-01-01"}`,
 			expectedStatus: http.StatusInternalServerError,
 		},
+		{
+			name: "Delay Policy Error",
+			mockSetup: func(m *MockTaskUsecase) {
+				m.On("ExtendDueDate", user.UserId(1), task.TaskId(1), "2021-01-01").Return(&task.DelayPolicyError{Rule: task.DelayRuleMaxExtensions, Limit: 3})
+			},
+			reqBody:        `{"id":1,"due_date":"2021-01-01"}`,
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name: "Forbidden",
+			mockSetup: func(m *MockTaskUsecase) {
+				m.On("ExtendDueDate", user.UserId(1), task.TaskId(2), "2021-01-01").Return(task.ErrForbidden)
+			},
+			reqBody:        `{"id":2,"due_date":"2021-01-01"}`,
+			expectedStatus: http.StatusForbidden,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -130,6 +287,7 @@ func TestTaskControllerExtendDueDate(t *testing.T) {
 			w := httptest.NewRecorder()
 
 			r := gin.Default()
+			r.Use(authenticated(user.UserId(1)))
 			r.PUT("/tasks/:id/extend_due_date",
 				controller.ExtendDueDate)
 			r.ServeHTTP(w, req)
@@ -151,7 +309,7 @@ func TestTaskControllerChangeStatus(t *testing.T) {
 		{
 			name: "Success",
 			mockSetup: func(m *MockTaskUsecase) {
-				m.On("ChangeStatus", task.TaskId(1), task.StatusComplete).Return(nil)
+				m.On("ChangeStatus", user.UserId(1), task.TaskId(1), task.StatusComplete).Return(nil)
 			},
 			reqBody:        `{"id":1,"new_status":"完了"}`,
 			expectedStatus: http.StatusOK,
@@ -159,11 +317,27 @@ func TestTaskControllerChangeStatus(t *testing.T) {
 		{
 			name: "Usecase Error",
 			mockSetup: func(m *MockTaskUsecase) {
-				m.On("ChangeStatus", task.TaskId(1), task.StatusComplete).Return(fmt.Errorf("error"))
+				m.On("ChangeStatus", user.UserId(1), task.TaskId(1), task.StatusComplete).Return(fmt.Errorf("error"))
 			},
 			reqBody:        `{"id":1,"new_status":"完了"}`,
 			expectedStatus: http.StatusInternalServerError,
 		},
+		{
+			name: "Already Completed",
+			mockSetup: func(m *MockTaskUsecase) {
+				m.On("ChangeStatus", user.UserId(1), task.TaskId(1), task.StatusComplete).Return(task.ErrAlreadyCompleted)
+			},
+			reqBody:        `{"id":1,"new_status":"完了"}`,
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name: "Cannot Revert",
+			mockSetup: func(m *MockTaskUsecase) {
+				m.On("ChangeStatus", user.UserId(1), task.TaskId(1), task.StatusIncomplete).Return(task.ErrCannotRevert)
+			},
+			reqBody:        `{"id":1,"new_status":"未完了"}`,
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -179,6 +353,7 @@ func TestTaskControllerChangeStatus(t *testing.T) {
 			w := httptest.NewRecorder()
 
 			r := gin.Default()
+			r.Use(authenticated(user.UserId(1)))
 			r.PUT("/tasks/:id/change_status", controller.ChangeStatus)
 			r.ServeHTTP(w, req)
 
@@ -195,15 +370,16 @@ func TestTaskControllerGetTasksByUserId(t *testing.T) {
 		mockSetup      func(m *MockTaskUsecase)
 		params         string
 		expectedStatus int
+		expectedFields map[string]any
 	}{
 		{
 			name: "Success",
 			mockSetup: func(m *MockTaskUsecase) {
-				m.On("GetTasksByUserId", user.UserId(1)).Return([]*task.Task{
+				m.On("GetTasksByUserId", user.UserId(1), user.UserId(1), task.Filter{}).Return([]*task.Task{
 					{
 						Id:         1,
 						Name:       "タスク名",
-						UserId:     1,
+						CreatedBy:  1,
 						DueDate:    "2021-01-01",
 						Status:     task.StatusIncomplete,
 						DelayCount: 0,
@@ -212,11 +388,19 @@ func TestTaskControllerGetTasksByUserId(t *testing.T) {
 			},
 			params:         "1",
 			expectedStatus: http.StatusOK,
+			expectedFields: map[string]any{
+				"id":          float64(1),
+				"name":        "タスク名",
+				"user_id":     float64(1),
+				"status":      "未完了",
+				"due_date":    "2021-01-01",
+				"delay_count": float64(0),
+			},
 		},
 		{
 			name: "Usecase Error",
 			mockSetup: func(m *MockTaskUsecase) {
-				m.On("GetTasksByUserId", user.UserId(1)).Return(nil, fmt.Errorf("error"))
+				m.On("GetTasksByUserId", user.UserId(1), user.UserId(1), task.Filter{}).Return(nil, fmt.Errorf("error"))
 			},
 			params:         "1",
 			expectedStatus: http.StatusInternalServerError,
@@ -227,6 +411,28 @@ func TestTaskControllerGetTasksByUserId(t *testing.T) {
 			params:         "abc",
 			expectedStatus: http.StatusBadRequest,
 		},
+		{
+			name: "Filter",
+			mockSetup: func(m *MockTaskUsecase) {
+				m.On("GetTasksByUserId", user.UserId(1), user.UserId(1), task.Filter{Tag: "work", Priority: task.PriorityHigh}).Return([]*task.Task{}, nil)
+			},
+			params:         "1?tag=work&priority=high",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "Overdue Filter",
+			mockSetup: func(m *MockTaskUsecase) {
+				m.On("GetTasksByUserId", user.UserId(1), user.UserId(1), task.Filter{Overdue: true}).Return([]*task.Task{}, nil)
+			},
+			params:         "1?overdue=true",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Invalid Filter",
+			mockSetup:      func(m *MockTaskUsecase) {},
+			params:         "1?priority=critical",
+			expectedStatus: http.StatusBadRequest,
+		},
 
 	}
 
@@ -241,6 +447,7 @@ func TestTaskControllerGetTasksByUserId(t *testing.T) {
 			w := httptest.NewRecorder()
 
 			r := gin.Default()
+			r.Use(authenticated(user.UserId(1)))
 
 			r.GET("/tasks/:id", controller.GetTask)
 			r.ServeHTTP(w, req)
@@ -248,6 +455,475 @@ func TestTaskControllerGetTasksByUserId(t *testing.T) {
 			fmt.Println("body", w.Body.String())
 			fmt.Println("status", w.Code)
 			fmt.Println("expected", tc.expectedStatus)
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			if tc.expectedFields != nil {
+				var body []map[string]any
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+				assert.Len(t, body, 1)
+				for key, value := range tc.expectedFields {
+					assert.Equal(t, value, body[0][key], key)
+				}
+			}
+			mockUsecase.AssertExpectations(t)
+		})
+	}
+}
+
+func TestTaskControllerUpdateTask(t *testing.T) {
+	name := "新しいタスク名"
+
+	testCases := []struct {
+		name           string
+		mockSetup      func(m *MockTaskUsecase)
+		params         string
+		reqBody        string
+		expectedStatus int
+	}{
+		{
+			name: "Success",
+			mockSetup: func(m *MockTaskUsecase) {
+				m.On("UpdateTask", user.UserId(1), task.TaskId(1), task.Patch{Name: &name}).Return(&task.Task{Id: 1, Name: name}, nil)
+			},
+			params:         "1",
+			reqBody:        `{"name":"新しいタスク名"}`,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Non Editable Field",
+			mockSetup:      func(m *MockTaskUsecase) {},
+			params:         "1",
+			reqBody:        `{"status":"完了"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Validation Error",
+			mockSetup: func(m *MockTaskUsecase) {
+				m.On("UpdateTask", user.UserId(1), task.TaskId(1), mock.Anything).Return(nil, &task.ValidationError{Field: "name", Message: "invalid task name"})
+			},
+			params:         "1",
+			reqBody:        `{"name":null}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Completed Task",
+			mockSetup: func(m *MockTaskUsecase) {
+				m.On("UpdateTask", user.UserId(1), task.TaskId(1), task.Patch{Name: &name}).Return(nil, task.ErrCompletedTask)
+			},
+			params:         "1",
+			reqBody:        `{"name":"新しいタスク名"}`,
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name: "Not Found",
+			mockSetup: func(m *MockTaskUsecase) {
+				m.On("UpdateTask", user.UserId(1), task.TaskId(1), task.Patch{Name: &name}).Return(nil, repository.ErrNotFound)
+			},
+			params:         "1",
+			reqBody:        `{"name":"新しいタスク名"}`,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "Params Error",
+			mockSetup:      func(m *MockTaskUsecase) {},
+			params:         "abc",
+			reqBody:        `{"name":"新しいタスク名"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockUsecase := new(MockTaskUsecase)
+			tc.mockSetup(mockUsecase)
+
+			controller := controller.NewTaskController(mockUsecase)
+
+			req, _ := http.NewRequest("PATCH", "/tasks/"+tc.params, bytes.NewBufferString(tc.reqBody))
+			req.Header.Set("Content-Type", "application/merge-patch+json")
+
+			w := httptest.NewRecorder()
+
+			r := gin.Default()
+			r.Use(authenticated(user.UserId(1)))
+			r.PATCH("/tasks/:id", controller.UpdateTask)
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			mockUsecase.AssertExpectations(t)
+		})
+	}
+}
+
+func TestTaskControllerSubtasks(t *testing.T) {
+	testCases := []struct {
+		name           string
+		mockSetup      func(m *MockTaskUsecase)
+		method         string
+		path           string
+		reqBody        string
+		expectedStatus int
+	}{
+		{
+			name: "Add Success",
+			mockSetup: func(m *MockTaskUsecase) {
+				m.On("AddSubtask", user.UserId(1), task.TaskId(1), usecase.CreateTaskInput{Name: "子タスク", DueDate: "2021-01-01"}).Return(task.TaskId(2), nil)
+			},
+			method:         "POST",
+			path:           "/tasks/1/subtasks",
+			reqBody:        `{"name":"子タスク","due_date":"2021-01-01"}`,
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name: "Add Nested",
+			mockSetup: func(m *MockTaskUsecase) {
+				m.On("AddSubtask", user.UserId(1), task.TaskId(2), usecase.CreateTaskInput{Name: "子タスク", DueDate: "2021-01-01"}).Return(task.TaskId(0), task.ErrNestedSubtask)
+			},
+			method:         "POST",
+			path:           "/tasks/2/subtasks",
+			reqBody:        `{"name":"子タスク","due_date":"2021-01-01"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Reorder Success",
+			mockSetup: func(m *MockTaskUsecase) {
+				m.On("ReorderSubtasks", user.UserId(1), task.TaskId(1), []task.TaskId{3, 2}).Return(nil)
+			},
+			method:         "PUT",
+			path:           "/tasks/1/subtasks/order",
+			reqBody:        `{"ids":[3,2]}`,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "Reorder Invalid",
+			mockSetup: func(m *MockTaskUsecase) {
+				m.On("ReorderSubtasks", user.UserId(1), task.TaskId(1), []task.TaskId{3}).Return(task.ErrInvalidOrder)
+			},
+			method:         "PUT",
+			path:           "/tasks/1/subtasks/order",
+			reqBody:        `{"ids":[3]}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "List Success",
+			mockSetup: func(m *MockTaskUsecase) {
+				m.On("GetSubtasks", user.UserId(1), task.TaskId(1)).Return([]*task.Task{{Id: 2, Name: "子タスク"}}, nil)
+			},
+			method:         "GET",
+			path:           "/tasks/1/subtasks",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "List Not Found",
+			mockSetup: func(m *MockTaskUsecase) {
+				m.On("GetSubtasks", user.UserId(1), task.TaskId(1)).Return(nil, repository.ErrNotFound)
+			},
+			method:         "GET",
+			path:           "/tasks/1/subtasks",
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockUsecase := new(MockTaskUsecase)
+			tc.mockSetup(mockUsecase)
+
+			controller := controller.NewTaskController(mockUsecase)
+
+			req, _ := http.NewRequest(tc.method, tc.path, bytes.NewBufferString(tc.reqBody))
+			req.Header.Set("Content-Type", "application/json")
+
+			w := httptest.NewRecorder()
+
+			r := gin.Default()
+			r.Use(authenticated(user.UserId(1)))
+			r.POST("/tasks/:id/subtasks", controller.AddSubtask)
+			r.GET("/tasks/:id/subtasks", controller.GetSubtasks)
+			r.PUT("/tasks/:id/subtasks/order", controller.ReorderSubtasks)
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			mockUsecase.AssertExpectations(t)
+		})
+	}
+}
+
+func TestTaskControllerDependencies(t *testing.T) {
+	testCases := []struct {
+		name           string
+		mockSetup      func(m *MockTaskUsecase)
+		method         string
+		path           string
+		reqBody        string
+		expectedStatus int
+	}{
+		{
+			name: "Add Success",
+			mockSetup: func(m *MockTaskUsecase) {
+				m.On("AddDependency", user.UserId(1), task.TaskId(2), task.TaskId(1)).Return(nil)
+			},
+			method:         "POST",
+			path:           "/tasks/2/dependencies",
+			reqBody:        `{"blocked_by_id":1}`,
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name: "Add Cycle",
+			mockSetup: func(m *MockTaskUsecase) {
+				m.On("AddDependency", user.UserId(1), task.TaskId(1), task.TaskId(2)).Return(task.ErrDependencyCycle)
+			},
+			method:         "POST",
+			path:           "/tasks/1/dependencies",
+			reqBody:        `{"blocked_by_id":2}`,
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name: "Remove Success",
+			mockSetup: func(m *MockTaskUsecase) {
+				m.On("RemoveDependency", user.UserId(1), task.TaskId(2), task.TaskId(1)).Return(nil)
+			},
+			method:         "DELETE",
+			path:           "/tasks/2/dependencies/1",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "Remove Not Found",
+			mockSetup: func(m *MockTaskUsecase) {
+				m.On("RemoveDependency", user.UserId(1), task.TaskId(2), task.TaskId(3)).Return(repository.ErrNotFound)
+			},
+			method:         "DELETE",
+			path:           "/tasks/2/dependencies/3",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name: "Chain Success",
+			mockSetup: func(m *MockTaskUsecase) {
+				m.On("GetDependencyChain", user.UserId(1), task.TaskId(2)).Return([]*task.Task{{Id: 1, Name: "先行タスク"}}, nil)
+			},
+			method:         "GET",
+			path:           "/tasks/2/dependencies",
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockUsecase := new(MockTaskUsecase)
+			tc.mockSetup(mockUsecase)
+
+			controller := controller.NewTaskController(mockUsecase)
+
+			req, _ := http.NewRequest(tc.method, tc.path, bytes.NewBufferString(tc.reqBody))
+			req.Header.Set("Content-Type", "application/json")
+
+			w := httptest.NewRecorder()
+
+			r := gin.Default()
+			r.Use(authenticated(user.UserId(1)))
+			r.POST("/tasks/:id/dependencies", controller.AddDependency)
+			r.GET("/tasks/:id/dependencies", controller.GetDependencyChain)
+			r.DELETE("/tasks/:id/dependencies/:blocked_by_id", controller.RemoveDependency)
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			mockUsecase.AssertExpectations(t)
+		})
+	}
+}
+
+func TestTaskControllerShares(t *testing.T) {
+	testCases := []struct {
+		name           string
+		mockSetup      func(m *MockTaskUsecase)
+		method         string
+		path           string
+		reqBody        string
+		expectedStatus int
+	}{
+		{
+			name: "Share Success",
+			mockSetup: func(m *MockTaskUsecase) {
+				m.On("ShareTask", user.UserId(1), task.TaskId(1), user.UserId(2)).Return(nil)
+			},
+			method:         "POST",
+			path:           "/tasks/1/shares",
+			reqBody:        `{"user_id":2}`,
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "Share Missing User",
+			mockSetup:      func(m *MockTaskUsecase) {},
+			method:         "POST",
+			path:           "/tasks/1/shares",
+			reqBody:        `{}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Share Not Owner",
+			mockSetup: func(m *MockTaskUsecase) {
+				m.On("ShareTask", user.UserId(1), task.TaskId(3), user.UserId(2)).Return(task.ErrForbidden)
+			},
+			method:         "POST",
+			path:           "/tasks/3/shares",
+			reqBody:        `{"user_id":2}`,
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name: "Unshare Success",
+			mockSetup: func(m *MockTaskUsecase) {
+				m.On("UnshareTask", user.UserId(1), task.TaskId(1), user.UserId(2)).Return(nil)
+			},
+			method:         "DELETE",
+			path:           "/tasks/1/shares/2",
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockUsecase := new(MockTaskUsecase)
+			tc.mockSetup(mockUsecase)
+
+			controller := controller.NewTaskController(mockUsecase)
+
+			req, _ := http.NewRequest(tc.method, tc.path, bytes.NewBufferString(tc.reqBody))
+			req.Header.Set("Content-Type", "application/json")
+
+			w := httptest.NewRecorder()
+
+			r := gin.Default()
+			r.Use(authenticated(user.UserId(1)))
+			r.POST("/tasks/:id/shares", controller.ShareTask)
+			r.DELETE("/tasks/:id/shares/:user_id", controller.UnshareTask)
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			mockUsecase.AssertExpectations(t)
+		})
+	}
+}
+
+func TestTaskControllerAssignment(t *testing.T) {
+	assignee := user.UserId(2)
+
+	testCases := []struct {
+		name           string
+		mockSetup      func(m *MockTaskUsecase)
+		method         string
+		path           string
+		reqBody        string
+		expectedStatus int
+	}{
+		{
+			name: "Assign Success",
+			mockSetup: func(m *MockTaskUsecase) {
+				m.On("ReassignTask", user.UserId(1), task.TaskId(1), &assignee).Return(nil)
+			},
+			method:         "PUT",
+			path:           "/tasks/1/assignee",
+			reqBody:        `{"assignee_id":2}`,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "Unassign Success",
+			mockSetup: func(m *MockTaskUsecase) {
+				m.On("ReassignTask", user.UserId(1), task.TaskId(1), (*user.UserId)(nil)).Return(nil)
+			},
+			method:         "PUT",
+			path:           "/tasks/1/assignee",
+			reqBody:        `{"assignee_id":null}`,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "Assign Forbidden",
+			mockSetup: func(m *MockTaskUsecase) {
+				m.On("ReassignTask", user.UserId(1), task.TaskId(3), &assignee).Return(task.ErrForbidden)
+			},
+			method:         "PUT",
+			path:           "/tasks/3/assignee",
+			reqBody:        `{"assignee_id":2}`,
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name: "Assignment History Success",
+			mockSetup: func(m *MockTaskUsecase) {
+				m.On("GetAssignmentHistory", user.UserId(1), task.TaskId(1)).
+					Return([]task.Assignment{{Id: 1, TaskId: 1, ToUserId: &assignee, ChangedBy: 1}}, nil)
+			},
+			method:         "GET",
+			path:           "/tasks/1/assignments",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "Assignee Not Found",
+			mockSetup: func(m *MockTaskUsecase) {
+				m.On("ReassignTask", user.UserId(1), task.TaskId(1), &assignee).Return(task.ErrAssigneeNotFound)
+			},
+			method:         "PUT",
+			path:           "/tasks/1/assignee",
+			reqBody:        `{"assignee_id":2}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Assigned Tasks Success",
+			mockSetup: func(m *MockTaskUsecase) {
+				m.On("GetAssignedTasks", user.UserId(1), user.UserId(1), task.Filter{}).
+					Return([]*task.Task{task.NewTask("test", user.UserId(2), "2024-01-01")}, nil)
+			},
+			method:         "GET",
+			path:           "/users/1/tasks/assigned",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "Assigned Tasks Forbidden",
+			mockSetup: func(m *MockTaskUsecase) {
+				m.On("GetAssignedTasks", user.UserId(1), user.UserId(2), task.Filter{}).Return(nil, task.ErrForbidden)
+			},
+			method:         "GET",
+			path:           "/users/2/tasks/assigned",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name: "Workspace Tasks Success",
+			mockSetup: func(m *MockTaskUsecase) {
+				m.On("GetWorkspaceTasks", user.UserId(1), workspace.WorkspaceId(1), task.Filter{Priority: task.PriorityHigh}).
+					Return([]*task.Task{task.NewTask("test", user.UserId(2), "2024-01-01")}, nil)
+			},
+			method:         "GET",
+			path:           "/workspaces/1/tasks?priority=high",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "Workspace Tasks Forbidden",
+			mockSetup: func(m *MockTaskUsecase) {
+				m.On("GetWorkspaceTasks", user.UserId(1), workspace.WorkspaceId(2), task.Filter{}).Return(nil, task.ErrForbidden)
+			},
+			method:         "GET",
+			path:           "/workspaces/2/tasks",
+			expectedStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockUsecase := new(MockTaskUsecase)
+			tc.mockSetup(mockUsecase)
+
+			controller := controller.NewTaskController(mockUsecase)
+
+			req, _ := http.NewRequest(tc.method, tc.path, bytes.NewBufferString(tc.reqBody))
+			req.Header.Set("Content-Type", "application/json")
+
+			w := httptest.NewRecorder()
+
+			r := gin.Default()
+			r.Use(authenticated(user.UserId(1)))
+			r.PUT("/tasks/:id/assignee", controller.ReassignTask)
+			r.GET("/tasks/:id/assignments", controller.GetAssignmentHistory)
+			r.GET("/users/:id/tasks/assigned", controller.GetAssignedTasks)
+			r.GET("/workspaces/:id/tasks", controller.GetWorkspaceTasks)
+			r.ServeHTTP(w, req)
+
 			assert.Equal(t, tc.expectedStatus, w.Code)
 			mockUsecase.AssertExpectations(t)
 		})
@@ -0,0 +1,131 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/fuki01/onion-architecture/domain/webhook"
+	"github.com/fuki01/onion-architecture/presentation/request"
+	"github.com/fuki01/onion-architecture/presentation/response"
+	"github.com/fuki01/onion-architecture/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WebhookController struct {
+	webhookusecase usecase.WebhookUsecase
+}
+
+func NewWebhookController(webhookusecase usecase.WebhookUsecase) *WebhookController {
+	return &WebhookController{
+		webhookusecase: webhookusecase,
+	}
+}
+
+// 送信先を登録する
+func (wc *WebhookController) CreateWebhook(c *gin.Context) {
+	actor, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var input request.CreateWebhookRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	sub, err := wc.webhookusecase.CreateSubscription(actor, input.URL, input.Secret, input.EventTypes)
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, response.NewCreateWebhookResponse(sub))
+}
+
+// 送信先一覧を取得する
+func (wc *WebhookController) GetWebhooks(c *gin.Context) {
+	actor, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	subs, err := wc.webhookusecase.GetSubscriptions(actor)
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, subs)
+}
+
+// 送信先を削除する
+func (wc *WebhookController) DeleteWebhook(c *gin.Context) {
+	actor, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := wc.webhookusecase.DeleteSubscription(actor, webhook.SubscriptionId(id)); err != nil {
+		errorResponse(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// 送信先の配送記録を取得する
+func (wc *WebhookController) GetDeliveries(c *gin.Context) {
+	actor, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	deliveries, err := wc.webhookusecase.GetDeliveries(actor, webhook.SubscriptionId(id))
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, deliveries)
+}
+
+// 配送記録を再送する
+func (wc *WebhookController) Redeliver(c *gin.Context) {
+	actor, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	deliveryID, err := strconv.Atoi(c.Param("delivery_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	d, err := wc.webhookusecase.Redeliver(actor, webhook.SubscriptionId(id), webhook.DeliveryId(deliveryID))
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, d)
+}
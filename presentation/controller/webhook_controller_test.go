@@ -0,0 +1,196 @@
+package controller_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fuki01/onion-architecture/domain/repository"
+	"github.com/fuki01/onion-architecture/domain/user"
+	"github.com/fuki01/onion-architecture/domain/webhook"
+	"github.com/fuki01/onion-architecture/presentation/controller"
+	"github.com/fuki01/onion-architecture/usecase"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockWebhookUsecase struct {
+	mock.Mock
+}
+
+func (m *MockWebhookUsecase) Publish(event webhook.Event, audience usecase.Audience) error {
+	args := m.Called(event, audience)
+	return args.Error(0)
+}
+
+func (m *MockWebhookUsecase) CreateSubscription(actor user.UserId, url, secret string, eventTypes []webhook.EventType) (*webhook.Subscription, error) {
+	args := m.Called(actor, url, secret, eventTypes)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*webhook.Subscription), args.Error(1)
+}
+
+func (m *MockWebhookUsecase) GetSubscriptions(actor user.UserId) ([]*webhook.Subscription, error) {
+	args := m.Called(actor)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*webhook.Subscription), args.Error(1)
+}
+
+func (m *MockWebhookUsecase) DeleteSubscription(actor user.UserId, id webhook.SubscriptionId) error {
+	args := m.Called(actor, id)
+	return args.Error(0)
+}
+
+func (m *MockWebhookUsecase) GetDeliveries(actor user.UserId, id webhook.SubscriptionId) ([]*webhook.Delivery, error) {
+	args := m.Called(actor, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*webhook.Delivery), args.Error(1)
+}
+
+func (m *MockWebhookUsecase) Redeliver(actor user.UserId, id webhook.SubscriptionId, deliveryId webhook.DeliveryId) (*webhook.Delivery, error) {
+	args := m.Called(actor, id, deliveryId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*webhook.Delivery), args.Error(1)
+}
+
+func (m *MockWebhookUsecase) Shutdown(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
+func TestWebhookController(t *testing.T) {
+	sub := &webhook.Subscription{Id: 1, URL: "https://example.com/hook", Secret: "s3cret", EventTypes: []webhook.EventType{webhook.EventTaskCreated}}
+
+	testCases := []struct {
+		name           string
+		mockSetup      func(m *MockWebhookUsecase)
+		method         string
+		path           string
+		reqBody        string
+		expectedStatus int
+		expectedSecret bool
+	}{
+		{
+			name: "Create Success",
+			mockSetup: func(m *MockWebhookUsecase) {
+				m.On("CreateSubscription", user.UserId(1), "https://example.com/hook", "", []webhook.EventType{webhook.EventTaskCreated}).Return(sub, nil)
+			},
+			method:         "POST",
+			path:           "/webhooks",
+			reqBody:        `{"url":"https://example.com/hook","event_types":["task.created"]}`,
+			expectedStatus: http.StatusCreated,
+			expectedSecret: true,
+		},
+		{
+			name:           "Create Unknown Event",
+			mockSetup:      func(m *MockWebhookUsecase) {},
+			method:         "POST",
+			path:           "/webhooks",
+			reqBody:        `{"url":"https://example.com/hook","event_types":["task.deleted"]}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Create Invalid URL",
+			mockSetup: func(m *MockWebhookUsecase) {
+				m.On("CreateSubscription", user.UserId(1), "ftp://example.com/hook", "", []webhook.EventType{webhook.EventTaskCreated}).Return(nil, webhook.ErrInvalidURL)
+			},
+			method:         "POST",
+			path:           "/webhooks",
+			reqBody:        `{"url":"ftp://example.com/hook","event_types":["task.created"]}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "List Hides Secret",
+			mockSetup: func(m *MockWebhookUsecase) {
+				m.On("GetSubscriptions", user.UserId(1)).Return([]*webhook.Subscription{sub}, nil)
+			},
+			method:         "GET",
+			path:           "/webhooks",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "Delete Not Found",
+			mockSetup: func(m *MockWebhookUsecase) {
+				m.On("DeleteSubscription", user.UserId(1), webhook.SubscriptionId(2)).Return(repository.ErrNotFound)
+			},
+			method:         "DELETE",
+			path:           "/webhooks/2",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name: "Deliveries Success",
+			mockSetup: func(m *MockWebhookUsecase) {
+				m.On("GetDeliveries", user.UserId(1), webhook.SubscriptionId(1)).Return([]*webhook.Delivery{{Id: 10, SubscriptionId: 1}}, nil)
+			},
+			method:         "GET",
+			path:           "/webhooks/1/deliveries",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "Redeliver Success",
+			mockSetup: func(m *MockWebhookUsecase) {
+				m.On("Redeliver", user.UserId(1), webhook.SubscriptionId(1), webhook.DeliveryId(10)).Return(&webhook.Delivery{Id: 11, SubscriptionId: 1}, nil)
+			},
+			method:         "POST",
+			path:           "/webhooks/1/deliveries/10/redeliver",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Redeliver Invalid ID",
+			mockSetup:      func(m *MockWebhookUsecase) {},
+			method:         "POST",
+			path:           "/webhooks/1/deliveries/x/redeliver",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockUsecase := new(MockWebhookUsecase)
+			tc.mockSetup(mockUsecase)
+
+			controller := controller.NewWebhookController(mockUsecase)
+
+			req, _ := http.NewRequest(tc.method, tc.path, bytes.NewBufferString(tc.reqBody))
+			req.Header.Set("Content-Type", "application/json")
+
+			w := httptest.NewRecorder()
+
+			r := gin.Default()
+			r.Use(authenticated(user.UserId(1)))
+			r.POST("/webhooks", controller.CreateWebhook)
+			r.GET("/webhooks", controller.GetWebhooks)
+			r.DELETE("/webhooks/:id", controller.DeleteWebhook)
+			r.GET("/webhooks/:id/deliveries", controller.GetDeliveries)
+			r.POST("/webhooks/:id/deliveries/:delivery_id/redeliver", controller.Redeliver)
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			mockUsecase.AssertExpectations(t)
+
+			if w.Code < 300 && tc.method != "DELETE" {
+				var body interface{}
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+				if obj, ok := body.(map[string]interface{}); ok {
+					_, hasSecret := obj["secret"]
+					assert.Equal(t, tc.expectedSecret, hasSecret)
+				}
+				if list, ok := body.([]interface{}); ok && tc.path == "/webhooks" {
+					_, hasSecret := list[0].(map[string]interface{})["secret"]
+					assert.False(t, hasSecret)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/fuki01/onion-architecture/domain/user"
+	"github.com/fuki01/onion-architecture/domain/workspace"
+	"github.com/fuki01/onion-architecture/presentation/request"
+	"github.com/fuki01/onion-architecture/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WorkspaceController struct {
+	workspaceusecase usecase.WorkspaceUsecase
+}
+
+func NewWorkspaceController(workspaceusecase usecase.WorkspaceUsecase) *WorkspaceController {
+	return &WorkspaceController{
+		workspaceusecase: workspaceusecase,
+	}
+}
+
+// ワークスペースを作成する
+func (wc *WorkspaceController) CreateWorkspace(c *gin.Context) {
+	actor, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var input request.CreateWorkspaceRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ws, err := wc.workspaceusecase.CreateWorkspace(actor, input.Name)
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, ws)
+}
+
+// 参加しているワークスペースの一覧を取得する
+func (wc *WorkspaceController) GetWorkspaces(c *gin.Context) {
+	actor, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	workspaces, err := wc.workspaceusecase.GetWorkspaces(actor)
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, workspaces)
+}
+
+// ワークスペースを取得する
+func (wc *WorkspaceController) GetWorkspace(c *gin.Context) {
+	actor, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ws, err := wc.workspaceusecase.GetWorkspace(actor, workspace.WorkspaceId(id))
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, ws)
+}
+
+// メンバーを追加する
+func (wc *WorkspaceController) AddMember(c *gin.Context) {
+	actor, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var input request.AddMemberRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	m, err := wc.workspaceusecase.AddMember(actor, workspace.WorkspaceId(id), input.UserID, input.Role)
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, m)
+}
+
+// メンバーの役割を変更する
+func (wc *WorkspaceController) ChangeRole(c *gin.Context) {
+	actor, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, err := strconv.ParseInt(c.Param("user_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var input request.ChangeRoleRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	m, err := wc.workspaceusecase.ChangeRole(actor, workspace.WorkspaceId(id), user.UserId(userID), input.Role)
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, m)
+}
+
+// メンバーを削除する
+func (wc *WorkspaceController) RemoveMember(c *gin.Context) {
+	actor, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, err := strconv.ParseInt(c.Param("user_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := wc.workspaceusecase.RemoveMember(actor, workspace.WorkspaceId(id), user.UserId(userID)); err != nil {
+		errorResponse(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
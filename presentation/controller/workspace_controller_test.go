@@ -0,0 +1,198 @@
+package controller_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/fuki01/onion-architecture/domain/user"
+	"github.com/fuki01/onion-architecture/domain/workspace"
+	"github.com/fuki01/onion-architecture/presentation/controller"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockWorkspaceUsecase struct {
+	mock.Mock
+}
+
+func (m *MockWorkspaceUsecase) CreateWorkspace(actor user.UserId, name string) (*workspace.Workspace, error) {
+	args := m.Called(actor, name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*workspace.Workspace), args.Error(1)
+}
+
+func (m *MockWorkspaceUsecase) GetWorkspaces(actor user.UserId) ([]*workspace.Workspace, error) {
+	args := m.Called(actor)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*workspace.Workspace), args.Error(1)
+}
+
+func (m *MockWorkspaceUsecase) GetWorkspace(actor user.UserId, id workspace.WorkspaceId) (*workspace.Workspace, error) {
+	args := m.Called(actor, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*workspace.Workspace), args.Error(1)
+}
+
+func (m *MockWorkspaceUsecase) AddMember(actor user.UserId, id workspace.WorkspaceId, userId user.UserId, role workspace.Role) (workspace.Member, error) {
+	args := m.Called(actor, id, userId, role)
+	return args.Get(0).(workspace.Member), args.Error(1)
+}
+
+func (m *MockWorkspaceUsecase) ChangeRole(actor user.UserId, id workspace.WorkspaceId, userId user.UserId, role workspace.Role) (workspace.Member, error) {
+	args := m.Called(actor, id, userId, role)
+	return args.Get(0).(workspace.Member), args.Error(1)
+}
+
+func (m *MockWorkspaceUsecase) RemoveMember(actor user.UserId, id workspace.WorkspaceId, userId user.UserId) error {
+	args := m.Called(actor, id, userId)
+	return args.Error(0)
+}
+
+func TestWorkspaceController(t *testing.T) {
+	createdAt := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	team := &workspace.Workspace{
+		Id:        1,
+		Name:      "team",
+		CreatedAt: createdAt,
+		Members:   []workspace.Member{{WorkspaceId: 1, UserId: 1, Role: workspace.RoleOwner, CreatedAt: createdAt}},
+	}
+
+	testCases := []struct {
+		name           string
+		mockSetup      func(m *MockWorkspaceUsecase)
+		method         string
+		path           string
+		reqBody        string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "Create Success",
+			mockSetup: func(m *MockWorkspaceUsecase) {
+				m.On("CreateWorkspace", user.UserId(1), "team").Return(team, nil)
+			},
+			method:         "POST",
+			path:           "/workspaces",
+			reqBody:        `{"name":"team"}`,
+			expectedStatus: http.StatusCreated,
+			expectedBody:   `{"id":1,"name":"team","created_at":"2024-01-01T09:00:00Z","members":[{"workspace_id":1,"user_id":1,"role":"owner","created_at":"2024-01-01T09:00:00Z"}]}`,
+		},
+		{
+			name:           "Create Missing Name",
+			mockSetup:      func(m *MockWorkspaceUsecase) {},
+			method:         "POST",
+			path:           "/workspaces",
+			reqBody:        `{}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Get Forbidden",
+			mockSetup: func(m *MockWorkspaceUsecase) {
+				m.On("GetWorkspace", user.UserId(1), workspace.WorkspaceId(2)).Return(nil, workspace.ErrForbidden)
+			},
+			method:         "GET",
+			path:           "/workspaces/2",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name: "Add Member Success",
+			mockSetup: func(m *MockWorkspaceUsecase) {
+				m.On("AddMember", user.UserId(1), workspace.WorkspaceId(1), user.UserId(2), workspace.RoleViewer).
+					Return(workspace.Member{WorkspaceId: 1, UserId: 2, Role: workspace.RoleViewer, CreatedAt: createdAt}, nil)
+			},
+			method:         "POST",
+			path:           "/workspaces/1/members",
+			reqBody:        `{"user_id":2,"role":"viewer"}`,
+			expectedStatus: http.StatusCreated,
+			expectedBody:   `{"workspace_id":1,"user_id":2,"role":"viewer","created_at":"2024-01-01T09:00:00Z"}`,
+		},
+		{
+			name:           "Add Member Unknown Role",
+			mockSetup:      func(m *MockWorkspaceUsecase) {},
+			method:         "POST",
+			path:           "/workspaces/1/members",
+			reqBody:        `{"user_id":2,"role":"guest"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Add Member Already Joined",
+			mockSetup: func(m *MockWorkspaceUsecase) {
+				m.On("AddMember", user.UserId(1), workspace.WorkspaceId(1), user.UserId(2), workspace.RoleMember).
+					Return(workspace.Member{}, workspace.ErrAlreadyMember)
+			},
+			method:         "POST",
+			path:           "/workspaces/1/members",
+			reqBody:        `{"user_id":2,"role":"member"}`,
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name: "Change Role Last Owner",
+			mockSetup: func(m *MockWorkspaceUsecase) {
+				m.On("ChangeRole", user.UserId(1), workspace.WorkspaceId(1), user.UserId(1), workspace.RoleMember).
+					Return(workspace.Member{}, workspace.ErrLastOwner)
+			},
+			method:         "PUT",
+			path:           "/workspaces/1/members/1",
+			reqBody:        `{"role":"member"}`,
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name: "Remove Member Success",
+			mockSetup: func(m *MockWorkspaceUsecase) {
+				m.On("RemoveMember", user.UserId(1), workspace.WorkspaceId(1), user.UserId(2)).Return(nil)
+			},
+			method:         "DELETE",
+			path:           "/workspaces/1/members/2",
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name: "Remove Non Member",
+			mockSetup: func(m *MockWorkspaceUsecase) {
+				m.On("RemoveMember", user.UserId(1), workspace.WorkspaceId(1), user.UserId(9)).Return(workspace.ErrNotMember)
+			},
+			method:         "DELETE",
+			path:           "/workspaces/1/members/9",
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockUsecase := new(MockWorkspaceUsecase)
+			tc.mockSetup(mockUsecase)
+
+			controller := controller.NewWorkspaceController(mockUsecase)
+
+			req, _ := http.NewRequest(tc.method, tc.path, bytes.NewBufferString(tc.reqBody))
+			req.Header.Set("Content-Type", "application/json")
+
+			w := httptest.NewRecorder()
+
+			r := gin.Default()
+			r.Use(authenticated(user.UserId(1)))
+			r.POST("/workspaces", controller.CreateWorkspace)
+			r.GET("/workspaces", controller.GetWorkspaces)
+			r.GET("/workspaces/:id", controller.GetWorkspace)
+			r.POST("/workspaces/:id/members", controller.AddMember)
+			r.PUT("/workspaces/:id/members/:user_id", controller.ChangeRole)
+			r.DELETE("/workspaces/:id/members/:user_id", controller.RemoveMember)
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			if tc.expectedBody != "" {
+				assert.JSONEq(t, tc.expectedBody, w.Body.String())
+			}
+			mockUsecase.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/fuki01/onion-architecture/domain/user"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TokenVerifier はアクセストークンを検証し、認証されたユーザーを返す
+type TokenVerifier interface {
+	Verify(token string) (user.UserId, error)
+}
+
+// ApiKeyVerifier はAPIキーを検証し、キーの所有者と許可されたスコープを返す
+type ApiKeyVerifier interface {
+	Authenticate(key string) (user.UserId, []user.Scope, error)
+}
+
+type userIdKey struct{}
+
+type scopesKey struct{}
+
+// WithUserId は認証されたユーザーをコンテキストに格納する
+func WithUserId(ctx context.Context, id user.UserId) context.Context {
+	return context.WithValue(ctx, userIdKey{}, id)
+}
+
+// UserIdFromContext はコンテキストから認証されたユーザーを取り出す
+func UserIdFromContext(ctx context.Context) (user.UserId, bool) {
+	id, ok := ctx.Value(userIdKey{}).(user.UserId)
+	return id, ok
+}
+
+// WithScopes はAPIキーで許可されたスコープをコンテキストに格納する
+func WithScopes(ctx context.Context, scopes []user.Scope) context.Context {
+	return context.WithValue(ctx, scopesKey{}, scopes)
+}
+
+// ScopesFromContext はAPIキーで許可されたスコープを取り出す
+// ログインしたユーザーのリクエストの場合はfalseを返す
+func ScopesFromContext(ctx context.Context) ([]user.Scope, bool) {
+	scopes, ok := ctx.Value(scopesKey{}).([]user.Scope)
+	return scopes, ok
+}
+
+// Authenticate はAuthorizationヘッダーのBearerトークンを検証する
+// APIキーの形式のトークンはapiKeysで、それ以外はアクセストークンとしてverifierで検証する
+// 検証に成功した場合はユーザーをリクエストのコンテキストに格納し、失敗した場合は401を返す
+func Authenticate(verifier TokenVerifier, apiKeys ApiKeyVerifier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scheme, token, ok := strings.Cut(c.GetHeader("Authorization"), " ")
+		if !ok || !strings.EqualFold(scheme, "Bearer") || token == "" {
+			unauthorized(c, "missing bearer token")
+			return
+		}
+
+		ctx := c.Request.Context()
+		if user.IsApiKey(token) {
+			id, scopes, err := apiKeys.Authenticate(token)
+			if err != nil {
+				unauthorized(c, "invalid api key")
+				return
+			}
+			ctx = WithScopes(WithUserId(ctx, id), scopes)
+		} else {
+			id, err := verifier.Verify(token)
+			if err != nil {
+				unauthorized(c, "invalid token")
+				return
+			}
+			ctx = WithUserId(ctx, id)
+		}
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// RequireScope はAPIキーのリクエストに指定したスコープが許可されているかを確認する
+// ログインしたユーザーのリクエストは全て許可する
+func RequireScope(scope user.Scope) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scopes, ok := ScopesFromContext(c.Request.Context())
+		if !ok {
+			c.Next()
+			return
+		}
+		for _, s := range scopes {
+			if s == scope {
+				c.Next()
+				return
+			}
+		}
+		c.Header("WWW-Authenticate", `Bearer realm="api", error="insufficient_scope", scope="`+string(scope)+`"`)
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "insufficient scope", "scope": scope})
+	}
+}
+
+// RequireSession はログインしたユーザーのリクエストのみを許可する
+// APIキーの管理やWebhookの設定などはAPIキーでは操作できない
+func RequireSession() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := ScopesFromContext(c.Request.Context()); ok {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "api keys are not allowed"})
+			return
+		}
+		c.Next()
+	}
+}
+
+func unauthorized(c *gin.Context, message string) {
+	c.Header("WWW-Authenticate", `Bearer realm="api"`)
+	c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": message})
+}
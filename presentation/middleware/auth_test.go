@@ -0,0 +1,186 @@
+package middleware_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fuki01/onion-architecture/domain/user"
+	"github.com/fuki01/onion-architecture/presentation/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockTokenVerifier struct {
+	mock.Mock
+}
+
+func (m *MockTokenVerifier) Verify(token string) (user.UserId, error) {
+	args := m.Called(token)
+	return args.Get(0).(user.UserId), args.Error(1)
+}
+
+type MockApiKeyVerifier struct {
+	mock.Mock
+}
+
+func (m *MockApiKeyVerifier) Authenticate(key string) (user.UserId, []user.Scope, error) {
+	args := m.Called(key)
+	if args.Get(1) == nil {
+		return args.Get(0).(user.UserId), nil, args.Error(2)
+	}
+	return args.Get(0).(user.UserId), args.Get(1).([]user.Scope), args.Error(2)
+}
+
+func TestAuthenticate(t *testing.T) {
+	testCases := []struct {
+		name           string
+		mockSetup      func(m *MockTokenVerifier, k *MockApiKeyVerifier)
+		header         string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "Success",
+			mockSetup: func(m *MockTokenVerifier, k *MockApiKeyVerifier) {
+				m.On("Verify", "valid").Return(user.UserId(1), nil)
+			},
+			header:         "Bearer valid",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"user_id":1}`,
+		},
+		{
+			name:           "Missing Header",
+			mockSetup:      func(m *MockTokenVerifier, k *MockApiKeyVerifier) {},
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   `{"error":"missing bearer token"}`,
+		},
+		{
+			name:           "Other Scheme",
+			mockSetup:      func(m *MockTokenVerifier, k *MockApiKeyVerifier) {},
+			header:         "Basic dXNlcjpwYXNz",
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   `{"error":"missing bearer token"}`,
+		},
+		{
+			name: "Invalid Token",
+			mockSetup: func(m *MockTokenVerifier, k *MockApiKeyVerifier) {
+				m.On("Verify", "expired").Return(user.UserId(0), errors.New("token is expired"))
+			},
+			header:         "Bearer expired",
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   `{"error":"invalid token"}`,
+		},
+		{
+			name: "Api Key",
+			mockSetup: func(m *MockTokenVerifier, k *MockApiKeyVerifier) {
+				k.On("Authenticate", "tk_0a1b2c3d_secret").Return(user.UserId(2), []user.Scope{user.ScopeTasksRead}, nil)
+			},
+			header:         "Bearer tk_0a1b2c3d_secret",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"user_id":2}`,
+		},
+		{
+			name: "Invalid Api Key",
+			mockSetup: func(m *MockTokenVerifier, k *MockApiKeyVerifier) {
+				k.On("Authenticate", "tk_0a1b2c3d_revoked").Return(user.UserId(0), nil, user.ErrInvalidApiKey)
+			},
+			header:         "Bearer tk_0a1b2c3d_revoked",
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   `{"error":"invalid api key"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			verifier := new(MockTokenVerifier)
+			apiKeys := new(MockApiKeyVerifier)
+			tc.mockSetup(verifier, apiKeys)
+
+			req, _ := http.NewRequest("GET", "/me", nil)
+			if tc.header != "" {
+				req.Header.Set("Authorization", tc.header)
+			}
+
+			w := httptest.NewRecorder()
+
+			r := gin.Default()
+			r.GET("/me", middleware.Authenticate(verifier, apiKeys), func(c *gin.Context) {
+				id, _ := middleware.UserIdFromContext(c.Request.Context())
+				c.JSON(http.StatusOK, gin.H{"user_id": id})
+			})
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			assert.JSONEq(t, tc.expectedBody, w.Body.String())
+			if tc.expectedStatus == http.StatusUnauthorized {
+				assert.NotEmpty(t, w.Header().Get("WWW-Authenticate"))
+			}
+			verifier.AssertExpectations(t)
+			apiKeys.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRequireScope(t *testing.T) {
+	testCases := []struct {
+		name           string
+		scopes         []user.Scope
+		apiKey         bool
+		middleware     gin.HandlerFunc
+		expectedStatus int
+	}{
+		{
+			name:           "Session",
+			middleware:     middleware.RequireScope(user.ScopeTasksWrite),
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Api Key With Scope",
+			scopes:         []user.Scope{user.ScopeTasksRead, user.ScopeTasksWrite},
+			apiKey:         true,
+			middleware:     middleware.RequireScope(user.ScopeTasksWrite),
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Api Key Without Scope",
+			scopes:         []user.Scope{user.ScopeTasksRead},
+			apiKey:         true,
+			middleware:     middleware.RequireScope(user.ScopeTasksWrite),
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "Session Only With Session",
+			middleware:     middleware.RequireSession(),
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Session Only With Api Key",
+			scopes:         []user.Scope{user.ScopeTasksRead, user.ScopeTasksWrite},
+			apiKey:         true,
+			middleware:     middleware.RequireSession(),
+			expectedStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, _ := http.NewRequest("POST", "/tasks", nil)
+			if tc.apiKey {
+				req = req.WithContext(middleware.WithScopes(req.Context(), tc.scopes))
+			}
+
+			w := httptest.NewRecorder()
+
+			r := gin.Default()
+			r.POST("/tasks", tc.middleware, func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+		})
+	}
+}
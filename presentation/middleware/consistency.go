@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/fuki01/onion-architecture/domain/user"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SessionTracker はユーザーのリクエストの間の書き込みを記録する
+// Beginは読み込み先の判断に使うセッションをコンテキストに格納し、リクエストの終了時に呼ぶ関数を返す
+type SessionTracker interface {
+	Begin(ctx context.Context, userId user.UserId) (context.Context, func())
+}
+
+// ReadYourWrites はユーザーが書き込んだ内容を直後の読み込みで必ず参照できるようにする
+// Authenticateの後に置く。認証されていないリクエストは何もしない
+func ReadYourWrites(tracker SessionTracker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userId, ok := UserIdFromContext(c.Request.Context())
+		if !ok {
+			c.Next()
+			return
+		}
+		ctx, done := tracker.Begin(c.Request.Context(), userId)
+		defer done()
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
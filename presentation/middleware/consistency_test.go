@@ -0,0 +1,76 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fuki01/onion-architecture/domain/user"
+	"github.com/fuki01/onion-architecture/presentation/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type sessionKey struct{}
+
+type stubTracker struct {
+	begun []user.UserId
+	done  int
+}
+
+func (s *stubTracker) Begin(ctx context.Context, userId user.UserId) (context.Context, func()) {
+	s.begun = append(s.begun, userId)
+	return context.WithValue(ctx, sessionKey{}, userId), func() { s.done++ }
+}
+
+func TestReadYourWrites(t *testing.T) {
+	testCases := []struct {
+		name          string
+		authenticated bool
+		expectedBegun []user.UserId
+	}{
+		{
+			name:          "Authenticated",
+			authenticated: true,
+			expectedBegun: []user.UserId{1},
+		},
+		{
+			name:          "Anonymous",
+			authenticated: false,
+			expectedBegun: nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tracker := &stubTracker{}
+			var session any
+
+			r := gin.Default()
+			if tc.authenticated {
+				r.Use(func(c *gin.Context) {
+					c.Request = c.Request.WithContext(middleware.WithUserId(c.Request.Context(), user.UserId(1)))
+				})
+			}
+			r.Use(middleware.ReadYourWrites(tracker))
+			r.GET("/tasks", func(c *gin.Context) {
+				session = c.Request.Context().Value(sessionKey{})
+				c.Status(http.StatusOK)
+			})
+
+			req, _ := http.NewRequest("GET", "/tasks", nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, tc.expectedBegun, tracker.begun)
+			assert.Equal(t, len(tc.expectedBegun), tracker.done)
+			if tc.authenticated {
+				assert.Equal(t, user.UserId(1), session)
+			} else {
+				assert.Nil(t, session)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/fuki01/onion-architecture/domain/idempotency"
+	"github.com/fuki01/onion-architecture/domain/user"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// IdempotencyKeyHeader はリクエストを再送しても一度しか処理しないためのキーを指定するヘッダー
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader は保存した応答を返したことを示すヘッダー
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+)
+
+// IdempotencyStore は冪等キーを付けたリクエストの応答を保存する
+// Beginは応答を記録済みの場合にその記録とtrueを返す
+type IdempotencyStore interface {
+	Begin(ctx context.Context, userId user.UserId, key, requestHash string) (*idempotency.Record, bool, error)
+	Complete(ctx context.Context, r *idempotency.Record, statusCode int, contentType string, body []byte) error
+	Abandon(ctx context.Context, r *idempotency.Record) error
+}
+
+// Idempotency はIdempotency-Keyヘッダーを付けたリクエストを一度だけ処理し、再送には保存した応答を返す
+// Authenticateの後に置く。ヘッダーのないリクエストと認証されていないリクエストは何もしない
+// 同じキーを別の内容のリクエストに使った場合は422、同じリクエストを処理中の場合は409を返す
+// 5xxの応答は保存せず、同じキーで再試行できるようにする
+func Idempotency(store IdempotencyStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		userId, ok := UserIdFromContext(c.Request.Context())
+		if key == "" || !ok {
+			c.Next()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "failed to read request body"})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		ctx := c.Request.Context()
+		requestHash := idempotency.HashRequest(c.Request.Method, c.Request.URL.Path, body)
+		record, replay, err := store.Begin(ctx, userId, key, requestHash)
+		switch {
+		case errors.Is(err, idempotency.ErrInvalidKey):
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		case errors.Is(err, idempotency.ErrKeyReused):
+			c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return
+		case errors.Is(err, idempotency.ErrInProgress):
+			c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		case err != nil:
+			slog.ErrorContext(ctx, "failed to begin idempotent request", slog.Any("error", err))
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+		if replay {
+			c.Header(IdempotentReplayedHeader, "true")
+			c.Data(record.StatusCode, record.ContentType, record.Body)
+			c.Abort()
+			return
+		}
+
+		w := &capturingWriter{ResponseWriter: c.Writer}
+		c.Writer = w
+		completed := false
+		// 応答の記録はクライアントが切断しても行う。パニックした場合も受け付けを取り消す
+		defer func() {
+			if completed {
+				return
+			}
+			if err := store.Abandon(context.WithoutCancel(ctx), record); err != nil {
+				slog.ErrorContext(ctx, "failed to abandon idempotent request", slog.Any("error", err))
+			}
+		}()
+
+		c.Next()
+
+		if status := w.Status(); status < http.StatusInternalServerError {
+			if err := store.Complete(context.WithoutCancel(ctx), record, status, w.Header().Get("Content-Type"), w.body.Bytes()); err != nil {
+				slog.ErrorContext(ctx, "failed to save idempotent response", slog.Any("error", err))
+				return
+			}
+			completed = true
+		}
+	}
+}
+
+// capturingWriter は応答を保存するため、書き込んだ本文を控える
+type capturingWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *capturingWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *capturingWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
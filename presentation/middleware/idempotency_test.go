@@ -0,0 +1,178 @@
+package middleware_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/fuki01/onion-architecture/domain/idempotency"
+	"github.com/fuki01/onion-architecture/domain/user"
+	"github.com/fuki01/onion-architecture/presentation/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockIdempotencyStore struct {
+	mock.Mock
+}
+
+func (m *MockIdempotencyStore) Begin(ctx context.Context, userId user.UserId, key, requestHash string) (*idempotency.Record, bool, error) {
+	args := m.Called(userId, key, requestHash)
+	if args.Get(0) == nil {
+		return nil, false, args.Error(2)
+	}
+	return args.Get(0).(*idempotency.Record), args.Bool(1), args.Error(2)
+}
+
+func (m *MockIdempotencyStore) Complete(ctx context.Context, r *idempotency.Record, statusCode int, contentType string, body []byte) error {
+	args := m.Called(r, statusCode, contentType, body)
+	return args.Error(0)
+}
+
+func (m *MockIdempotencyStore) Abandon(ctx context.Context, r *idempotency.Record) error {
+	args := m.Called(r)
+	return args.Error(0)
+}
+
+func TestIdempotency(t *testing.T) {
+	const body = `{"name":"task"}`
+	requestHash := idempotency.HashRequest("POST", "/tasks", []byte(body))
+	record := &idempotency.Record{UserId: 1, RequestHash: requestHash}
+	replayed := &idempotency.Record{UserId: 1, RequestHash: requestHash, StatusCode: http.StatusCreated, ContentType: "application/json; charset=utf-8", Body: []byte(`{"id":1}`)}
+
+	testCases := []struct {
+		name           string
+		key            string
+		handlerStatus  int
+		setupMock      func(store *MockIdempotencyStore)
+		expectedStatus int
+		expectedBody   string
+		expectedCalls  int
+		replayed       bool
+	}{
+		{
+			name:          "FirstRequest",
+			key:           "key-1",
+			handlerStatus: http.StatusCreated,
+			setupMock: func(store *MockIdempotencyStore) {
+				store.On("Begin", user.UserId(1), "key-1", requestHash).Return(record, false, nil)
+				store.On("Complete", record, http.StatusCreated, "application/json; charset=utf-8", []byte(`{"id":1}`)).Return(nil)
+			},
+			expectedStatus: http.StatusCreated,
+			expectedBody:   `{"id":1}`,
+			expectedCalls:  1,
+		},
+		{
+			name: "Replay",
+			key:  "key-1",
+			setupMock: func(store *MockIdempotencyStore) {
+				store.On("Begin", user.UserId(1), "key-1", requestHash).Return(replayed, true, nil)
+			},
+			expectedStatus: http.StatusCreated,
+			expectedBody:   `{"id":1}`,
+			expectedCalls:  0,
+			replayed:       true,
+		},
+		{
+			name:          "ServerError",
+			key:           "key-1",
+			handlerStatus: http.StatusInternalServerError,
+			setupMock: func(store *MockIdempotencyStore) {
+				store.On("Begin", user.UserId(1), "key-1", requestHash).Return(record, false, nil)
+				store.On("Abandon", record).Return(nil)
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"id":1}`,
+			expectedCalls:  1,
+		},
+		{
+			name: "KeyReused",
+			key:  "key-1",
+			setupMock: func(store *MockIdempotencyStore) {
+				store.On("Begin", user.UserId(1), "key-1", requestHash).Return(nil, false, idempotency.ErrKeyReused)
+			},
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedBody:   `{"error":"idempotency key was already used for a different request"}`,
+		},
+		{
+			name: "InProgress",
+			key:  "key-1",
+			setupMock: func(store *MockIdempotencyStore) {
+				store.On("Begin", user.UserId(1), "key-1", requestHash).Return(nil, false, idempotency.ErrInProgress)
+			},
+			expectedStatus: http.StatusConflict,
+			expectedBody:   `{"error":"a request with this idempotency key is in progress"}`,
+		},
+		{
+			name: "InvalidKey",
+			key:  strings.Repeat("k", 256),
+			setupMock: func(store *MockIdempotencyStore) {
+				store.On("Begin", user.UserId(1), strings.Repeat("k", 256), requestHash).Return(nil, false, idempotency.ErrInvalidKey)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"idempotency key must be 1 to 255 characters"}`,
+		},
+		{
+			name: "StoreFailure",
+			key:  "key-1",
+			setupMock: func(store *MockIdempotencyStore) {
+				store.On("Begin", user.UserId(1), "key-1", requestHash).Return(nil, false, errors.New("connection refused"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"error":"internal server error"}`,
+		},
+		{
+			name:           "WithoutKey",
+			handlerStatus:  http.StatusCreated,
+			setupMock:      func(store *MockIdempotencyStore) {},
+			expectedStatus: http.StatusCreated,
+			expectedBody:   `{"id":1}`,
+			expectedCalls:  1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// モック作成
+			store := new(MockIdempotencyStore)
+			tc.setupMock(store)
+			calls := 0
+
+			r := gin.New()
+			r.Use(func(c *gin.Context) {
+				c.Request = c.Request.WithContext(middleware.WithUserId(c.Request.Context(), user.UserId(1)))
+			})
+			r.POST("/tasks", middleware.Idempotency(store), func(c *gin.Context) {
+				calls++
+				// ハンドラーは元の本文を読める
+				received, _ := io.ReadAll(c.Request.Body)
+				assert.Equal(t, body, string(received))
+				c.JSON(tc.handlerStatus, gin.H{"id": 1})
+			})
+
+			req := httptest.NewRequest("POST", "/tasks", strings.NewReader(body))
+			if tc.key != "" {
+				req.Header.Set(middleware.IdempotencyKeyHeader, tc.key)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			// 検証
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			assert.JSONEq(t, tc.expectedBody, w.Body.String())
+			assert.Equal(t, tc.expectedCalls, calls)
+			if tc.replayed {
+				assert.Equal(t, "true", w.Header().Get(middleware.IdempotentReplayedHeader))
+				assert.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
+			} else {
+				assert.Empty(t, w.Header().Get(middleware.IdempotentReplayedHeader))
+			}
+			store.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/fuki01/onion-architecture/presentation/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type observed struct {
+	method, route string
+	status        int
+}
+
+type stubObserver struct {
+	requests []observed
+}
+
+func (s *stubObserver) ObserveRequest(method, route string, status int, duration time.Duration) {
+	s.requests = append(s.requests, observed{method: method, route: route, status: status})
+}
+
+func TestInstrument(t *testing.T) {
+	observer := &stubObserver{}
+	r := gin.New()
+	r.Use(middleware.Instrument(observer))
+	r.GET("/tasks/:id", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+	for _, path := range []string{"/tasks/1", "/tasks/2", "/unknown"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+	}
+
+	assert.Equal(t, []observed{
+		{method: "GET", route: "/tasks/:id", status: http.StatusNoContent},
+		{method: "GET", route: "/tasks/:id", status: http.StatusNoContent},
+		{method: "GET", route: "unmatched", status: http.StatusNotFound},
+	}, observer.requests)
+}
@@ -0,0 +1,91 @@
+package middleware
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimitStore はキーごとのトークンバケットを保持する
+// Takeはバケットにrate毎秒の速さでburstを上限にトークンを補充してから1つ取り出し、取り出せたかと残りのトークン数を返す
+// 複数のプロセスで上限を共有する場合は共有のストアを実装する
+type RateLimitStore interface {
+	Take(ctx context.Context, key string, rate float64, burst int) (allowed bool, tokens float64, err error)
+}
+
+// Limit は1分あたりのリクエスト数と、まとめて受け付けられるリクエスト数
+// PerMinuteが0以下の場合は制限しない
+type Limit struct {
+	PerMinute int
+	Burst     int
+}
+
+// KeyFunc はリクエストの送り手を識別するキーを返す
+type KeyFunc func(c *gin.Context) string
+
+// ByClientIP はクライアントのIPアドレスで送り手を識別する
+func ByClientIP(c *gin.Context) string {
+	return "ip:" + c.ClientIP()
+}
+
+// ByIdentity は認証されたユーザー、Authorizationヘッダー、クライアントのIPアドレスの順で送り手を識別する
+// Authenticateより前に置いた場合は、検証前のトークンごとに数える
+func ByIdentity(c *gin.Context) string {
+	if id, ok := UserIdFromContext(c.Request.Context()); ok {
+		return fmt.Sprintf("user:%d", id)
+	}
+	if auth := c.GetHeader("Authorization"); auth != "" {
+		sum := sha256.Sum256([]byte(auth))
+		return "token:" + hex.EncodeToString(sum[:16])
+	}
+	return ByClientIP(c)
+}
+
+// RateLimit は送り手ごとのリクエストの数をトークンバケットで制限する
+// nameはルートのグループの名前で、グループごとに別のバケットを使う
+// 制限を超えたリクエストは429とRetry-Afterで拒否し、全ての応答にRateLimit-*ヘッダーで残りを返す
+// ストアが失敗した場合はリクエストを拒否せずに通す
+func RateLimit(store RateLimitStore, name string, limit Limit, key KeyFunc) gin.HandlerFunc {
+	if limit.PerMinute <= 0 {
+		return func(c *gin.Context) { c.Next() }
+	}
+	rate := float64(limit.PerMinute) / 60
+	burst := limit.Burst
+	if burst <= 0 {
+		burst = 1
+	}
+
+	return func(c *gin.Context) {
+		allowed, tokens, err := store.Take(c.Request.Context(), name+":"+key(c), rate, burst)
+		if err != nil {
+			slog.WarnContext(c.Request.Context(), "rate limit store failed", slog.String("group", name), slog.Any("error", err))
+			c.Next()
+			return
+		}
+
+		c.Header("RateLimit-Limit", strconv.Itoa(burst))
+		c.Header("RateLimit-Remaining", strconv.Itoa(int(math.Floor(tokens))))
+		c.Header("RateLimit-Reset", strconv.Itoa(seconds(float64(burst)-tokens, rate)))
+		if !allowed {
+			c.Header("Retry-After", strconv.Itoa(max(seconds(1-tokens, rate), 1)))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "rate limit exceeded"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// seconds はtokens個のトークンが補充されるまでの秒数を切り上げて返す
+func seconds(tokens, rate float64) int {
+	if tokens <= 0 {
+		return 0
+	}
+	return int(math.Ceil(tokens / rate))
+}
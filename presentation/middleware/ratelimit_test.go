@@ -0,0 +1,127 @@
+package middleware_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fuki01/onion-architecture/domain/user"
+	"github.com/fuki01/onion-architecture/presentation/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockRateLimitStore struct {
+	mock.Mock
+}
+
+func (m *MockRateLimitStore) Take(ctx context.Context, key string, rate float64, burst int) (bool, float64, error) {
+	args := m.Called(key, rate, burst)
+	return args.Bool(0), args.Get(1).(float64), args.Error(2)
+}
+
+func TestRateLimit(t *testing.T) {
+	tests := []struct {
+		name        string
+		allowed     bool
+		tokens      float64
+		err         error
+		wantCode    int
+		wantHeaders map[string]string
+	}{
+		{
+			name:     "allowed",
+			allowed:  true,
+			tokens:   4.5,
+			wantCode: http.StatusOK,
+			wantHeaders: map[string]string{
+				"RateLimit-Limit":     "10",
+				"RateLimit-Remaining": "4",
+				// 60回/分なので5.5個の補充に6秒かかる
+				"RateLimit-Reset": "6",
+				"Retry-After":     "",
+			},
+		},
+		{
+			name:     "limited",
+			allowed:  false,
+			tokens:   0.25,
+			wantCode: http.StatusTooManyRequests,
+			wantHeaders: map[string]string{
+				"RateLimit-Remaining": "0",
+				"Retry-After":         "1",
+			},
+		},
+		{
+			name:     "store failure lets the request through",
+			err:      errors.New("connection refused"),
+			wantCode: http.StatusOK,
+			wantHeaders: map[string]string{
+				"RateLimit-Limit": "",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// モック作成
+			store := new(MockRateLimitStore)
+			store.On("Take", "api:ip:192.0.2.1", 1.0, 10).Return(tt.allowed, tt.tokens, tt.err)
+
+			r := gin.New()
+			r.Use(middleware.RateLimit(store, "api", middleware.Limit{PerMinute: 60, Burst: 10}, middleware.ByClientIP))
+			r.POST("/tasks", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			req := httptest.NewRequest("POST", "/tasks", nil)
+			req.RemoteAddr = "192.0.2.1:1234"
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			// 検証
+			assert.Equal(t, tt.wantCode, w.Code)
+			for k, v := range tt.wantHeaders {
+				assert.Equal(t, v, w.Header().Get(k), k)
+			}
+			store.AssertExpectations(t)
+		})
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		store := new(MockRateLimitStore)
+		r := gin.New()
+		r.Use(middleware.RateLimit(store, "api", middleware.Limit{}, middleware.ByClientIP))
+		r.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		store.AssertNotCalled(t, "Take")
+	})
+}
+
+func TestByIdentity(t *testing.T) {
+	newContext := func(header string) *gin.Context {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest("GET", "/", nil)
+		c.Request.RemoteAddr = "192.0.2.1:1234"
+		if header != "" {
+			c.Request.Header.Set("Authorization", header)
+		}
+		return c
+	}
+
+	authenticated := newContext("Bearer token")
+	authenticated.Request = authenticated.Request.WithContext(middleware.WithUserId(authenticated.Request.Context(), user.UserId(7)))
+	assert.Equal(t, "user:7", middleware.ByIdentity(authenticated))
+
+	a, b := middleware.ByIdentity(newContext("Bearer a")), middleware.ByIdentity(newContext("Bearer b"))
+	assert.Contains(t, a, "token:")
+	assert.NotEqual(t, a, b)
+	assert.NotContains(t, a, "Bearer")
+
+	assert.Equal(t, "ip:192.0.2.1", middleware.ByIdentity(newContext("")))
+}
@@ -0,0 +1,52 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/fuki01/onion-architecture/presentation/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestID(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		want   string
+	}{
+		{name: "keeps the caller's id", header: "abc-123", want: "abc-123"},
+		{name: "generates when missing", header: ""},
+		{name: "replaces an invalid id", header: "bad id\n"},
+		{name: "replaces a too long id", header: strings.Repeat("a", 129)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var fromContext string
+			r := gin.New()
+			r.Use(middleware.RequestID())
+			r.GET("/", func(c *gin.Context) {
+				fromContext, _ = middleware.RequestIDFromContext(c.Request.Context())
+			})
+
+			req := httptest.NewRequest("GET", "/", nil)
+			if tt.header != "" {
+				req.Header.Set(middleware.RequestIDHeader, tt.header)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			got := w.Header().Get(middleware.RequestIDHeader)
+			assert.Equal(t, got, fromContext)
+			if tt.want != "" {
+				assert.Equal(t, tt.want, got)
+			} else {
+				assert.Len(t, got, 32)
+			}
+			assert.Equal(t, http.StatusOK, w.Code)
+		})
+	}
+}
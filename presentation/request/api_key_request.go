@@ -0,0 +1,9 @@
+package request
+
+import "time"
+
+type CreateApiKeyRequest struct {
+	Name      string     `json:"name" binding:"required,max=100"`
+	Scopes    []string   `json:"scopes" binding:"required,min=1,dive,oneof=tasks:read tasks:write"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
@@ -0,0 +1,14 @@
+package request
+
+import "github.com/fuki01/onion-architecture/domain/user"
+
+type NotificationPreferenceRequest struct {
+	Channel user.NotificationChannel `json:"channel" binding:"required,oneof=email webhook slack"`
+	Target  string                   `json:"target" binding:"required"`
+	Enabled bool                     `json:"enabled"`
+}
+
+type UpdateNotificationSettingsRequest struct {
+	Locale      user.Locale                     `json:"locale" binding:"omitempty,oneof=ja en"`
+	Preferences []NotificationPreferenceRequest `json:"preferences" binding:"dive"`
+}
@@ -0,0 +1,65 @@
+package request
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/fuki01/onion-architecture/domain/task"
+)
+
+// ParseTaskPatch はJSON Merge Patch(RFC 7396)形式のリクエストボディを解釈する
+// nullを指定したフィールドは既定値に戻す
+func ParseTaskPatch(body []byte) (task.Patch, error) {
+	var patch task.Patch
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil || fields == nil {
+		return patch, errors.New("merge patch must be a JSON object")
+	}
+
+	for field, raw := range fields {
+		isNull := bytes.Equal(bytes.TrimSpace(raw), []byte("null"))
+		var err error
+		switch field {
+		case "name":
+			patch.Name = new(string)
+			if !isNull {
+				err = json.Unmarshal(raw, patch.Name)
+			}
+		case "description":
+			patch.Description = new(string)
+			if !isNull {
+				err = json.Unmarshal(raw, patch.Description)
+			}
+		case "priority":
+			priority := task.PriorityMedium
+			patch.Priority = &priority
+			if !isNull {
+				err = json.Unmarshal(raw, patch.Priority)
+			}
+		case "estimate_minutes":
+			patch.EstimateMinutes = new(int)
+			if !isNull {
+				err = json.Unmarshal(raw, patch.EstimateMinutes)
+			}
+		case "recurrence":
+			patch.Recurrence = new(string)
+			if !isNull {
+				err = json.Unmarshal(raw, patch.Recurrence)
+			}
+		case "tags":
+			patch.Tags = &[]string{}
+			if !isNull {
+				err = json.Unmarshal(raw, patch.Tags)
+			}
+		default:
+			return patch, &task.ValidationError{Field: field, Message: fmt.Sprintf("field %q cannot be edited", field)}
+		}
+		if err != nil {
+			return patch, &task.ValidationError{Field: field, Message: fmt.Sprintf("invalid value for %q", field)}
+		}
+	}
+
+	return patch, nil
+}
@@ -3,13 +3,45 @@ package request
 import (
 	"github.com/fuki01/onion-architecture/domain/task"
 	"github.com/fuki01/onion-architecture/domain/user"
+	"github.com/fuki01/onion-architecture/domain/workspace"
 )
 
 // request.go
 type CreateTaskRequest struct {
-	Name    string      `json:"name" binding:"required"`
-	UserId  user.UserId `json:"user_id" binding:"required"`
-	DueDate string      `json:"due_date" binding:"required"`
+	Name            string                 `json:"name" binding:"required"`
+	DueDate         string                 `json:"due_date" binding:"required"`
+	Description     string                 `json:"description"`
+	Priority        task.Priority          `json:"priority" binding:"omitempty,oneof=low medium high urgent"`
+	EstimateMinutes int                    `json:"estimate_minutes" binding:"min=0"`
+	Tags            []string               `json:"tags" binding:"dive,required,max=32"`
+	Recurrence      string                 `json:"recurrence"`
+	WorkspaceID     *workspace.WorkspaceId `json:"workspace_id"`
+}
+
+type CreateSubtaskRequest struct {
+	Name            string        `json:"name" binding:"required"`
+	DueDate         string        `json:"due_date" binding:"required"`
+	Description     string        `json:"description"`
+	Priority        task.Priority `json:"priority" binding:"omitempty,oneof=low medium high urgent"`
+	EstimateMinutes int           `json:"estimate_minutes" binding:"min=0"`
+	Tags            []string      `json:"tags" binding:"dive,required,max=32"`
+}
+
+type ReorderSubtasksRequest struct {
+	IDs []task.TaskId `json:"ids" binding:"required"`
+}
+
+type AddDependencyRequest struct {
+	BlockedByID task.TaskId `json:"blocked_by_id" binding:"required"`
+}
+
+type ShareTaskRequest struct {
+	UserID user.UserId `json:"user_id" binding:"required"`
+}
+
+// ReassignTaskRequest はassignee_idにnullを指定すると担当者を外す
+type ReassignTaskRequest struct {
+	AssigneeID *user.UserId `json:"assignee_id"`
 }
 
 type ExtendDueDateRequest struct {
@@ -21,3 +53,9 @@ type ChangeStatusRequest struct {
 	ID        task.TaskId     `json:"id" binding:"required"`
 	NewStatus task.TaskStatus `json:"new_status" binding:"required"`
 }
+
+type ListTasksQuery struct {
+	Tag      string        `form:"tag"`
+	Priority task.Priority `form:"priority" binding:"omitempty,oneof=low medium high urgent"`
+	Overdue  bool          `form:"overdue"`
+}
@@ -4,7 +4,6 @@ import (
 	"testing"
 
 	"github.com/fuki01/onion-architecture/domain/task"
-	"github.com/fuki01/onion-architecture/domain/user"
 	"github.com/fuki01/onion-architecture/presentation/request"
 	"github.com/stretchr/testify/assert"
 )
@@ -13,18 +12,15 @@ func TestCreateTaskRequest(t *testing.T) {
 	t.Run("Valid request", func(t *testing.T) {
 		req := request.CreateTaskRequest{
 			Name:    "Task 1",
-			UserId:  user.UserId(1),
 			DueDate: "2023-05-31",
 		}
 		assert.Equal(t, "Task 1", req.Name)
-		assert.Equal(t, user.UserId(1), req.UserId)
 		assert.Equal(t, "2023-05-31", req.DueDate)
 	})
 
 	t.Run("Missing required fields", func(t *testing.T) {
 		req := request.CreateTaskRequest{}
 		assert.Empty(t, req.Name)
-		assert.Equal(t, user.UserId(0), req.UserId)
 		assert.Empty(t, req.DueDate)
 	})
 }
@@ -62,3 +58,58 @@ func TestChangeStatusRequest(t *testing.T) {
 		assert.Equal(t, task.TaskStatus(""), req.NewStatus)
 	})
 }
+
+func TestListTasksQuery(t *testing.T) {
+	t.Run("Valid request", func(t *testing.T) {
+		req := request.ListTasksQuery{
+			Tag:      "work",
+			Priority: task.PriorityHigh,
+		}
+		assert.Equal(t, "work", req.Tag)
+		assert.Equal(t, task.PriorityHigh, req.Priority)
+	})
+
+	t.Run("Empty query", func(t *testing.T) {
+		req := request.ListTasksQuery{}
+		assert.Empty(t, req.Tag)
+		assert.Equal(t, task.Priority(""), req.Priority)
+	})
+}
+
+func TestParseTaskPatch(t *testing.T) {
+	t.Run("Valid request", func(t *testing.T) {
+		patch, err := request.ParseTaskPatch([]byte(`{"name":"renamed","tags":["a","b"]}`))
+		assert.NoError(t, err)
+		assert.Equal(t, "renamed", *patch.Name)
+		assert.Equal(t, []string{"a", "b"}, *patch.Tags)
+		assert.Nil(t, patch.Description)
+		assert.Nil(t, patch.Priority)
+	})
+
+	t.Run("Null resets field", func(t *testing.T) {
+		patch, err := request.ParseTaskPatch([]byte(`{"description":null,"priority":null,"estimate_minutes":null}`))
+		assert.NoError(t, err)
+		assert.Equal(t, "", *patch.Description)
+		assert.Equal(t, task.PriorityMedium, *patch.Priority)
+		assert.Equal(t, 0, *patch.EstimateMinutes)
+	})
+
+	t.Run("Non editable field", func(t *testing.T) {
+		_, err := request.ParseTaskPatch([]byte(`{"status":"完了"}`))
+		var validationErr *task.ValidationError
+		assert.ErrorAs(t, err, &validationErr)
+		assert.Equal(t, "status", validationErr.Field)
+	})
+
+	t.Run("Invalid type", func(t *testing.T) {
+		_, err := request.ParseTaskPatch([]byte(`{"estimate_minutes":"ten"}`))
+		var validationErr *task.ValidationError
+		assert.ErrorAs(t, err, &validationErr)
+		assert.Equal(t, "estimate_minutes", validationErr.Field)
+	})
+
+	t.Run("Not an object", func(t *testing.T) {
+		_, err := request.ParseTaskPatch([]byte(`[1]`))
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,9 @@
+package request
+
+import "github.com/fuki01/onion-architecture/domain/webhook"
+
+type CreateWebhookRequest struct {
+	URL        string              `json:"url" binding:"required,url"`
+	Secret     string              `json:"secret"`
+	EventTypes []webhook.EventType `json:"event_types" binding:"required,min=1,dive,oneof=task.created task.extended task.completed"`
+}
@@ -0,0 +1,19 @@
+package request
+
+import (
+	"github.com/fuki01/onion-architecture/domain/user"
+	"github.com/fuki01/onion-architecture/domain/workspace"
+)
+
+type CreateWorkspaceRequest struct {
+	Name string `json:"name" binding:"required,max=100"`
+}
+
+type AddMemberRequest struct {
+	UserID user.UserId    `json:"user_id" binding:"required"`
+	Role   workspace.Role `json:"role" binding:"required,oneof=owner admin member viewer"`
+}
+
+type ChangeRoleRequest struct {
+	Role workspace.Role `json:"role" binding:"required,oneof=owner admin member viewer"`
+}
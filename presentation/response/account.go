@@ -0,0 +1,25 @@
+package response
+
+import (
+	"time"
+
+	"github.com/fuki01/onion-architecture/usecase"
+)
+
+type TokenResponse struct {
+	AccessToken           string    `json:"access_token"`
+	TokenType             string    `json:"token_type"`
+	ExpiresAt             time.Time `json:"expires_at"`
+	RefreshToken          string    `json:"refresh_token"`
+	RefreshTokenExpiresAt time.Time `json:"refresh_token_expires_at"`
+}
+
+func NewTokenResponse(t *usecase.Tokens) TokenResponse {
+	return TokenResponse{
+		AccessToken:           t.AccessToken,
+		TokenType:             "Bearer",
+		ExpiresAt:             t.AccessTokenExpiresAt,
+		RefreshToken:          t.RefreshToken,
+		RefreshTokenExpiresAt: t.RefreshTokenExpiresAt,
+	}
+}
@@ -0,0 +1,16 @@
+package response
+
+import "github.com/fuki01/onion-architecture/domain/user"
+
+// CreateApiKeyResponse は作成直後に一度だけキーを返す
+type CreateApiKeyResponse struct {
+	*user.ApiKey
+	Key string `json:"key"`
+}
+
+func NewCreateApiKeyResponse(key string, k *user.ApiKey) CreateApiKeyResponse {
+	return CreateApiKeyResponse{
+		ApiKey: k,
+		Key:    key,
+	}
+}
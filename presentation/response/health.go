@@ -0,0 +1,32 @@
+package response
+
+const (
+	HealthOK   = "ok"
+	HealthFail = "fail"
+)
+
+// HealthResponse はサービスと依存先の状態
+type HealthResponse struct {
+	Status string                         `json:"status"`
+	Checks map[string]HealthCheckResponse `json:"checks,omitempty"`
+}
+
+// HealthCheckResponse は1つのチェックの結果
+type HealthCheckResponse struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// NewHealthResponse はチェックごとの結果から全体の状態を作る。1つでも失敗していれば失敗とする
+func NewHealthResponse(results map[string]error) HealthResponse {
+	res := HealthResponse{Status: HealthOK, Checks: make(map[string]HealthCheckResponse, len(results))}
+	for name, err := range results {
+		if err != nil {
+			res.Status = HealthFail
+			res.Checks[name] = HealthCheckResponse{Status: HealthFail, Error: err.Error()}
+			continue
+		}
+		res.Checks[name] = HealthCheckResponse{Status: HealthOK}
+	}
+	return res
+}
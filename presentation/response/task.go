@@ -1,6 +1,10 @@
 package response
 
-import "github.com/fuki01/onion-architecture/domain/task"
+import (
+	"github.com/fuki01/onion-architecture/domain/task"
+	"github.com/fuki01/onion-architecture/domain/user"
+	"github.com/fuki01/onion-architecture/domain/workspace"
+)
 
 type CreateTaskResponse struct {
 	TaskID task.TaskId `json:"task_id"`
@@ -14,11 +18,56 @@ type ErrorResponse struct {
 	Error string `json:"error"`
 }
 
-
 type GetTaskResponse struct {
-	TaskID   task.TaskId `json:"task_id"`
-	Name     string      `json:"name"`
-	DueDate  string      `json:"due_date"`
-	Status   string      `json:"status"`
-	DelayCnt int         `json:"delay_cnt"`
+	ID              task.TaskId            `json:"id"`
+	Name            string                 `json:"name"`
+	UserID          user.UserId            `json:"user_id"`
+	Description     string                 `json:"description"`
+	DueDate         string                 `json:"due_date"`
+	Status          string                 `json:"status"`
+	Priority        string                 `json:"priority"`
+	EstimateMinutes int                    `json:"estimate_minutes"`
+	Tags            []string               `json:"tags"`
+	DelayCount      int                    `json:"delay_count"`
+	ParentID        *task.TaskId           `json:"parent_id"`
+	Position        int                    `json:"position"`
+	Progress        int                    `json:"progress"`
+	Recurrence      string                 `json:"recurrence"`
+	Occurrence      int                    `json:"occurrence"`
+	IsOverdue       bool                   `json:"is_overdue"`
+	CreatedBy       user.UserId            `json:"created_by"`
+	WorkspaceID     *workspace.WorkspaceId `json:"workspace_id"`
+	AssigneeID      *user.UserId           `json:"assignee_id"`
+}
+
+func NewGetTaskResponse(t *task.Task) GetTaskResponse {
+	return GetTaskResponse{
+		ID:              t.Id,
+		Name:            t.Name,
+		UserID:          t.CreatedBy,
+		Description:     t.Description,
+		DueDate:         t.DueDate,
+		Status:          string(t.Status),
+		Priority:        string(t.Priority),
+		EstimateMinutes: t.EstimateMinutes,
+		Tags:            t.TagNames(),
+		DelayCount:      t.DelayCount,
+		ParentID:        t.ParentId,
+		Position:        t.Position,
+		Progress:        t.Progress(),
+		Recurrence:      t.Recurrence,
+		Occurrence:      t.Occurrence,
+		IsOverdue:       t.Overdue,
+		CreatedBy:       t.CreatedBy,
+		WorkspaceID:     t.WorkspaceId,
+		AssigneeID:      t.AssigneeId,
+	}
+}
+
+func NewGetTaskResponses(tasks []*task.Task) []GetTaskResponse {
+	res := make([]GetTaskResponse, 0, len(tasks))
+	for _, t := range tasks {
+		res = append(res, NewGetTaskResponse(t))
+	}
+	return res
 }
@@ -0,0 +1,16 @@
+package response
+
+import "github.com/fuki01/onion-architecture/domain/webhook"
+
+// CreateWebhookResponse は登録直後に一度だけ署名用のシークレットを返す
+type CreateWebhookResponse struct {
+	*webhook.Subscription
+	Secret string `json:"secret"`
+}
+
+func NewCreateWebhookResponse(s *webhook.Subscription) CreateWebhookResponse {
+	return CreateWebhookResponse{
+		Subscription: s,
+		Secret:       s.Secret,
+	}
+}
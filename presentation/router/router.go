@@ -3,22 +3,118 @@ package router
 import (
 	"github.com/gin-gonic/gin"
 
+	"github.com/fuki01/onion-architecture/domain/user"
 	"github.com/fuki01/onion-architecture/presentation/controller"
+	"github.com/fuki01/onion-architecture/presentation/middleware"
 )
 
-func SetupRouter(taskController *controller.TaskController) *gin.Engine {
-	router := gin.Default()
+// RateLimiters はルートのグループごとのレート制限
+type RateLimiters struct {
+	// Client はAPI全体にクライアントごとにかける
+	Client gin.HandlerFunc
+	// Auth は認証なしで受け付ける登録とログインにかける
+	Auth gin.HandlerFunc
+	// API は認証の後にユーザーごとにかける
+	API gin.HandlerFunc
+}
+
+func SetupRouter(trace gin.HandlerFunc, instrument gin.HandlerFunc, limits RateLimiters, authenticate gin.HandlerFunc, readYourWrites gin.HandlerFunc, idempotent gin.HandlerFunc, taskController *controller.TaskController, notificationController *controller.NotificationController, webhookController *controller.WebhookController, accountController *controller.AccountController, apiKeyController *controller.ApiKeyController, workspaceController *controller.WorkspaceController, healthController *controller.HealthController) *gin.Engine {
+	// パニックもアクセスログ、トレース、メトリクスに記録されるよう、Recoveryは最も内側に置く
+	router := gin.New()
+	router.Use(middleware.RequestID(), trace, instrument, middleware.AccessLog(), middleware.Recovery())
+
+	// ロードバランサーが確認できるよう、ヘルスチェックはAPIと同じアドレスで公開する
+	router.GET("/healthz", healthController.Live)
+	router.GET("/readyz", healthController.Ready)
 
-	v1 := router.Group("/api/v1")
+	v1 := router.Group("/api/v1", limits.Client)
 	{
-		tasks := v1.Group("/tasks")
+		// 登録とログインは認証なしで受け付けるため、総当たりを防ぐよう厳しく制限する
+		// ローカルアカウントが無効な場合は登録しない
+		if accountController != nil {
+			account := v1.Group("/auth", limits.Auth)
+			account.POST("/register", accountController.Register)
+			account.POST("/login", accountController.Login)
+			account.POST("/refresh", accountController.Refresh)
+			account.POST("/logout", accountController.Logout)
+			account.PUT("/password", authenticate, middleware.RequireSession(), accountController.ChangePassword)
+		}
+
+		// それ以外のAPIはすべて認証を必要とする
+		// 書き込んだユーザーのその後の読み込みはリードレプリカではなくプライマリから行う
+		authorized := v1.Group("", authenticate, limits.API, readYourWrites)
+
+		// タスクのAPIはAPIキーのスコープで操作を制限する
+		read := middleware.RequireScope(user.ScopeTasksRead)
+		write := middleware.RequireScope(user.ScopeTasksWrite)
+		// タスクを作成するAPIは、再送されたリクエストでタスクが重複しないようIdempotency-Keyを受け付ける
+		tasks := authorized.Group("/tasks")
+		{
+			tasks.POST("", write, idempotent, taskController.CreateTask)
+			tasks.GET("/:id", read, taskController.GetTask)
+			tasks.PUT("/:id/extend", write, taskController.ExtendDueDate)
+			tasks.PUT("/:id/status", write, taskController.ChangeStatus)
+			tasks.PATCH("/:id", write, taskController.UpdateTask)
+			tasks.POST("/:id/subtasks", write, idempotent, taskController.AddSubtask)
+			tasks.GET("/:id/subtasks", read, taskController.GetSubtasks)
+			tasks.PUT("/:id/subtasks/order", write, taskController.ReorderSubtasks)
+			tasks.POST("/:id/dependencies", write, taskController.AddDependency)
+			tasks.GET("/:id/dependencies", read, taskController.GetDependencyChain)
+			tasks.DELETE("/:id/dependencies/:blocked_by_id", write, taskController.RemoveDependency)
+			tasks.POST("/:id/shares", write, taskController.ShareTask)
+			tasks.DELETE("/:id/shares/:user_id", write, taskController.UnshareTask)
+			tasks.PUT("/:id/assignee", write, taskController.ReassignTask)
+			tasks.GET("/:id/assignments", read, taskController.GetAssignmentHistory)
+		}
+		authorized.GET("/workspaces/:id/tasks", read, taskController.GetWorkspaceTasks)
+		authorized.GET("/users/:id/tasks/created", read, taskController.GetTask)
+		authorized.GET("/users/:id/tasks/assigned", read, taskController.GetAssignedTasks)
+
+		// タスク以外の設定はログインしたユーザーのみ操作できる
+		session := authorized.Group("", middleware.RequireSession())
+
+		apiKeys := session.Group("/api_keys")
+		{
+			apiKeys.POST("", apiKeyController.CreateApiKey)
+			apiKeys.GET("", apiKeyController.GetApiKeys)
+			apiKeys.DELETE("/:id", apiKeyController.RevokeApiKey)
+		}
+
+		workspaces := session.Group("/workspaces")
+		{
+			workspaces.POST("", workspaceController.CreateWorkspace)
+			workspaces.GET("", workspaceController.GetWorkspaces)
+			workspaces.GET("/:id", workspaceController.GetWorkspace)
+			workspaces.POST("/:id/members", workspaceController.AddMember)
+			workspaces.PUT("/:id/members/:user_id", workspaceController.ChangeRole)
+			workspaces.DELETE("/:id/members/:user_id", workspaceController.RemoveMember)
+		}
+
+		users := session.Group("/users")
 		{
-			tasks.POST("", taskController.CreateTask)
-			tasks.GET("/:id", taskController.GetTask)
-			tasks.PUT("/:id/extend", taskController.ExtendDueDate)
-			tasks.PUT("/:id/status", taskController.ChangeStatus)
+			users.GET("/:id/notification_settings", notificationController.GetSettings)
+			users.PUT("/:id/notification_settings", notificationController.UpdateSettings)
+		}
+
+		webhooks := session.Group("/webhooks")
+		{
+			webhooks.POST("", webhookController.CreateWebhook)
+			webhooks.GET("", webhookController.GetWebhooks)
+			webhooks.DELETE("/:id", webhookController.DeleteWebhook)
+			webhooks.GET("/:id/deliveries", webhookController.GetDeliveries)
+			webhooks.POST("/:id/deliveries/:delivery_id/redeliver", webhookController.Redeliver)
 		}
 	}
 
 	return router
 }
+
+// SetupMetricsRouter はメトリクスを公開するルーティングを設定する
+// 内部の情報を含むため、APIとは別の内部向けのアドレスで待ち受ける
+func SetupMetricsRouter(metricsController *controller.MetricsController) *gin.Engine {
+	router := gin.New()
+	router.Use(middleware.Recovery())
+	router.GET("/metrics", metricsController.Prometheus)
+	router.GET("/metrics/db", metricsController.DBStats)
+	return router
+}
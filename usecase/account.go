@@ -0,0 +1,245 @@
+package usecase
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fuki01/onion-architecture/domain/repository"
+	"github.com/fuki01/onion-architecture/domain/user"
+)
+
+// PasswordHasher はパスワードのハッシュ化と照合を行うポート
+type PasswordHasher interface {
+	Hash(password string) (string, error)
+	// Compare は一致しない場合にエラーを返す
+	Compare(hash, password string) error
+}
+
+// TokenIssuer はアクセストークンを発行するポート
+type TokenIssuer interface {
+	Issue(userId user.UserId) (token string, expiresAt time.Time, err error)
+}
+
+// Tokens はログインとトークンの再発行で返すトークンの組
+type Tokens struct {
+	AccessToken           string
+	AccessTokenExpiresAt  time.Time
+	RefreshToken          string
+	RefreshTokenExpiresAt time.Time
+}
+
+// RegisterInput はユーザー登録時の入力値
+type RegisterInput struct {
+	Name     string
+	Email    string
+	Password string
+}
+
+type AccountUsecase interface {
+	Register(input RegisterInput) (*user.User, error)
+	Login(email, password string) (*Tokens, error)
+	// Refresh はリフレッシュトークンを新しいものに交換し、アクセストークンを再発行する
+	Refresh(refreshToken string) (*Tokens, error)
+	// Logout はリフレッシュトークンと同じログインから発行されたトークンを全て失効させる
+	Logout(refreshToken string) error
+	// ChangePassword はパスワードを変更し、全てのリフレッシュトークンを失効させる
+	ChangePassword(actor user.UserId, currentPassword, newPassword string) error
+}
+
+const DefaultRefreshTokenTTL = 30 * 24 * time.Hour
+
+type accountUsecase struct {
+	userRepository         repository.UserRepository
+	refreshTokenRepository repository.RefreshTokenRepository
+	hasher                 PasswordHasher
+	issuer                 TokenIssuer
+	lockout                user.LockoutPolicy
+	refreshTTL             time.Duration
+	now                    func() time.Time
+
+	// 存在しないユーザーでも照合を行い、応答時間からの推測を防ぐ
+	dummyHashOnce sync.Once
+	dummyHash     string
+}
+
+func NewAccountUsecase(userRepository repository.UserRepository, refreshTokenRepository repository.RefreshTokenRepository, hasher PasswordHasher, issuer TokenIssuer, opts ...AccountUsecaseOption) AccountUsecase {
+	au := &accountUsecase{
+		userRepository:         userRepository,
+		refreshTokenRepository: refreshTokenRepository,
+		hasher:                 hasher,
+		issuer:                 issuer,
+		lockout:                user.DefaultLockoutPolicy,
+		refreshTTL:             DefaultRefreshTokenTTL,
+		now:                    time.Now,
+	}
+	for _, opt := range opts {
+		opt(au)
+	}
+	return au
+}
+
+// ユーザーを登録する
+func (au *accountUsecase) Register(input RegisterInput) (*user.User, error) {
+	if err := user.ValidatePassword(input.Password); err != nil {
+		return nil, err
+	}
+	u, err := user.NewAccount(input.Name, input.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := au.userRepository.FindByEmail(u.Email); err == nil {
+		return nil, user.ErrEmailTaken
+	} else if !errors.Is(err, repository.ErrNotFound) {
+		return nil, err
+	}
+
+	if u.PasswordHash, err = au.hasher.Hash(input.Password); err != nil {
+		return nil, err
+	}
+	if err := au.userRepository.Insert(u); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// メールアドレスとパスワードでログインする
+// 失敗が続いた場合は一定時間ロックする
+func (au *accountUsecase) Login(email, password string) (*Tokens, error) {
+	now := au.now()
+	u, err := au.userRepository.FindByEmail(strings.ToLower(strings.TrimSpace(email)))
+	if errors.Is(err, repository.ErrNotFound) {
+		au.hasher.Compare(au.dummyPasswordHash(), password)
+		return nil, user.ErrInvalidCredentials
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if u.IsLocked(now) {
+		return nil, user.ErrAccountLocked
+	}
+	if u.PasswordHash == "" || au.hasher.Compare(u.PasswordHash, password) != nil {
+		// 同時の失敗やパスワード変更を上書きしないよう、失敗回数とロックのみを更新する
+		if _, err := au.userRepository.RecordLoginFailure(u.Id, now, au.lockout); err != nil {
+			return nil, err
+		}
+		return nil, user.ErrInvalidCredentials
+	}
+
+	if u.FailedLogins > 0 || u.LockedUntil != nil {
+		if err := au.userRepository.ResetLoginFailures(u.Id); err != nil {
+			return nil, err
+		}
+	}
+	return au.issueTokens(u.Id, "", now)
+}
+
+// リフレッシュトークンを交換する
+// 失効済みのトークンが使われた場合は漏洩とみなし、同じログインのトークンを全て失効させる
+func (au *accountUsecase) Refresh(refreshToken string) (*Tokens, error) {
+	now := au.now()
+	current, err := au.refreshTokenRepository.FindByHash(user.HashRefreshToken(refreshToken))
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, user.ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if current.RevokedAt != nil {
+		return nil, au.revokeReused(current, now)
+	}
+	if !current.IsActive(now) {
+		return nil, user.ErrInvalidRefreshToken
+	}
+
+	u, err := au.userRepository.FindById(current.UserId)
+	if err != nil {
+		return nil, err
+	}
+	if u.IsLocked(now) {
+		return nil, user.ErrAccountLocked
+	}
+
+	current.Revoke(now)
+	if err := au.refreshTokenRepository.Revoke(current); err != nil {
+		// 同時に交換された場合も再利用として扱う
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, au.revokeReused(current, now)
+		}
+		return nil, err
+	}
+	return au.issueTokens(u.Id, current.FamilyId, now)
+}
+
+// ログアウトする
+// 不明なトークンの場合も成功とする
+func (au *accountUsecase) Logout(refreshToken string) error {
+	current, err := au.refreshTokenRepository.FindByHash(user.HashRefreshToken(refreshToken))
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return au.refreshTokenRepository.RevokeFamily(current.FamilyId, au.now())
+}
+
+// パスワードを変更する
+func (au *accountUsecase) ChangePassword(actor user.UserId, currentPassword, newPassword string) error {
+	u, err := au.userRepository.FindById(actor)
+	if err != nil {
+		return err
+	}
+	if u.PasswordHash == "" || au.hasher.Compare(u.PasswordHash, currentPassword) != nil {
+		return user.ErrInvalidCredentials
+	}
+	if err := user.ValidatePassword(newPassword); err != nil {
+		return err
+	}
+
+	hash, err := au.hasher.Hash(newPassword)
+	if err != nil {
+		return err
+	}
+	return au.userRepository.UpdatePasswordHash(u.Id, hash, au.now())
+}
+
+// アクセストークンとリフレッシュトークンを発行する
+func (au *accountUsecase) issueTokens(userId user.UserId, familyId string, now time.Time) (*Tokens, error) {
+	access, accessExpiresAt, err := au.issuer.Issue(userId)
+	if err != nil {
+		return nil, err
+	}
+	refresh, record, err := user.NewRefreshToken(userId, familyId, now, au.refreshTTL)
+	if err != nil {
+		return nil, err
+	}
+	if err := au.refreshTokenRepository.Insert(record); err != nil {
+		return nil, err
+	}
+	return &Tokens{
+		AccessToken:           access,
+		AccessTokenExpiresAt:  accessExpiresAt,
+		RefreshToken:          refresh,
+		RefreshTokenExpiresAt: record.ExpiresAt,
+	}, nil
+}
+
+// 再利用されたトークンと同じログインのトークンを全て失効させる
+func (au *accountUsecase) revokeReused(t *user.RefreshToken, now time.Time) error {
+	if err := au.refreshTokenRepository.RevokeFamily(t.FamilyId, now); err != nil {
+		return err
+	}
+	return user.ErrInvalidRefreshToken
+}
+
+func (au *accountUsecase) dummyPasswordHash() string {
+	au.dummyHashOnce.Do(func() {
+		au.dummyHash, _ = au.hasher.Hash("dummy-password")
+	})
+	return au.dummyHash
+}
@@ -0,0 +1,414 @@
+package usecase_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/fuki01/onion-architecture/domain/repository"
+	"github.com/fuki01/onion-architecture/domain/user"
+	"github.com/fuki01/onion-architecture/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockUserRepository struct {
+	mock.Mock
+}
+
+func (m *MockUserRepository) FindById(id user.UserId) (*user.User, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*user.User), args.Error(1)
+}
+
+func (m *MockUserRepository) FindByEmail(email string) (*user.User, error) {
+	args := m.Called(email)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*user.User), args.Error(1)
+}
+
+func (m *MockUserRepository) Insert(u *user.User) error {
+	args := m.Called(u)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) UpdatePasswordHash(id user.UserId, hash string, at time.Time) error {
+	args := m.Called(id, hash, at)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) RecordLoginFailure(id user.UserId, now time.Time, policy user.LockoutPolicy) (*user.User, error) {
+	args := m.Called(id, now, policy)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*user.User), args.Error(1)
+}
+
+func (m *MockUserRepository) ResetLoginFailures(id user.UserId) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+type MockRefreshTokenRepository struct {
+	mock.Mock
+}
+
+func (m *MockRefreshTokenRepository) FindByHash(hash string) (*user.RefreshToken, error) {
+	args := m.Called(hash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*user.RefreshToken), args.Error(1)
+}
+
+func (m *MockRefreshTokenRepository) Insert(t *user.RefreshToken) error {
+	args := m.Called(t)
+	return args.Error(0)
+}
+
+func (m *MockRefreshTokenRepository) Revoke(t *user.RefreshToken) error {
+	args := m.Called(t)
+	return args.Error(0)
+}
+
+func (m *MockRefreshTokenRepository) RevokeFamily(familyId string, at time.Time) error {
+	args := m.Called(familyId, at)
+	return args.Error(0)
+}
+
+// plainHasher はテスト用に接頭辞を付けるだけのハッシュ
+type plainHasher struct{}
+
+func (plainHasher) Hash(password string) (string, error) {
+	return "hashed:" + password, nil
+}
+
+func (plainHasher) Compare(hash, password string) error {
+	if hash != "hashed:"+password {
+		return errors.New("mismatch")
+	}
+	return nil
+}
+
+type stubIssuer struct {
+	expiresAt time.Time
+}
+
+func (s stubIssuer) Issue(userId user.UserId) (string, time.Time, error) {
+	return "access-token", s.expiresAt, nil
+}
+
+func TestRegister(t *testing.T) {
+	createUsecase := func(users *MockUserRepository) usecase.AccountUsecase {
+		return usecase.NewAccountUsecase(users, new(MockRefreshTokenRepository), plainHasher{}, stubIssuer{})
+	}
+
+	t.Run("success", func(t *testing.T) {
+		// モック作成
+		users := new(MockUserRepository)
+		users.On("FindByEmail", "taro@example.com").Return(nil, repository.ErrNotFound)
+		users.On("Insert", mock.MatchedBy(func(u *user.User) bool {
+			return u.Email == "taro@example.com" && u.PasswordHash == "hashed:correct horse"
+		})).Return(nil)
+		usecase := createUsecase(users)
+
+		// 検証
+		u, err := usecase.Register(registerInput("太郎", " Taro@Example.com ", "correct horse"))
+		require.NoError(t, err)
+		assert.Equal(t, "taro@example.com", u.Email)
+		users.AssertExpectations(t)
+	})
+
+	t.Run("email taken", func(t *testing.T) {
+		// モック作成
+		users := new(MockUserRepository)
+		users.On("FindByEmail", "taro@example.com").Return(&user.User{Id: 1}, nil)
+		usecase := createUsecase(users)
+
+		// 検証
+		_, err := usecase.Register(registerInput("太郎", "taro@example.com", "correct horse"))
+		assert.ErrorIs(t, err, user.ErrEmailTaken)
+		users.AssertNotCalled(t, "Insert", mock.Anything)
+	})
+
+	t.Run("weak password", func(t *testing.T) {
+		// モック作成
+		users := new(MockUserRepository)
+		usecase := createUsecase(users)
+
+		// 検証
+		_, err := usecase.Register(registerInput("太郎", "taro@example.com", "short"))
+		assert.ErrorIs(t, err, user.ErrWeakPassword)
+	})
+
+	t.Run("invalid email", func(t *testing.T) {
+		// モック作成
+		users := new(MockUserRepository)
+		usecase := createUsecase(users)
+
+		// 検証
+		_, err := usecase.Register(registerInput("太郎", "taro", "correct horse"))
+		assert.ErrorIs(t, err, user.ErrInvalidEmail)
+	})
+}
+
+func registerInput(name, email, password string) usecase.RegisterInput {
+	return usecase.RegisterInput{Name: name, Email: email, Password: password}
+}
+
+func TestLogin(t *testing.T) {
+	now := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	policy := user.LockoutPolicy{MaxFailures: 3, Duration: 15 * time.Minute}
+	newUser := func() *user.User {
+		return &user.User{Id: 1, Name: "太郎", Email: "taro@example.com", PasswordHash: "hashed:correct horse"}
+	}
+	createUsecase := func(users *MockUserRepository, tokens *MockRefreshTokenRepository, at *time.Time) usecase.AccountUsecase {
+		return usecase.NewAccountUsecase(users, tokens, plainHasher{}, stubIssuer{expiresAt: now.Add(15 * time.Minute)},
+			usecase.WithLockoutPolicy(policy),
+			usecase.WithRefreshTokenTTL(24*time.Hour),
+			usecase.WithAccountClock(func() time.Time { return *at }))
+	}
+
+	t.Run("success", func(t *testing.T) {
+		// モック作成
+		var stored *user.RefreshToken
+		users := new(MockUserRepository)
+		users.On("FindByEmail", "taro@example.com").Return(newUser(), nil)
+		tokens := new(MockRefreshTokenRepository)
+		tokens.On("Insert", mock.AnythingOfType("*user.RefreshToken")).Run(func(args mock.Arguments) {
+			stored = args.Get(0).(*user.RefreshToken)
+		}).Return(nil)
+		usecase := createUsecase(users, tokens, &now)
+
+		// ログイン
+		result, err := usecase.Login("Taro@example.com", "correct horse")
+
+		// 検証
+		require.NoError(t, err)
+		assert.Equal(t, "access-token", result.AccessToken)
+		assert.Equal(t, now.Add(24*time.Hour), result.RefreshTokenExpiresAt)
+		assert.NotEmpty(t, stored.FamilyId)
+		assert.Equal(t, user.HashRefreshToken(result.RefreshToken), stored.TokenHash)
+		assert.NotEqual(t, result.RefreshToken, stored.TokenHash)
+		users.AssertNotCalled(t, "ResetLoginFailures", mock.Anything)
+	})
+
+	t.Run("unknown email", func(t *testing.T) {
+		// モック作成
+		users := new(MockUserRepository)
+		users.On("FindByEmail", "jiro@example.com").Return(nil, repository.ErrNotFound)
+		usecase := createUsecase(users, new(MockRefreshTokenRepository), &now)
+
+		// 検証
+		_, err := usecase.Login("jiro@example.com", "correct horse")
+		assert.ErrorIs(t, err, user.ErrInvalidCredentials)
+	})
+
+	t.Run("lockout after repeated failures", func(t *testing.T) {
+		// 初期値の設定
+		target := newUser()
+		at := now
+
+		// モック作成
+		users := new(MockUserRepository)
+		users.On("FindByEmail", "taro@example.com").Return(target, nil)
+		users.On("RecordLoginFailure", user.UserId(1), now, policy).Run(func(args mock.Arguments) {
+			target.RecordLoginFailure(now, policy)
+		}).Return(target, nil)
+		users.On("ResetLoginFailures", user.UserId(1)).Run(func(args mock.Arguments) {
+			target.FailedLogins = 0
+			target.LockedUntil = nil
+		}).Return(nil)
+		tokens := new(MockRefreshTokenRepository)
+		tokens.On("Insert", mock.Anything).Return(nil)
+		usecase := createUsecase(users, tokens, &at)
+
+		// 上限まで失敗するとロックされる
+		for i := 0; i < policy.MaxFailures; i++ {
+			_, err := usecase.Login("taro@example.com", "wrong")
+			assert.ErrorIs(t, err, user.ErrInvalidCredentials)
+		}
+		require.NotNil(t, target.LockedUntil)
+
+		// ロック中は正しいパスワードでもログインできない
+		_, err := usecase.Login("taro@example.com", "correct horse")
+		assert.ErrorIs(t, err, user.ErrAccountLocked)
+
+		// ロック期間が過ぎるとログインでき、失敗回数が解除される
+		at = now.Add(policy.Duration)
+		_, err = usecase.Login("taro@example.com", "correct horse")
+		assert.NoError(t, err)
+		assert.Nil(t, target.LockedUntil)
+		assert.Equal(t, 0, target.FailedLogins)
+	})
+}
+
+func TestRefresh(t *testing.T) {
+	now := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	createUsecase := func(users *MockUserRepository, tokens *MockRefreshTokenRepository) usecase.AccountUsecase {
+		return usecase.NewAccountUsecase(users, tokens, plainHasher{}, stubIssuer{},
+			usecase.WithAccountClock(func() time.Time { return now }))
+	}
+	newToken := func() *user.RefreshToken {
+		return &user.RefreshToken{Id: 1, UserId: 1, FamilyId: "family", TokenHash: user.HashRefreshToken("old"), ExpiresAt: now.Add(time.Hour)}
+	}
+
+	t.Run("rotation", func(t *testing.T) {
+		// 初期値の設定
+		current := newToken()
+
+		// モック作成
+		users := new(MockUserRepository)
+		users.On("FindById", user.UserId(1)).Return(&user.User{Id: 1}, nil)
+		tokens := new(MockRefreshTokenRepository)
+		tokens.On("FindByHash", user.HashRefreshToken("old")).Return(current, nil)
+		tokens.On("Revoke", current).Return(nil)
+		tokens.On("Insert", mock.MatchedBy(func(next *user.RefreshToken) bool {
+			return next.FamilyId == "family" && next.UserId == 1
+		})).Return(nil)
+		usecase := createUsecase(users, tokens)
+
+		// 交換
+		result, err := usecase.Refresh("old")
+
+		// 検証
+		require.NoError(t, err)
+		assert.NotEqual(t, "old", result.RefreshToken)
+		assert.NotNil(t, current.RevokedAt)
+		tokens.AssertExpectations(t)
+	})
+
+	t.Run("reuse revokes family", func(t *testing.T) {
+		// 初期値の設定
+		current := newToken()
+		revokedAt := now.Add(-time.Minute)
+		current.RevokedAt = &revokedAt
+
+		// モック作成
+		tokens := new(MockRefreshTokenRepository)
+		tokens.On("FindByHash", user.HashRefreshToken("old")).Return(current, nil)
+		tokens.On("RevokeFamily", "family", now).Return(nil)
+		usecase := createUsecase(new(MockUserRepository), tokens)
+
+		// 検証
+		_, err := usecase.Refresh("old")
+		assert.ErrorIs(t, err, user.ErrInvalidRefreshToken)
+		tokens.AssertExpectations(t)
+		tokens.AssertNotCalled(t, "Insert", mock.Anything)
+	})
+
+	t.Run("concurrent reuse revokes family", func(t *testing.T) {
+		// 初期値の設定
+		current := newToken()
+
+		// モック作成
+		users := new(MockUserRepository)
+		users.On("FindById", user.UserId(1)).Return(&user.User{Id: 1}, nil)
+		tokens := new(MockRefreshTokenRepository)
+		tokens.On("FindByHash", user.HashRefreshToken("old")).Return(current, nil)
+		tokens.On("Revoke", current).Return(repository.ErrNotFound)
+		tokens.On("RevokeFamily", "family", now).Return(nil)
+		usecase := createUsecase(users, tokens)
+
+		// 検証
+		_, err := usecase.Refresh("old")
+		assert.ErrorIs(t, err, user.ErrInvalidRefreshToken)
+		tokens.AssertNotCalled(t, "Insert", mock.Anything)
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		// 初期値の設定
+		current := newToken()
+		current.ExpiresAt = now
+
+		// モック作成
+		tokens := new(MockRefreshTokenRepository)
+		tokens.On("FindByHash", user.HashRefreshToken("old")).Return(current, nil)
+		usecase := createUsecase(new(MockUserRepository), tokens)
+
+		// 検証
+		_, err := usecase.Refresh("old")
+		assert.ErrorIs(t, err, user.ErrInvalidRefreshToken)
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		// モック作成
+		tokens := new(MockRefreshTokenRepository)
+		tokens.On("FindByHash", mock.Anything).Return(nil, repository.ErrNotFound)
+		usecase := createUsecase(new(MockUserRepository), tokens)
+
+		// 検証
+		_, err := usecase.Refresh("unknown")
+		assert.ErrorIs(t, err, user.ErrInvalidRefreshToken)
+	})
+}
+
+func TestLogout(t *testing.T) {
+	now := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+
+	// モック作成
+	tokens := new(MockRefreshTokenRepository)
+	tokens.On("FindByHash", user.HashRefreshToken("token")).Return(&user.RefreshToken{FamilyId: "family"}, nil)
+	tokens.On("FindByHash", user.HashRefreshToken("unknown")).Return(nil, repository.ErrNotFound)
+	tokens.On("RevokeFamily", "family", now).Return(nil)
+	usecase := usecase.NewAccountUsecase(new(MockUserRepository), tokens, plainHasher{}, stubIssuer{},
+		usecase.WithAccountClock(func() time.Time { return now }))
+
+	// 検証
+	assert.NoError(t, usecase.Logout("token"))
+	assert.NoError(t, usecase.Logout("unknown"))
+	tokens.AssertNumberOfCalls(t, "RevokeFamily", 1)
+}
+
+func TestChangePassword(t *testing.T) {
+	now := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	createUsecase := func(users *MockUserRepository, tokens *MockRefreshTokenRepository) usecase.AccountUsecase {
+		return usecase.NewAccountUsecase(users, tokens, plainHasher{}, stubIssuer{},
+			usecase.WithAccountClock(func() time.Time { return now }))
+	}
+
+	t.Run("success", func(t *testing.T) {
+		// 初期値の設定
+		target := &user.User{Id: 1, PasswordHash: "hashed:correct horse"}
+
+		// モック作成
+		users := new(MockUserRepository)
+		users.On("FindById", user.UserId(1)).Return(target, nil)
+		users.On("UpdatePasswordHash", user.UserId(1), "hashed:battery staple", now).Return(nil)
+		tokens := new(MockRefreshTokenRepository)
+		usecase := createUsecase(users, tokens)
+
+		// 検証
+		assert.NoError(t, usecase.ChangePassword(1, "correct horse", "battery staple"))
+		users.AssertExpectations(t)
+	})
+
+	t.Run("wrong current password", func(t *testing.T) {
+		// モック作成
+		users := new(MockUserRepository)
+		users.On("FindById", user.UserId(1)).Return(&user.User{Id: 1, PasswordHash: "hashed:correct horse"}, nil)
+		tokens := new(MockRefreshTokenRepository)
+		usecase := createUsecase(users, tokens)
+
+		// 検証
+		assert.ErrorIs(t, usecase.ChangePassword(1, "wrong", "battery staple"), user.ErrInvalidCredentials)
+		users.AssertNotCalled(t, "UpdatePasswordHash", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("weak new password", func(t *testing.T) {
+		// モック作成
+		users := new(MockUserRepository)
+		users.On("FindById", user.UserId(1)).Return(&user.User{Id: 1, PasswordHash: "hashed:correct horse"}, nil)
+		usecase := createUsecase(users, new(MockRefreshTokenRepository))
+
+		// 検証
+		assert.ErrorIs(t, usecase.ChangePassword(1, "correct horse", "short"), user.ErrWeakPassword)
+	})
+}
@@ -0,0 +1,105 @@
+package usecase
+
+import (
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/fuki01/onion-architecture/domain/repository"
+	"github.com/fuki01/onion-architecture/domain/user"
+)
+
+// CreateApiKeyInput はAPIキー作成時の入力値
+type CreateApiKeyInput struct {
+	Name      string
+	Scopes    []string
+	ExpiresAt *time.Time
+}
+
+type ApiKeyUsecase interface {
+	// CreateApiKey はキーを作成し、利用者に渡すキーを返す
+	// キーは作成時にしか取得できない
+	CreateApiKey(actor user.UserId, input CreateApiKeyInput) (string, *user.ApiKey, error)
+	GetApiKeys(actor user.UserId) ([]*user.ApiKey, error)
+	RevokeApiKey(actor user.UserId, id int) error
+	// Authenticate はキーを検証し、キーの所有者と許可されたスコープを返す
+	Authenticate(key string) (user.UserId, []user.Scope, error)
+}
+
+// 最終利用日時を更新する間隔
+// リクエストのたびに書き込まないよう、この間隔より細かい精度では記録しない
+const apiKeyLastUsedResolution = time.Minute
+
+type apiKeyUsecase struct {
+	apiKeyRepository repository.ApiKeyRepository
+	now              func() time.Time
+}
+
+func NewApiKeyUsecase(apiKeyRepository repository.ApiKeyRepository, opts ...ApiKeyUsecaseOption) ApiKeyUsecase {
+	au := &apiKeyUsecase{
+		apiKeyRepository: apiKeyRepository,
+		now:              time.Now,
+	}
+	for _, opt := range opts {
+		opt(au)
+	}
+	return au
+}
+
+// APIキーを作成する
+func (au *apiKeyUsecase) CreateApiKey(actor user.UserId, input CreateApiKeyInput) (string, *user.ApiKey, error) {
+	scopes, err := user.ParseScopes(input.Scopes)
+	if err != nil {
+		return "", nil, err
+	}
+	raw, key, err := user.NewApiKey(actor, input.Name, scopes, input.ExpiresAt, au.now())
+	if err != nil {
+		return "", nil, err
+	}
+	if err := au.apiKeyRepository.Insert(key); err != nil {
+		return "", nil, err
+	}
+	return raw, key, nil
+}
+
+// APIキーの一覧を取得する
+func (au *apiKeyUsecase) GetApiKeys(actor user.UserId) ([]*user.ApiKey, error) {
+	return au.apiKeyRepository.FindByUserId(actor)
+}
+
+// APIキーを失効させる
+// 他のユーザーのキーは存在しないものとして扱う
+func (au *apiKeyUsecase) RevokeApiKey(actor user.UserId, id int) error {
+	key, err := au.apiKeyRepository.FindById(id)
+	if err != nil {
+		return err
+	}
+	if key.UserId != actor {
+		return repository.ErrNotFound
+	}
+	key.Revoke(au.now())
+	return au.apiKeyRepository.Revoke(key)
+}
+
+// APIキーで認証する
+func (au *apiKeyUsecase) Authenticate(raw string) (user.UserId, []user.Scope, error) {
+	now := au.now()
+	key, err := au.apiKeyRepository.FindByHash(user.HashApiKey(raw))
+	if errors.Is(err, repository.ErrNotFound) {
+		return 0, nil, user.ErrInvalidApiKey
+	}
+	if err != nil {
+		return 0, nil, err
+	}
+	if !key.IsActive(now) {
+		return 0, nil, user.ErrInvalidApiKey
+	}
+
+	// 最終利用日時の記録に失敗しても認証は失敗させない
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= apiKeyLastUsedResolution {
+		if err := au.apiKeyRepository.TouchLastUsed(key.Id, now); err != nil {
+			slog.Warn("failed to record last use of api key", slog.Int("api_key_id", int(key.Id)), slog.Any("error", err))
+		}
+	}
+	return key.UserId, key.Scopes, nil
+}
@@ -0,0 +1,191 @@
+package usecase_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fuki01/onion-architecture/domain/repository"
+	"github.com/fuki01/onion-architecture/domain/user"
+	"github.com/fuki01/onion-architecture/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockApiKeyRepository struct {
+	mock.Mock
+}
+
+func (m *MockApiKeyRepository) FindById(id int) (*user.ApiKey, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*user.ApiKey), args.Error(1)
+}
+
+func (m *MockApiKeyRepository) FindByHash(hash string) (*user.ApiKey, error) {
+	args := m.Called(hash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*user.ApiKey), args.Error(1)
+}
+
+func (m *MockApiKeyRepository) FindByUserId(userId user.UserId) ([]*user.ApiKey, error) {
+	args := m.Called(userId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*user.ApiKey), args.Error(1)
+}
+
+func (m *MockApiKeyRepository) Insert(k *user.ApiKey) error {
+	args := m.Called(k)
+	return args.Error(0)
+}
+
+func (m *MockApiKeyRepository) Revoke(k *user.ApiKey) error {
+	args := m.Called(k)
+	return args.Error(0)
+}
+
+func (m *MockApiKeyRepository) TouchLastUsed(id int, at time.Time) error {
+	args := m.Called(id, at)
+	return args.Error(0)
+}
+
+func TestApiKey(t *testing.T) {
+	now := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	createUsecase := func(keys *MockApiKeyRepository) usecase.ApiKeyUsecase {
+		return usecase.NewApiKeyUsecase(keys, usecase.WithApiKeyClock(func() time.Time { return now }))
+	}
+
+	t.Run("create and authenticate", func(t *testing.T) {
+		// モック作成
+		var stored *user.ApiKey
+		keys := new(MockApiKeyRepository)
+		keys.On("Insert", mock.AnythingOfType("*user.ApiKey")).Run(func(args mock.Arguments) {
+			stored = args.Get(0).(*user.ApiKey)
+			stored.Id = 1
+		}).Return(nil)
+		usecase := createUsecase(keys)
+
+		// 作成
+		raw, key, err := usecase.CreateApiKey(user.UserId(1), apiKeyInput("ci", "tasks:write", "tasks:write"))
+		require.NoError(t, err)
+
+		// 検証
+		assert.True(t, strings.HasPrefix(raw, key.Prefix+"_"))
+		assert.Equal(t, user.HashApiKey(raw), key.KeyHash)
+		assert.Equal(t, []user.Scope{user.ScopeTasksWrite}, key.Scopes)
+
+		keys.On("FindByHash", user.HashApiKey(raw)).Return(stored, nil)
+		keys.On("TouchLastUsed", 1, now).Return(nil)
+		userId, scopes, err := usecase.Authenticate(raw)
+		require.NoError(t, err)
+		assert.Equal(t, user.UserId(1), userId)
+		assert.Equal(t, []user.Scope{user.ScopeTasksWrite}, scopes)
+		keys.AssertExpectations(t)
+	})
+
+	t.Run("invalid scope", func(t *testing.T) {
+		// モック作成
+		keys := new(MockApiKeyRepository)
+		usecase := createUsecase(keys)
+
+		// 検証
+		_, _, err := usecase.CreateApiKey(user.UserId(1), apiKeyInput("ci", "tasks:delete"))
+		assert.ErrorIs(t, err, user.ErrInvalidScope)
+		keys.AssertNotCalled(t, "Insert", mock.Anything)
+	})
+
+	t.Run("expiry in the past", func(t *testing.T) {
+		// モック作成
+		keys := new(MockApiKeyRepository)
+		usecase := createUsecase(keys)
+		input := apiKeyInput("ci", "tasks:read")
+		expiresAt := now.Add(-time.Hour)
+		input.ExpiresAt = &expiresAt
+
+		// 検証
+		_, _, err := usecase.CreateApiKey(user.UserId(1), input)
+		assert.ErrorIs(t, err, user.ErrInvalidApiKeyTTL)
+	})
+
+	t.Run("inactive keys", func(t *testing.T) {
+		expired := now.Add(-time.Second)
+		revoked := now.Add(-time.Hour)
+		for name, key := range map[string]*user.ApiKey{
+			"expired": {Id: 1, UserId: 1, ExpiresAt: &expired},
+			"revoked": {Id: 1, UserId: 1, RevokedAt: &revoked},
+		} {
+			t.Run(name, func(t *testing.T) {
+				// モック作成
+				keys := new(MockApiKeyRepository)
+				keys.On("FindByHash", user.HashApiKey("tk_key")).Return(key, nil)
+				usecase := createUsecase(keys)
+
+				// 検証
+				_, _, err := usecase.Authenticate("tk_key")
+				assert.ErrorIs(t, err, user.ErrInvalidApiKey)
+				keys.AssertNotCalled(t, "TouchLastUsed", mock.Anything, mock.Anything)
+			})
+		}
+	})
+
+	t.Run("unknown key", func(t *testing.T) {
+		// モック作成
+		keys := new(MockApiKeyRepository)
+		keys.On("FindByHash", user.HashApiKey("tk_unknown")).Return(nil, repository.ErrNotFound)
+		usecase := createUsecase(keys)
+
+		// 検証
+		_, _, err := usecase.Authenticate("tk_unknown")
+		assert.ErrorIs(t, err, user.ErrInvalidApiKey)
+	})
+
+	t.Run("recently used key is not touched", func(t *testing.T) {
+		// モック作成
+		lastUsed := now.Add(-30 * time.Second)
+		keys := new(MockApiKeyRepository)
+		keys.On("FindByHash", user.HashApiKey("tk_key")).
+			Return(&user.ApiKey{Id: 1, UserId: 1, Scopes: []user.Scope{user.ScopeTasksRead}, LastUsedAt: &lastUsed}, nil)
+		usecase := createUsecase(keys)
+
+		// 検証
+		_, _, err := usecase.Authenticate("tk_key")
+		require.NoError(t, err)
+		keys.AssertNotCalled(t, "TouchLastUsed", mock.Anything, mock.Anything)
+	})
+
+	t.Run("revoke", func(t *testing.T) {
+		// モック作成
+		keys := new(MockApiKeyRepository)
+		keys.On("FindById", 1).Return(&user.ApiKey{Id: 1, UserId: 1}, nil)
+		keys.On("Revoke", mock.MatchedBy(func(k *user.ApiKey) bool {
+			return k.RevokedAt != nil && k.RevokedAt.Equal(now)
+		})).Return(nil)
+		usecase := createUsecase(keys)
+
+		// 検証
+		require.NoError(t, usecase.RevokeApiKey(user.UserId(1), 1))
+		keys.AssertExpectations(t)
+	})
+
+	t.Run("revoke other user's key", func(t *testing.T) {
+		// モック作成
+		keys := new(MockApiKeyRepository)
+		keys.On("FindById", 1).Return(&user.ApiKey{Id: 1, UserId: 2}, nil)
+		usecase := createUsecase(keys)
+
+		// 検証
+		assert.ErrorIs(t, usecase.RevokeApiKey(user.UserId(1), 1), repository.ErrNotFound)
+		keys.AssertNotCalled(t, "Revoke", mock.Anything)
+	})
+}
+
+func apiKeyInput(name string, scopes ...string) usecase.CreateApiKeyInput {
+	return usecase.CreateApiKeyInput{Name: name, Scopes: scopes}
+}
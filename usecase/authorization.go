@@ -0,0 +1,121 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+
+	"github.com/fuki01/onion-architecture/domain/task"
+	"github.com/fuki01/onion-architecture/domain/user"
+	"github.com/fuki01/onion-architecture/domain/workspace"
+)
+
+// findAuthorized はタスクを取得し、ユーザーが操作を行えることを確認する
+func (tu *taskUsecase) findAuthorized(ctx context.Context, actor user.UserId, id task.TaskId, action workspace.Action) (*task.Task, error) {
+	t, err := tu.taskRepository.FindById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := tu.authorize(ctx, actor, t, action); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// findOwned はタスクを取得し、ユーザーが所有していることを確認する
+func (tu *taskUsecase) findOwned(ctx context.Context, actor user.UserId, id task.TaskId) (*task.Task, error) {
+	t, err := tu.taskRepository.FindById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !t.IsOwnedBy(actor) {
+		return nil, task.ErrForbidden
+	}
+	return t, nil
+}
+
+// authorize はユーザーがタスクに対して操作を行えるかを確認する
+// ワークスペースのタスクはメンバーの役割で判断する
+// それ以外のタスクは所有者と共有されたユーザーに許可し、子タスクは親タスクを共有されたユーザーにも許可する
+func (tu *taskUsecase) authorize(ctx context.Context, actor user.UserId, t *task.Task, action workspace.Action) error {
+	if t.WorkspaceId != nil {
+		_, err := tu.authorizeWorkspace(actor, *t.WorkspaceId, action)
+		return err
+	}
+
+	if t.IsAccessibleBy(actor) {
+		return nil
+	}
+	if t.ParentId != nil {
+		parent, err := tu.taskRepository.FindById(ctx, *t.ParentId)
+		if err != nil {
+			return err
+		}
+		if parent.IsAccessibleBy(actor) {
+			return nil
+		}
+	}
+	return task.ErrForbidden
+}
+
+// viewers はタスクを参照できるユーザーを返す
+// ワークスペースのタスクはタスクを参照できる役割のメンバー、それ以外のタスクは所有者と共有されたユーザーで、子タスクは親タスクのユーザーも含む
+func (tu *taskUsecase) viewers(ctx context.Context, t *task.Task) ([]user.UserId, error) {
+	if t.WorkspaceId != nil {
+		if tu.workspaceRepository == nil {
+			return nil, nil
+		}
+		ws, err := tu.workspaceRepository.FindById(*t.WorkspaceId)
+		if err != nil {
+			return nil, err
+		}
+		var ids []user.UserId
+		for _, m := range ws.Members {
+			if ws.Authorize(m.UserId, workspace.ActionViewTasks) == nil {
+				ids = append(ids, m.UserId)
+			}
+		}
+		return ids, nil
+	}
+
+	tasks := []*task.Task{t}
+	if t.ParentId != nil {
+		parent, err := tu.taskRepository.FindById(ctx, *t.ParentId)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, parent)
+	}
+	seen := map[user.UserId]bool{}
+	var ids []user.UserId
+	add := func(id user.UserId) {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	for _, target := range tasks {
+		add(target.CreatedBy)
+		for _, s := range target.Shares {
+			add(s.UserId)
+		}
+	}
+	return ids, nil
+}
+
+// authorizeWorkspace はワークスペースを取得し、ユーザーの役割で操作が許可されているかを確認する
+func (tu *taskUsecase) authorizeWorkspace(actor user.UserId, id workspace.WorkspaceId, action workspace.Action) (*workspace.Workspace, error) {
+	if tu.workspaceRepository == nil {
+		return nil, task.ErrForbidden
+	}
+	ws, err := tu.workspaceRepository.FindById(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := ws.Authorize(actor, action); err != nil {
+		if errors.Is(err, workspace.ErrForbidden) {
+			return nil, task.ErrForbidden
+		}
+		return nil, err
+	}
+	return ws, nil
+}
@@ -0,0 +1,258 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fuki01/onion-architecture/domain/task"
+	"github.com/fuki01/onion-architecture/domain/user"
+	"github.com/fuki01/onion-architecture/domain/workspace"
+	"github.com/fuki01/onion-architecture/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAuthorization(t *testing.T) {
+	owner := user.UserId(1)
+	shared := user.UserId(2)
+	stranger := user.UserId(3)
+
+	// ユーザー1が所有し、ユーザー2に共有したタスク
+	newOwnedTask := func() *task.Task {
+		t := task.NewTask("test", owner, "2024-01-01")
+		t.Id = task.TaskId(1)
+		t.Shares = []task.Share{{TaskId: 1, UserId: shared}}
+		return t
+	}
+
+	t.Run("stranger cannot extend", func(t *testing.T) {
+		// モック作成
+		mockRepo := new(MockTaskRepository)
+		mockRepo.On("FindById", task.TaskId(1)).Return(newOwnedTask(), nil)
+		usecase := usecase.NewTaskUsecase(mockRepo)
+
+		// 検証
+		err := usecase.ExtendDueDate(context.Background(), stranger, task.TaskId(1), "2024-01-02")
+		assert.ErrorIs(t, err, task.ErrForbidden)
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+	})
+
+	t.Run("stranger cannot change status", func(t *testing.T) {
+		// モック作成
+		mockRepo := new(MockTaskRepository)
+		mockRepo.On("FindById", task.TaskId(1)).Return(newOwnedTask(), nil)
+		usecase := usecase.NewTaskUsecase(mockRepo)
+
+		// 検証
+		err := usecase.ChangeStatus(context.Background(), stranger, task.TaskId(1), task.StatusComplete)
+		assert.ErrorIs(t, err, task.ErrForbidden)
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+	})
+
+	t.Run("stranger cannot read subtasks", func(t *testing.T) {
+		// モック作成
+		mockRepo := new(MockTaskRepository)
+		mockRepo.On("FindById", task.TaskId(1)).Return(newOwnedTask(), nil)
+		usecase := usecase.NewTaskUsecase(mockRepo)
+
+		// 検証
+		_, err := usecase.GetSubtasks(context.Background(), stranger, task.TaskId(1))
+		assert.ErrorIs(t, err, task.ErrForbidden)
+	})
+
+	t.Run("stranger cannot list other user's tasks", func(t *testing.T) {
+		// モック作成
+		mockRepo := new(MockTaskRepository)
+		usecase := usecase.NewTaskUsecase(mockRepo)
+
+		// 検証
+		_, err := usecase.GetTasksByUserId(context.Background(), stranger, owner, task.Filter{})
+		assert.ErrorIs(t, err, task.ErrForbidden)
+		mockRepo.AssertNotCalled(t, "FindByCreatedBy", mock.Anything, mock.Anything)
+	})
+
+	t.Run("cannot depend on inaccessible task", func(t *testing.T) {
+		// 初期値の設定
+		mine := task.NewTask("mine", stranger, "2024-01-01")
+		mine.Id = task.TaskId(2)
+
+		// モック作成
+		mockRepo := new(MockTaskRepository)
+		mockRepo.On("FindById", task.TaskId(2)).Return(mine, nil)
+		mockRepo.On("FindById", task.TaskId(1)).Return(newOwnedTask(), nil)
+		usecase := usecase.NewTaskUsecase(mockRepo)
+
+		// 検証
+		err := usecase.AddDependency(context.Background(), stranger, task.TaskId(2), task.TaskId(1))
+		assert.ErrorIs(t, err, task.ErrForbidden)
+		mockRepo.AssertNotCalled(t, "AddDependency", mock.Anything)
+	})
+
+	t.Run("shared user can extend", func(t *testing.T) {
+		// 初期値の設定
+		existingTask := newOwnedTask()
+
+		// モック作成
+		mockRepo := new(MockTaskRepository)
+		mockRepo.On("FindById", task.TaskId(1)).Return(existingTask, nil)
+		mockRepo.On("Update", existingTask).Return(nil)
+		usecase := usecase.NewTaskUsecase(mockRepo)
+
+		// 検証
+		assert.NoError(t, usecase.ExtendDueDate(context.Background(), shared, task.TaskId(1), "2024-01-02"))
+		assert.Equal(t, "2024-01-02", existingTask.DueDate)
+	})
+
+	t.Run("subtask is accessible through shared parent", func(t *testing.T) {
+		// 初期値の設定
+		parentId := task.TaskId(1)
+		child := task.NewTask("child", owner, "2024-01-01")
+		child.Id = task.TaskId(2)
+		child.ParentId = &parentId
+
+		// モック作成
+		mockRepo := new(MockTaskRepository)
+		mockRepo.On("FindById", task.TaskId(2)).Return(child, nil)
+		mockRepo.On("FindById", task.TaskId(1)).Return(newOwnedTask(), nil)
+		mockRepo.On("Update", child).Return(nil)
+		usecase := usecase.NewTaskUsecase(mockRepo)
+
+		// 検証
+		assert.NoError(t, usecase.ChangeStatus(context.Background(), shared, task.TaskId(2), task.StatusComplete))
+		assert.ErrorIs(t, usecase.ChangeStatus(context.Background(), stranger, task.TaskId(2), task.StatusComplete), task.ErrForbidden)
+	})
+
+	t.Run("only owner can share", func(t *testing.T) {
+		// モック作成
+		mockRepo := new(MockTaskRepository)
+		mockRepo.On("FindById", task.TaskId(1)).Return(newOwnedTask(), nil)
+		mockRepo.On("AddShare", task.Share{TaskId: 1, UserId: stranger}).Return(nil)
+		usecase := usecase.NewTaskUsecase(mockRepo)
+
+		// 検証
+		assert.ErrorIs(t, usecase.ShareTask(context.Background(), shared, task.TaskId(1), stranger), task.ErrForbidden)
+		assert.ErrorIs(t, usecase.UnshareTask(context.Background(), shared, task.TaskId(1), shared), task.ErrForbidden)
+		assert.NoError(t, usecase.ShareTask(context.Background(), owner, task.TaskId(1), stranger))
+		mockRepo.AssertNumberOfCalls(t, "AddShare", 1)
+	})
+
+	t.Run("cannot share with owner", func(t *testing.T) {
+		// モック作成
+		mockRepo := new(MockTaskRepository)
+		mockRepo.On("FindById", task.TaskId(1)).Return(newOwnedTask(), nil)
+		usecase := usecase.NewTaskUsecase(mockRepo)
+
+		// 検証
+		var validationErr *task.ValidationError
+		assert.ErrorAs(t, usecase.ShareTask(context.Background(), owner, task.TaskId(1), owner), &validationErr)
+		mockRepo.AssertNotCalled(t, "AddShare", mock.Anything)
+	})
+}
+
+func TestWorkspaceAuthorization(t *testing.T) {
+	owner := user.UserId(1)
+	member := user.UserId(3)
+	viewer := user.UserId(4)
+	outsider := user.UserId(5)
+
+	// newTeamのワークスペースに属し、ユーザー3が作成したタスク
+	newTeamTask := func() *task.Task {
+		t := task.NewTask("test", member, "2024-01-01")
+		t.Id = task.TaskId(1)
+		workspaceId := workspace.WorkspaceId(1)
+		t.WorkspaceId = &workspaceId
+		return t
+	}
+	createUsecase := func(mockRepo *MockTaskRepository) usecase.TaskUsecase {
+		workspaces := new(MockWorkspaceRepository)
+		workspaces.On("FindById", workspace.WorkspaceId(1)).Return(newTeam(), nil)
+		return usecase.NewTaskUsecase(mockRepo, usecase.WithWorkspaceRepository(workspaces))
+	}
+
+	t.Run("viewer can read but cannot edit", func(t *testing.T) {
+		// モック作成
+		mockRepo := new(MockTaskRepository)
+		mockRepo.On("FindById", task.TaskId(1)).Return(newTeamTask(), nil)
+		usecase := createUsecase(mockRepo)
+
+		// 検証
+		_, err := usecase.GetSubtasks(context.Background(), viewer, task.TaskId(1))
+		assert.NoError(t, err)
+		assert.ErrorIs(t, usecase.ChangeStatus(context.Background(), viewer, task.TaskId(1), task.StatusComplete), task.ErrForbidden)
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+	})
+
+	t.Run("outsider cannot read", func(t *testing.T) {
+		// モック作成
+		mockRepo := new(MockTaskRepository)
+		mockRepo.On("FindById", task.TaskId(1)).Return(newTeamTask(), nil)
+		usecase := createUsecase(mockRepo)
+
+		// 検証
+		_, err := usecase.GetSubtasks(context.Background(), outsider, task.TaskId(1))
+		assert.ErrorIs(t, err, task.ErrForbidden)
+		_, err = usecase.GetWorkspaceTasks(context.Background(), outsider, workspace.WorkspaceId(1), task.Filter{})
+		assert.ErrorIs(t, err, task.ErrForbidden)
+	})
+
+	t.Run("owner can edit other member's task", func(t *testing.T) {
+		// 初期値の設定
+		existingTask := newTeamTask()
+
+		// モック作成
+		mockRepo := new(MockTaskRepository)
+		mockRepo.On("FindById", task.TaskId(1)).Return(existingTask, nil)
+		mockRepo.On("Update", existingTask).Return(nil)
+		usecase := createUsecase(mockRepo)
+
+		// 検証
+		assert.NoError(t, usecase.ExtendDueDate(context.Background(), owner, task.TaskId(1), "2024-01-02"))
+	})
+
+	t.Run("viewer cannot create", func(t *testing.T) {
+		// 初期値の設定
+		workspaceId := workspace.WorkspaceId(1)
+		input := usecase.CreateTaskInput{Name: "test", CreatedBy: viewer, DueDate: "2024-01-01", WorkspaceId: &workspaceId}
+
+		// モック作成
+		mockRepo := new(MockTaskRepository)
+		usecase := createUsecase(mockRepo)
+
+		// 検証
+		_, err := usecase.CreateTask(context.Background(), input)
+		assert.ErrorIs(t, err, task.ErrForbidden)
+		mockRepo.AssertNotCalled(t, "Insert", mock.Anything)
+	})
+
+	t.Run("assign to member", func(t *testing.T) {
+		// 初期値の設定
+		existingTask := newTeamTask()
+
+		// モック作成
+		mockRepo := new(MockTaskRepository)
+		mockRepo.On("FindById", task.TaskId(1)).Return(existingTask, nil)
+		mockRepo.On("Reassign", existingTask, mock.AnythingOfType("*task.Assignment")).Return(nil)
+		usecase := createUsecase(mockRepo)
+
+		// 検証
+		assert.NoError(t, usecase.ReassignTask(context.Background(), member, task.TaskId(1), &viewer))
+		assert.Equal(t, &viewer, existingTask.AssigneeId)
+		assert.Equal(t, member, existingTask.CreatedBy)
+
+		var validationErr *task.ValidationError
+		assert.ErrorAs(t, usecase.ReassignTask(context.Background(), member, task.TaskId(1), &outsider), &validationErr)
+		assert.ErrorIs(t, usecase.ReassignTask(context.Background(), viewer, task.TaskId(1), &viewer), task.ErrForbidden)
+		mockRepo.AssertNumberOfCalls(t, "Reassign", 1)
+	})
+
+	t.Run("workspace tasks without repository", func(t *testing.T) {
+		// モック作成
+		mockRepo := new(MockTaskRepository)
+		mockRepo.On("FindById", task.TaskId(1)).Return(newTeamTask(), nil)
+		usecase := usecase.NewTaskUsecase(mockRepo)
+
+		// 検証
+		_, err := usecase.GetSubtasks(context.Background(), member, task.TaskId(1))
+		assert.ErrorIs(t, err, task.ErrForbidden)
+	})
+}
@@ -0,0 +1,107 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/fuki01/onion-architecture/domain/idempotency"
+	"github.com/fuki01/onion-architecture/domain/repository"
+	"github.com/fuki01/onion-architecture/domain/user"
+)
+
+// IdempotencyUsecase は冪等キーを付けたリクエストの応答を保存し、再送されたリクエストに同じ応答を返す
+type IdempotencyUsecase interface {
+	// Begin はキーでリクエストを受け付ける
+	// 同じキーで同じリクエストの応答を記録済みの場合は、その記録とtrueを返す
+	// 別のリクエストに使われたキーの場合はErrKeyReused、同じリクエストを処理中の場合はErrInProgressを返す
+	Begin(ctx context.Context, userId user.UserId, key, requestHash string) (*idempotency.Record, bool, error)
+	// Complete は受け付けたリクエストの応答を記録する
+	Complete(ctx context.Context, r *idempotency.Record, statusCode int, contentType string, body []byte) error
+	// Abandon は応答を記録せずに受け付けを取り消し、同じキーで再試行できるようにする
+	Abandon(ctx context.Context, r *idempotency.Record) error
+	// DeleteExpired はnow時点で保存期間を過ぎた記録を削除する
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+}
+
+// 処理中のまま応答が記録されない場合に、処理したプロセスが停止したとみなすまでの時間
+const idempotencyLockTimeout = time.Minute
+
+type idempotencyUsecase struct {
+	idempotencyRepository repository.IdempotencyRepository
+	ttl                   time.Duration
+	now                   func() time.Time
+}
+
+func NewIdempotencyUsecase(idempotencyRepository repository.IdempotencyRepository, ttl time.Duration, opts ...IdempotencyUsecaseOption) IdempotencyUsecase {
+	iu := &idempotencyUsecase{
+		idempotencyRepository: idempotencyRepository,
+		ttl:                   ttl,
+		now:                   time.Now,
+	}
+	for _, opt := range opts {
+		opt(iu)
+	}
+	return iu
+}
+
+// キーでリクエストを受け付ける
+// 記録の登録は主キーの一意制約で排他するため、同じキーのリクエストが同時に届いても一方のみ処理する
+func (iu *idempotencyUsecase) Begin(ctx context.Context, userId user.UserId, key, requestHash string) (*idempotency.Record, bool, error) {
+	now := iu.now()
+	r, err := idempotency.NewRecord(userId, key, requestHash, now, iu.ttl)
+	if err != nil {
+		return nil, false, err
+	}
+	err = iu.idempotencyRepository.Insert(ctx, r)
+	if err == nil {
+		return r, false, nil
+	}
+	if !errors.Is(err, repository.ErrAlreadyExists) {
+		return nil, false, err
+	}
+
+	existing, err := iu.idempotencyRepository.Find(ctx, userId, r.KeyHash)
+	if errors.Is(err, repository.ErrNotFound) {
+		// 登録の後に削除された場合は、他のリクエストが再び受け付けるまでの間とみなす
+		return nil, false, idempotency.ErrInProgress
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	if existing.IsExpired(now) || existing.IsAbandoned(now, idempotencyLockTimeout) {
+		if err := iu.idempotencyRepository.Delete(ctx, existing); err != nil {
+			return nil, false, err
+		}
+		if err := iu.idempotencyRepository.Insert(ctx, r); err != nil {
+			if errors.Is(err, repository.ErrAlreadyExists) {
+				return nil, false, idempotency.ErrInProgress
+			}
+			return nil, false, err
+		}
+		return r, false, nil
+	}
+	if existing.RequestHash != requestHash {
+		return nil, false, idempotency.ErrKeyReused
+	}
+	if !existing.IsCompleted() {
+		return nil, false, idempotency.ErrInProgress
+	}
+	return existing, true, nil
+}
+
+// 応答を記録する
+func (iu *idempotencyUsecase) Complete(ctx context.Context, r *idempotency.Record, statusCode int, contentType string, body []byte) error {
+	r.Complete(statusCode, contentType, body)
+	return iu.idempotencyRepository.Update(ctx, r)
+}
+
+// 受け付けを取り消す
+func (iu *idempotencyUsecase) Abandon(ctx context.Context, r *idempotency.Record) error {
+	return iu.idempotencyRepository.Delete(ctx, r)
+}
+
+// 保存期間を過ぎた記録を削除する
+func (iu *idempotencyUsecase) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	return iu.idempotencyRepository.DeleteExpired(ctx, now)
+}
@@ -0,0 +1,172 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fuki01/onion-architecture/domain/idempotency"
+	"github.com/fuki01/onion-architecture/domain/repository"
+	"github.com/fuki01/onion-architecture/domain/user"
+	"github.com/fuki01/onion-architecture/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockIdempotencyRepository struct {
+	mock.Mock
+}
+
+func (m *MockIdempotencyRepository) Insert(ctx context.Context, r *idempotency.Record) error {
+	args := m.Called(r)
+	return args.Error(0)
+}
+
+func (m *MockIdempotencyRepository) Find(ctx context.Context, userId user.UserId, keyHash string) (*idempotency.Record, error) {
+	args := m.Called(userId, keyHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*idempotency.Record), args.Error(1)
+}
+
+func (m *MockIdempotencyRepository) Update(ctx context.Context, r *idempotency.Record) error {
+	args := m.Called(r)
+	return args.Error(0)
+}
+
+func (m *MockIdempotencyRepository) Delete(ctx context.Context, r *idempotency.Record) error {
+	args := m.Called(r)
+	return args.Error(0)
+}
+
+func (m *MockIdempotencyRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	args := m.Called(now)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func TestIdempotency(t *testing.T) {
+	now := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	keyHash := idempotency.HashKey("key-1")
+	createUsecase := func(records *MockIdempotencyRepository) usecase.IdempotencyUsecase {
+		return usecase.NewIdempotencyUsecase(records, 24*time.Hour, usecase.WithIdempotencyClock(func() time.Time { return now }))
+	}
+
+	t.Run("first request", func(t *testing.T) {
+		// モック作成
+		records := new(MockIdempotencyRepository)
+		records.On("Insert", mock.AnythingOfType("*idempotency.Record")).Return(nil)
+		records.On("Update", mock.AnythingOfType("*idempotency.Record")).Return(nil)
+		usecase := createUsecase(records)
+
+		// 受け付け
+		r, replay, err := usecase.Begin(context.Background(), user.UserId(1), "key-1", "hash")
+		require.NoError(t, err)
+		assert.False(t, replay)
+		assert.Equal(t, keyHash, r.KeyHash)
+		assert.Equal(t, now.Add(24*time.Hour), r.ExpiresAt)
+		assert.False(t, r.IsCompleted())
+
+		// 検証
+		require.NoError(t, usecase.Complete(context.Background(), r, 201, "application/json", []byte(`{"id":1}`)))
+		assert.True(t, r.IsCompleted())
+		records.AssertExpectations(t)
+	})
+
+	t.Run("existing record", func(t *testing.T) {
+		completed := &idempotency.Record{UserId: 1, KeyHash: keyHash, RequestHash: "hash", StatusCode: 201, Body: []byte(`{"id":1}`), CreatedAt: now.Add(-time.Hour), ExpiresAt: now.Add(time.Hour)}
+		inProgress := &idempotency.Record{UserId: 1, KeyHash: keyHash, RequestHash: "hash", CreatedAt: now.Add(-time.Second), ExpiresAt: now.Add(time.Hour)}
+		testCases := []struct {
+			name           string
+			existing       *idempotency.Record
+			requestHash    string
+			expectedReplay bool
+			expectedErr    error
+		}{
+			{
+				name:           "Replay",
+				existing:       completed,
+				requestHash:    "hash",
+				expectedReplay: true,
+			},
+			{
+				name:        "KeyReused",
+				existing:    completed,
+				requestHash: "other",
+				expectedErr: idempotency.ErrKeyReused,
+			},
+			{
+				name:        "InProgress",
+				existing:    inProgress,
+				requestHash: "hash",
+				expectedErr: idempotency.ErrInProgress,
+			},
+			{
+				name:        "Deleted",
+				existing:    nil,
+				requestHash: "hash",
+				expectedErr: idempotency.ErrInProgress,
+			},
+		}
+
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				// モック作成
+				records := new(MockIdempotencyRepository)
+				records.On("Insert", mock.AnythingOfType("*idempotency.Record")).Return(repository.ErrAlreadyExists)
+				if tc.existing != nil {
+					records.On("Find", user.UserId(1), keyHash).Return(tc.existing, nil)
+				} else {
+					records.On("Find", user.UserId(1), keyHash).Return(nil, repository.ErrNotFound)
+				}
+				usecase := createUsecase(records)
+
+				// 検証
+				r, replay, err := usecase.Begin(context.Background(), user.UserId(1), "key-1", tc.requestHash)
+				assert.ErrorIs(t, err, tc.expectedErr)
+				assert.Equal(t, tc.expectedReplay, replay)
+				if tc.expectedReplay {
+					assert.Same(t, tc.existing, r)
+				}
+				records.AssertNotCalled(t, "Delete", mock.Anything)
+			})
+		}
+	})
+
+	t.Run("stale record is replaced", func(t *testing.T) {
+		for name, existing := range map[string]*idempotency.Record{
+			"expired":   {UserId: 1, KeyHash: keyHash, RequestHash: "other", StatusCode: 201, CreatedAt: now.Add(-25 * time.Hour), ExpiresAt: now.Add(-time.Hour)},
+			"abandoned": {UserId: 1, KeyHash: keyHash, RequestHash: "hash", CreatedAt: now.Add(-2 * time.Minute), ExpiresAt: now.Add(time.Hour)},
+		} {
+			t.Run(name, func(t *testing.T) {
+				// モック作成
+				records := new(MockIdempotencyRepository)
+				records.On("Insert", mock.AnythingOfType("*idempotency.Record")).Return(repository.ErrAlreadyExists).Once()
+				records.On("Find", user.UserId(1), keyHash).Return(existing, nil)
+				records.On("Delete", existing).Return(nil)
+				records.On("Insert", mock.AnythingOfType("*idempotency.Record")).Return(nil).Once()
+				usecase := createUsecase(records)
+
+				// 検証
+				r, replay, err := usecase.Begin(context.Background(), user.UserId(1), "key-1", "hash")
+				require.NoError(t, err)
+				assert.False(t, replay)
+				assert.NotSame(t, existing, r)
+				assert.Equal(t, "hash", r.RequestHash)
+				records.AssertExpectations(t)
+			})
+		}
+	})
+
+	t.Run("invalid key", func(t *testing.T) {
+		// モック作成
+		records := new(MockIdempotencyRepository)
+		usecase := createUsecase(records)
+
+		// 検証
+		_, _, err := usecase.Begin(context.Background(), user.UserId(1), "", "hash")
+		assert.ErrorIs(t, err, idempotency.ErrInvalidKey)
+		records.AssertNotCalled(t, "Insert", mock.Anything)
+	})
+}
@@ -0,0 +1,101 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/fuki01/onion-architecture/domain/repository"
+	"github.com/fuki01/onion-architecture/domain/task"
+	"github.com/fuki01/onion-architecture/domain/user"
+)
+
+type NotificationUsecase interface {
+	Notify(ctx context.Context, kind NotificationKind, tasks []*task.Task) error
+	// GetSettings はユーザーの通知設定を取得する。他のユーザーの設定は取得できない
+	GetSettings(actor user.UserId, userId user.UserId) (*user.NotificationSettings, error)
+	// UpdateSettings はユーザーの通知設定を更新する。他のユーザーの設定は更新できない
+	UpdateSettings(actor user.UserId, settings *user.NotificationSettings) error
+}
+
+type notificationUsecase struct {
+	settingsRepository repository.NotificationSettingsRepository
+	notifiers          map[user.NotificationChannel]Notifier
+}
+
+func NewNotificationUsecase(settingsRepository repository.NotificationSettingsRepository, notifiers map[user.NotificationChannel]Notifier) NotificationUsecase {
+	return &notificationUsecase{
+		settingsRepository: settingsRepository,
+		notifiers:          notifiers,
+	}
+}
+
+// タスクの担当者(いない場合は作成者)の有効な通知先すべてに通知する
+// 一部の送信に失敗しても残りの送信は続け、失敗をまとめて返す
+func (nu *notificationUsecase) Notify(ctx context.Context, kind NotificationKind, tasks []*task.Task) error {
+	var errs []error
+	for _, t := range tasks {
+		settings, err := nu.settingsOf(t.Recipient())
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		subject, body, err := renderMessage(kind, settings.Locale, t)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		for _, pref := range settings.EnabledPreferences() {
+			notifier, ok := nu.notifiers[pref.Channel]
+			if !ok {
+				errs = append(errs, fmt.Errorf("no notifier for channel %s", pref.Channel))
+				continue
+			}
+			err := notifier.Notify(ctx, Notification{
+				Channel: pref.Channel,
+				Target:  pref.Target,
+				Subject: subject,
+				Body:    body,
+			})
+			if err != nil {
+				errs = append(errs, fmt.Errorf("notify task %d via %s: %w", t.Id, pref.Channel, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// 通知設定を取得する
+func (nu *notificationUsecase) GetSettings(actor user.UserId, userId user.UserId) (*user.NotificationSettings, error) {
+	if actor != userId {
+		return nil, user.ErrForbidden
+	}
+	return nu.settingsOf(userId)
+}
+
+// 通知設定を更新する
+// 通知先を書き換えて他のユーザーの通知を受け取れないよう、本人の設定のみ更新できる
+func (nu *notificationUsecase) UpdateSettings(actor user.UserId, settings *user.NotificationSettings) error {
+	if actor != settings.UserId {
+		return user.ErrForbidden
+	}
+	if settings.Locale == "" {
+		settings.Locale = user.LocaleJa
+	}
+	if err := settings.Validate(); err != nil {
+		return err
+	}
+	return nu.settingsRepository.Save(settings)
+}
+
+// 通知設定を取得する
+// 未設定のユーザーは既定の設定を返す
+func (nu *notificationUsecase) settingsOf(userId user.UserId) (*user.NotificationSettings, error) {
+	settings, err := nu.settingsRepository.FindByUserId(userId)
+	if errors.Is(err, repository.ErrNotFound) {
+		return user.NewNotificationSettings(userId), nil
+	}
+	return settings, err
+}
@@ -0,0 +1,70 @@
+package usecase
+
+import (
+	"strings"
+	"text/template"
+
+	"github.com/fuki01/onion-architecture/domain/task"
+	"github.com/fuki01/onion-architecture/domain/user"
+)
+
+type NotificationKind string
+
+const (
+	NotificationDueSoon NotificationKind = "due_soon"
+	NotificationOverdue NotificationKind = "overdue"
+)
+
+type messageTemplate struct {
+	subject *template.Template
+	body    *template.Template
+}
+
+func newMessageTemplate(subject, body string) messageTemplate {
+	return messageTemplate{
+		subject: template.Must(template.New("subject").Parse(subject)),
+		body:    template.Must(template.New("body").Parse(body)),
+	}
+}
+
+var messageTemplates = map[NotificationKind]map[user.Locale]messageTemplate{
+	NotificationDueSoon: {
+		user.LocaleJa: newMessageTemplate(
+			"【リマインド】タスク「{{.Name}}」の期限が近づいています",
+			"タスク「{{.Name}}」の期限は{{.DueDate}}です。\n",
+		),
+		user.LocaleEn: newMessageTemplate(
+			`Reminder: "{{.Name}}" is due soon`,
+			"The task \"{{.Name}}\" is due on {{.DueDate}}.\n",
+		),
+	},
+	NotificationOverdue: {
+		user.LocaleJa: newMessageTemplate(
+			"【期限切れ】タスク「{{.Name}}」の期限が過ぎています",
+			"タスク「{{.Name}}」の期限({{.DueDate}})が過ぎています。\n",
+		),
+		user.LocaleEn: newMessageTemplate(
+			`Overdue: "{{.Name}}"`,
+			"The task \"{{.Name}}\" was due on {{.DueDate}} and is not completed yet.\n",
+		),
+	},
+}
+
+// renderMessage はタスクの通知文を指定した言語で生成する
+// 未対応の言語は日本語で生成する
+func renderMessage(kind NotificationKind, locale user.Locale, t *task.Task) (string, string, error) {
+	templates := messageTemplates[kind]
+	tmpl, ok := templates[locale]
+	if !ok {
+		tmpl = templates[user.LocaleJa]
+	}
+
+	var subject, body strings.Builder
+	if err := tmpl.subject.Execute(&subject, t); err != nil {
+		return "", "", err
+	}
+	if err := tmpl.body.Execute(&body, t); err != nil {
+		return "", "", err
+	}
+	return subject.String(), body.String(), nil
+}
@@ -0,0 +1,163 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fuki01/onion-architecture/domain/repository"
+	"github.com/fuki01/onion-architecture/domain/task"
+	"github.com/fuki01/onion-architecture/domain/user"
+	"github.com/fuki01/onion-architecture/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockNotificationSettingsRepository struct {
+	mock.Mock
+}
+
+func (m *MockNotificationSettingsRepository) FindByUserId(userId user.UserId) (*user.NotificationSettings, error) {
+	args := m.Called(userId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*user.NotificationSettings), args.Error(1)
+}
+
+func (m *MockNotificationSettingsRepository) Save(settings *user.NotificationSettings) error {
+	args := m.Called(settings)
+	return args.Error(0)
+}
+
+type MockNotifier struct {
+	mock.Mock
+}
+
+func (m *MockNotifier) Notify(ctx context.Context, n usecase.Notification) error {
+	args := m.Called(n)
+	return args.Error(0)
+}
+
+func TestNotify(t *testing.T) {
+	dueSoon := task.NewTask("資料作成", user.UserId(1), "2024-01-10")
+
+	t.Run("japanese email", func(t *testing.T) {
+		// モック作成
+		mockRepo := new(MockNotificationSettingsRepository)
+		mockRepo.On("FindByUserId", user.UserId(1)).Return(&user.NotificationSettings{
+			UserId: 1,
+			Locale: user.LocaleJa,
+			Preferences: []user.NotificationPreference{
+				{Channel: user.ChannelEmail, Target: "taro@example.com", Enabled: true},
+				{Channel: user.ChannelSlack, Target: "https://hooks.example.com/x", Enabled: false},
+			},
+		}, nil)
+		email := new(MockNotifier)
+		email.On("Notify", usecase.Notification{
+			Channel: user.ChannelEmail,
+			Target:  "taro@example.com",
+			Subject: "【リマインド】タスク「資料作成」の期限が近づいています",
+			Body:    "タスク「資料作成」の期限は2024-01-10です。\n",
+		}).Return(nil)
+		slack := new(MockNotifier)
+		usecase := usecase.NewNotificationUsecase(mockRepo, map[user.NotificationChannel]usecase.Notifier{
+			user.ChannelEmail: email,
+			user.ChannelSlack: slack,
+		})
+
+		// 検証
+		assert.NoError(t, usecase.Notify(context.Background(), "due_soon", []*task.Task{dueSoon}))
+		email.AssertExpectations(t)
+		slack.AssertNotCalled(t, "Notify", mock.Anything)
+	})
+
+	t.Run("english webhook", func(t *testing.T) {
+		// モック作成
+		mockRepo := new(MockNotificationSettingsRepository)
+		mockRepo.On("FindByUserId", user.UserId(1)).Return(&user.NotificationSettings{
+			UserId:      1,
+			Locale:      user.LocaleEn,
+			Preferences: []user.NotificationPreference{{Channel: user.ChannelWebhook, Target: "https://example.com/hook", Enabled: true}},
+		}, nil)
+		webhook := new(MockNotifier)
+		webhook.On("Notify", usecase.Notification{
+			Channel: user.ChannelWebhook,
+			Target:  "https://example.com/hook",
+			Subject: `Overdue: "資料作成"`,
+			Body:    "The task \"資料作成\" was due on 2024-01-10 and is not completed yet.\n",
+		}).Return(nil)
+		usecase := usecase.NewNotificationUsecase(mockRepo, map[user.NotificationChannel]usecase.Notifier{
+			user.ChannelWebhook: webhook,
+		})
+
+		// 検証
+		assert.NoError(t, usecase.Notify(context.Background(), "overdue", []*task.Task{dueSoon}))
+		webhook.AssertExpectations(t)
+	})
+
+	t.Run("failures are collected", func(t *testing.T) {
+		// モック作成
+		mockRepo := new(MockNotificationSettingsRepository)
+		mockRepo.On("FindByUserId", user.UserId(1)).Return(&user.NotificationSettings{
+			UserId: 1,
+			Locale: user.LocaleJa,
+			Preferences: []user.NotificationPreference{
+				{Channel: user.ChannelEmail, Target: "taro@example.com", Enabled: true},
+				{Channel: user.ChannelSlack, Target: "https://hooks.example.com/x", Enabled: true},
+			},
+		}, nil)
+		email := new(MockNotifier)
+		email.On("Notify", mock.Anything).Return(assert.AnError)
+		usecase := usecase.NewNotificationUsecase(mockRepo, map[user.NotificationChannel]usecase.Notifier{
+			user.ChannelEmail: email,
+		})
+
+		// 検証
+		err := usecase.Notify(context.Background(), "due_soon", []*task.Task{dueSoon})
+		assert.ErrorIs(t, err, assert.AnError)
+		assert.Contains(t, err.Error(), "no notifier for channel slack")
+	})
+}
+
+func TestNotificationSettings(t *testing.T) {
+	t.Run("default settings", func(t *testing.T) {
+		// モック作成
+		mockRepo := new(MockNotificationSettingsRepository)
+		mockRepo.On("FindByUserId", user.UserId(2)).Return(nil, repository.ErrNotFound)
+		usecase := usecase.NewNotificationUsecase(mockRepo, nil)
+
+		// 検証
+		settings, err := usecase.GetSettings(user.UserId(2), user.UserId(2))
+		assert.NoError(t, err)
+		assert.Equal(t, user.LocaleJa, settings.Locale)
+		assert.Empty(t, settings.Preferences)
+	})
+
+	t.Run("invalid target", func(t *testing.T) {
+		// モック作成
+		mockRepo := new(MockNotificationSettingsRepository)
+		usecase := usecase.NewNotificationUsecase(mockRepo, nil)
+
+		// 検証
+		err := usecase.UpdateSettings(user.UserId(1), &user.NotificationSettings{
+			UserId:      1,
+			Preferences: []user.NotificationPreference{{Channel: user.ChannelEmail, Target: "not-an-address", Enabled: true}},
+		})
+		assert.EqualError(t, err, "invalid email address")
+		mockRepo.AssertNotCalled(t, "Save", mock.Anything)
+	})
+
+	t.Run("other user", func(t *testing.T) {
+		// モック作成
+		mockRepo := new(MockNotificationSettingsRepository)
+		usecase := usecase.NewNotificationUsecase(mockRepo, nil)
+
+		// 検証
+		_, err := usecase.GetSettings(user.UserId(1), user.UserId(2))
+		assert.ErrorIs(t, err, user.ErrForbidden)
+		err = usecase.UpdateSettings(user.UserId(1), user.NewNotificationSettings(2))
+		assert.ErrorIs(t, err, user.ErrForbidden)
+		mockRepo.AssertNotCalled(t, "FindByUserId", mock.Anything)
+		mockRepo.AssertNotCalled(t, "Save", mock.Anything)
+	})
+}
@@ -0,0 +1,21 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/fuki01/onion-architecture/domain/user"
+)
+
+// Notification は通知チャネルに送る内容
+type Notification struct {
+	Channel user.NotificationChannel
+	Target  string
+	Subject string
+	Body    string
+}
+
+// Notifier は通知を送信するポート
+// チャネルごとにinfrastructureで実装する
+type Notifier interface {
+	Notify(ctx context.Context, n Notification) error
+}
@@ -0,0 +1,99 @@
+package usecase
+
+import (
+	"time"
+
+	"github.com/fuki01/onion-architecture/domain/repository"
+	"github.com/fuki01/onion-architecture/domain/task"
+	"github.com/fuki01/onion-architecture/domain/user"
+)
+
+// TaskUsecaseOption はTaskUsecaseの任意の設定
+type TaskUsecaseOption func(*taskUsecase)
+
+// WithDelayPolicy は期限延長の制限を設定する
+func WithDelayPolicy(policy task.DelayPolicy) TaskUsecaseOption {
+	return func(tu *taskUsecase) {
+		tu.delayPolicy = policy
+	}
+}
+
+// WithClock は現在時刻の取得方法を設定する
+func WithClock(now func() time.Time) TaskUsecaseOption {
+	return func(tu *taskUsecase) {
+		tu.now = now
+	}
+}
+
+// WithEventPublisher はタスクの操作で発生したイベントの送り先を設定する
+func WithEventPublisher(publisher EventPublisher) TaskUsecaseOption {
+	return func(tu *taskUsecase) {
+		tu.publisher = publisher
+	}
+}
+
+// WithTaskMetrics はタスクの操作を数える先を設定する
+func WithTaskMetrics(metrics TaskMetrics) TaskUsecaseOption {
+	return func(tu *taskUsecase) {
+		tu.metrics = metrics
+	}
+}
+
+// WithWorkspaceRepository はワークスペースのタスクの権限確認に使うリポジトリを設定する
+// 設定しない場合、ワークスペースのタスクは全て操作できない
+func WithWorkspaceRepository(workspaceRepository repository.WorkspaceRepository) TaskUsecaseOption {
+	return func(tu *taskUsecase) {
+		tu.workspaceRepository = workspaceRepository
+	}
+}
+
+// WithUserRepository は担当者が存在するかの確認に使うリポジトリを設定する
+func WithUserRepository(userRepository repository.UserRepository) TaskUsecaseOption {
+	return func(tu *taskUsecase) {
+		tu.userRepository = userRepository
+	}
+}
+
+// AccountUsecaseOption はAccountUsecaseの任意の設定
+type AccountUsecaseOption func(*accountUsecase)
+
+// WithLockoutPolicy はログインの失敗によるロックの設定を変更する
+func WithLockoutPolicy(policy user.LockoutPolicy) AccountUsecaseOption {
+	return func(au *accountUsecase) {
+		au.lockout = policy
+	}
+}
+
+// WithRefreshTokenTTL はリフレッシュトークンの有効期間を変更する
+func WithRefreshTokenTTL(ttl time.Duration) AccountUsecaseOption {
+	return func(au *accountUsecase) {
+		au.refreshTTL = ttl
+	}
+}
+
+// WithAccountClock は現在時刻の取得方法を設定する
+func WithAccountClock(now func() time.Time) AccountUsecaseOption {
+	return func(au *accountUsecase) {
+		au.now = now
+	}
+}
+
+// ApiKeyUsecaseOption はApiKeyUsecaseの任意の設定
+type ApiKeyUsecaseOption func(*apiKeyUsecase)
+
+// WithApiKeyClock は現在時刻の取得方法を設定する
+func WithApiKeyClock(now func() time.Time) ApiKeyUsecaseOption {
+	return func(au *apiKeyUsecase) {
+		au.now = now
+	}
+}
+
+// IdempotencyUsecaseOption はIdempotencyUsecaseの任意の設定
+type IdempotencyUsecaseOption func(*idempotencyUsecase)
+
+// WithIdempotencyClock は現在時刻の取得方法を設定する
+func WithIdempotencyClock(now func() time.Time) IdempotencyUsecaseOption {
+	return func(iu *idempotencyUsecase) {
+		iu.now = now
+	}
+}
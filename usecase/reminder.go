@@ -0,0 +1,66 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/fuki01/onion-architecture/domain/repository"
+	"github.com/fuki01/onion-architecture/domain/task"
+)
+
+// ReminderUsecase は期限が迫ったタスクと期限切れのタスクを扱う定期処理
+type ReminderUsecase interface {
+	RemindDueSoon(ctx context.Context, now time.Time, within time.Duration) ([]*task.Task, error)
+	MarkOverdue(ctx context.Context, now time.Time) ([]*task.Task, error)
+}
+
+type reminderUsecase struct {
+	taskRepository repository.TaskRepository
+}
+
+func NewReminderUsecase(taskRepository repository.TaskRepository) ReminderUsecase {
+	return &reminderUsecase{
+		taskRepository: taskRepository,
+	}
+}
+
+// nowから期限がwithin以内に迫った未通知のタスクを通知済みにする
+func (ru *reminderUsecase) RemindDueSoon(ctx context.Context, now time.Time, within time.Duration) ([]*task.Task, error) {
+	candidates, err := ru.taskRepository.FindIncompleteDueBy(ctx, now.Add(within).Format(task.DueDateLayout))
+	if err != nil {
+		return nil, err
+	}
+
+	var reminded []*task.Task
+	for _, t := range candidates {
+		if !t.NeedsReminder(now, within) {
+			continue
+		}
+		t.MarkReminded(now)
+		if err := ru.taskRepository.UpdateReminderState(ctx, t); err != nil {
+			return reminded, err
+		}
+		reminded = append(reminded, t)
+	}
+	return reminded, nil
+}
+
+// now時点で期限切れになったタスクに期限切れの日時を記録する
+func (ru *reminderUsecase) MarkOverdue(ctx context.Context, now time.Time) ([]*task.Task, error) {
+	candidates, err := ru.taskRepository.FindIncompleteDueBy(ctx, now.AddDate(0, 0, -1).Format(task.DueDateLayout))
+	if err != nil {
+		return nil, err
+	}
+
+	var marked []*task.Task
+	for _, t := range candidates {
+		if !t.MarkOverdue(now) {
+			continue
+		}
+		if err := ru.taskRepository.UpdateReminderState(ctx, t); err != nil {
+			return marked, err
+		}
+		marked = append(marked, t)
+	}
+	return marked, nil
+}
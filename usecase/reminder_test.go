@@ -0,0 +1,99 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fuki01/onion-architecture/domain/task"
+	"github.com/fuki01/onion-architecture/domain/user"
+	"github.com/fuki01/onion-architecture/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRemindDueSoon(t *testing.T) {
+	now := time.Date(2024, 1, 10, 9, 0, 0, 0, time.UTC)
+
+	t.Run("remind tasks due within 24h", func(t *testing.T) {
+		// 初期値の設定
+		dueToday := task.NewTask("today", user.UserId(1), "2024-01-10")
+		dueTomorrow := task.NewTask("tomorrow", user.UserId(1), "2024-01-11")
+		reminded := task.NewTask("reminded", user.UserId(1), "2024-01-10")
+		reminded.MarkReminded(now.Add(-time.Hour))
+
+		// モック作成
+		mockRepo := new(MockTaskRepository)
+		mockRepo.On("FindIncompleteDueBy", "2024-01-11").Return([]*task.Task{dueToday, dueTomorrow, reminded}, nil)
+		mockRepo.On("UpdateReminderState", dueToday).Return(nil)
+		usecase := usecase.NewReminderUsecase(mockRepo)
+
+		// 検証
+		result, err := usecase.RemindDueSoon(context.Background(), now, 24*time.Hour)
+		assert.NoError(t, err)
+		assert.Equal(t, []*task.Task{dueToday}, result)
+		assert.Equal(t, now, *dueToday.RemindedAt)
+		assert.Nil(t, dueTomorrow.RemindedAt)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestMarkOverdue(t *testing.T) {
+	now := time.Date(2024, 1, 10, 9, 0, 0, 0, time.UTC)
+
+	t.Run("mark overdue tasks once", func(t *testing.T) {
+		// 初期値の設定
+		overdue := task.NewTask("overdue", user.UserId(1), "2024-01-09")
+		marked := task.NewTask("marked", user.UserId(1), "2024-01-08")
+		marked.MarkOverdue(now.Add(-24 * time.Hour))
+
+		// モック作成
+		mockRepo := new(MockTaskRepository)
+		mockRepo.On("FindIncompleteDueBy", "2024-01-09").Return([]*task.Task{overdue, marked}, nil)
+		mockRepo.On("UpdateReminderState", overdue).Return(nil)
+		usecase := usecase.NewReminderUsecase(mockRepo)
+
+		// 検証
+		result, err := usecase.MarkOverdue(context.Background(), now)
+		assert.NoError(t, err)
+		assert.Equal(t, []*task.Task{overdue}, result)
+		mockRepo.AssertNotCalled(t, "UpdateReminderState", marked)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("tags are kept", func(t *testing.T) {
+		// 初期値の設定
+		tags, _ := task.NewTags([]string{"home"})
+		overdue := task.NewTask("overdue", user.UserId(1), "2024-01-09")
+		overdue.Tags = tags
+
+		// モック作成
+		mockRepo := new(MockTaskRepository)
+		mockRepo.On("FindIncompleteDueBy", "2024-01-09").Return([]*task.Task{overdue}, nil)
+		mockRepo.On("UpdateReminderState", overdue).Return(nil)
+		usecase := usecase.NewReminderUsecase(mockRepo)
+
+		// 検証
+		_, err := usecase.MarkOverdue(context.Background(), now)
+		assert.NoError(t, err)
+		assert.Equal(t, tags, overdue.Tags)
+		// タグを置き換えるUpdateは呼ばない
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("update error", func(t *testing.T) {
+		// 初期値の設定
+		overdue := task.NewTask("overdue", user.UserId(1), "2024-01-09")
+
+		// モック作成
+		mockRepo := new(MockTaskRepository)
+		mockRepo.On("FindIncompleteDueBy", mock.Anything).Return([]*task.Task{overdue}, nil)
+		mockRepo.On("UpdateReminderState", overdue).Return(assert.AnError)
+		usecase := usecase.NewReminderUsecase(mockRepo)
+
+		// 検証
+		_, err := usecase.MarkOverdue(context.Background(), now)
+		assert.ErrorIs(t, err, assert.AnError)
+	})
+}
@@ -1,71 +1,442 @@
 package usecase
 
 import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
 	"github.com/fuki01/onion-architecture/domain/repository"
 	"github.com/fuki01/onion-architecture/domain/task"
 	"github.com/fuki01/onion-architecture/domain/user"
+	"github.com/fuki01/onion-architecture/domain/webhook"
+	"github.com/fuki01/onion-architecture/domain/workspace"
 )
 
+// TaskUsecase はタスクの操作を提供する
+// actorは操作するユーザーで、操作が許可されていなければErrForbiddenを返す
+// ワークスペースのタスクはメンバーの役割で、それ以外のタスクは所有者か共有されたユーザーかで判断する
 type TaskUsecase interface {
-	CreateTask(name string, userId user.UserId, dueDate string) (task.TaskId, error)
-	ExtendDueDate(id task.TaskId, dueDate string) error
-	ChangeStatus(id task.TaskId, newStatus task.TaskStatus) error
-	GetTasksByUserId(userId user.UserId) ([]*task.Task, error)
+	CreateTask(ctx context.Context, input CreateTaskInput) (task.TaskId, error)
+	ExtendDueDate(ctx context.Context, actor user.UserId, id task.TaskId, dueDate string) error
+	ChangeStatus(ctx context.Context, actor user.UserId, id task.TaskId, newStatus task.TaskStatus) error
+	UpdateTask(ctx context.Context, actor user.UserId, id task.TaskId, patch task.Patch) (*task.Task, error)
+	AddSubtask(ctx context.Context, actor user.UserId, parentId task.TaskId, input CreateTaskInput) (task.TaskId, error)
+	ReorderSubtasks(ctx context.Context, actor user.UserId, parentId task.TaskId, ids []task.TaskId) error
+	GetSubtasks(ctx context.Context, actor user.UserId, parentId task.TaskId) ([]*task.Task, error)
+	AddDependency(ctx context.Context, actor user.UserId, id task.TaskId, blockedById task.TaskId) error
+	RemoveDependency(ctx context.Context, actor user.UserId, id task.TaskId, blockedById task.TaskId) error
+	GetDependencyChain(ctx context.Context, actor user.UserId, id task.TaskId) ([]*task.Task, error)
+	// GetTasksByUserId はユーザーが作成したタスクを取得する
+	GetTasksByUserId(ctx context.Context, actor user.UserId, userId user.UserId, filter task.Filter) ([]*task.Task, error)
+	// GetAssignedTasks はユーザーが担当するタスクを取得する
+	GetAssignedTasks(ctx context.Context, actor user.UserId, userId user.UserId, filter task.Filter) ([]*task.Task, error)
+	ShareTask(ctx context.Context, actor user.UserId, id task.TaskId, userId user.UserId) error
+	UnshareTask(ctx context.Context, actor user.UserId, id task.TaskId, userId user.UserId) error
+	// ReassignTask は担当者を変更し、変更履歴を記録する。assigneeIdがnilの場合は担当者を外す
+	ReassignTask(ctx context.Context, actor user.UserId, id task.TaskId, assigneeId *user.UserId) error
+	GetAssignmentHistory(ctx context.Context, actor user.UserId, id task.TaskId) ([]task.Assignment, error)
+	GetWorkspaceTasks(ctx context.Context, actor user.UserId, workspaceId workspace.WorkspaceId, filter task.Filter) ([]*task.Task, error)
+}
+
+// CreateTaskInput はタスク登録時の入力値
+// Priorityが空の場合は既定の優先度になる
+// WorkspaceIdを指定した場合はワークスペースのタスクとして登録する
+type CreateTaskInput struct {
+	Name            string
+	CreatedBy       user.UserId
+	WorkspaceId     *workspace.WorkspaceId
+	DueDate         string
+	Description     string
+	Priority        task.Priority
+	EstimateMinutes int
+	Tags            []string
+	Recurrence      string
 }
 
 type taskUsecase struct {
-	taskRepository repository.TaskRepository
+	taskRepository      repository.TaskRepository
+	workspaceRepository repository.WorkspaceRepository
+	userRepository      repository.UserRepository
+	delayPolicy         task.DelayPolicy
+	now                 func() time.Time
+	publisher           EventPublisher
+	metrics             TaskMetrics
+}
+
+// TaskMetrics はタスクの操作を数えるポート
+type TaskMetrics interface {
+	ObserveTaskOperation(eventType webhook.EventType)
 }
 
-func NewTaskUsecase(taskRepository repository.TaskRepository) TaskUsecase {
-	return &taskUsecase{
+func NewTaskUsecase(taskRepository repository.TaskRepository, opts ...TaskUsecaseOption) TaskUsecase {
+	tu := &taskUsecase{
 		taskRepository: taskRepository,
+		now:            time.Now,
 	}
+	for _, opt := range opts {
+		opt(tu)
+	}
+	return tu
 }
 
 // タスクを登録する
-func (tu *taskUsecase) CreateTask(name string, userId user.UserId, dueDate string) (task.TaskId, error) {
-	task := task.NewTask(name, userId, dueDate)
+// ワークスペースのタスクはタスクを編集できる役割が必要
+func (tu *taskUsecase) CreateTask(ctx context.Context, input CreateTaskInput) (task.TaskId, error) {
+	if input.WorkspaceId != nil {
+		if _, err := tu.authorizeWorkspace(input.CreatedBy, *input.WorkspaceId, workspace.ActionEditTasks); err != nil {
+			return 0, err
+		}
+	}
+
+	task, err := tu.newTask(input)
+	if err != nil {
+		return 0, err
+	}
 
 	if err := task.Validate(); err != nil {
 		return 0, err
 	}
 
-	task_id, err := tu.taskRepository.Insert(task)
+	task_id, err := tu.taskRepository.Insert(ctx, task)
 	if err != nil {
 		return 0, err
 	}
 
 	task.Id = task_id
+	tu.publish(ctx, webhook.EventTaskCreated, task)
 
 	return task.Id, nil
 }
 
 // タスクの期限を延長する
-func (tu *taskUsecase) ExtendDueDate(id task.TaskId, dueDate string) error {
-	task, err := tu.taskRepository.FindById(id)
+func (tu *taskUsecase) ExtendDueDate(ctx context.Context, actor user.UserId, id task.TaskId, dueDate string) error {
+	task, err := tu.findAuthorized(ctx, actor, id, workspace.ActionEditTasks)
 	if err != nil {
 		return err
 	}
-	task.DueDate = dueDate
-	task.DelayCount += 1
-	return tu.taskRepository.Update(task)
+	if err := task.ExtendDueDate(dueDate, tu.delayPolicy); err != nil {
+		return err
+	}
+	if err := tu.taskRepository.Update(ctx, task); err != nil {
+		return err
+	}
+	tu.publish(ctx, webhook.EventTaskExtended, task)
+	return nil
 }
 
 // タスクのステータスを変更する
-func (tu *taskUsecase) ChangeStatus(id task.TaskId, newStatus task.TaskStatus) error {
-	task, err := tu.taskRepository.FindById(id)
+func (tu *taskUsecase) ChangeStatus(ctx context.Context, actor user.UserId, id task.TaskId, newStatus task.TaskStatus) error {
+	task, err := tu.findAuthorized(ctx, actor, id, workspace.ActionEditTasks)
 	if err != nil {
 		return err
 	}
 	if err := task.SetStatus(newStatus); err != nil {
 		return err
 	}
-	return tu.taskRepository.Update(task)
+
+	// 繰り返しタスクは完了時に次回分を同じトランザクションで登録する
+	next, err := task.NextOccurrence()
+	if err != nil {
+		return err
+	}
+	if next == nil {
+		err = tu.taskRepository.Update(ctx, task)
+	} else {
+		err = tu.taskRepository.UpdateWithNext(ctx, task, next)
+	}
+	if err != nil {
+		return err
+	}
+	if task.IsCompleted() {
+		tu.publish(ctx, webhook.EventTaskCompleted, task)
+	}
+	if next != nil {
+		tu.publish(ctx, webhook.EventTaskCreated, next)
+	}
+	return nil
+}
+
+// タスクを部分更新する
+func (tu *taskUsecase) UpdateTask(ctx context.Context, actor user.UserId, id task.TaskId, patch task.Patch) (*task.Task, error) {
+	task, err := tu.findAuthorized(ctx, actor, id, workspace.ActionEditTasks)
+	if err != nil {
+		return nil, err
+	}
+	if err := task.Apply(patch); err != nil {
+		return nil, err
+	}
+	if err := tu.taskRepository.Update(ctx, task); err != nil {
+		return nil, err
+	}
+	task.EvaluateOverdue(tu.now())
+	return task, nil
+}
+
+// ユーザーが作成したタスク一覧を取得する
+// 他のユーザーの一覧は取得できない
+func (tu *taskUsecase) GetTasksByUserId(ctx context.Context, actor user.UserId, userId user.UserId, filter task.Filter) ([]*task.Task, error) {
+	if actor != userId {
+		return nil, task.ErrForbidden
+	}
+	now := tu.now()
+	if filter.Overdue {
+		filter.Now = now
+	}
+	tasks, err := tu.taskRepository.FindByCreatedBy(ctx, userId, filter)
+	if err != nil {
+		return nil, err
+	}
+	return evaluateOverdue(tasks, now), nil
+}
+
+// ユーザーが担当するタスク一覧を取得する
+// 他のユーザーの一覧は取得できない
+func (tu *taskUsecase) GetAssignedTasks(ctx context.Context, actor user.UserId, userId user.UserId, filter task.Filter) ([]*task.Task, error) {
+	if actor != userId {
+		return nil, task.ErrForbidden
+	}
+	now := tu.now()
+	if filter.Overdue {
+		filter.Now = now
+	}
+	tasks, err := tu.taskRepository.FindByAssigneeId(ctx, userId, filter)
+	if err != nil {
+		return nil, err
+	}
+	return evaluateOverdue(tasks, now), nil
+}
+
+// 子タスクを登録する
+func (tu *taskUsecase) AddSubtask(ctx context.Context, actor user.UserId, parentId task.TaskId, input CreateTaskInput) (task.TaskId, error) {
+	parent, err := tu.findAuthorized(ctx, actor, parentId, workspace.ActionEditTasks)
+	if err != nil {
+		return 0, err
+	}
+
+	child, err := tu.newTask(input)
+	if err != nil {
+		return 0, err
+	}
+	if err := parent.AddSubtask(child); err != nil {
+		return 0, err
+	}
+
+	id, err := tu.taskRepository.Insert(ctx, child)
+	if err != nil {
+		return 0, err
+	}
+	child.Id = id
+	tu.publish(ctx, webhook.EventTaskCreated, child)
+	return id, nil
+}
+
+// 子タスクを並べ替える
+func (tu *taskUsecase) ReorderSubtasks(ctx context.Context, actor user.UserId, parentId task.TaskId, ids []task.TaskId) error {
+	parent, err := tu.findAuthorized(ctx, actor, parentId, workspace.ActionEditTasks)
+	if err != nil {
+		return err
+	}
+	if err := parent.ReorderSubtasks(ids); err != nil {
+		return err
+	}
+	return tu.taskRepository.UpdatePositions(ctx, parent.Subtasks)
+}
+
+// 子タスク一覧を取得する
+func (tu *taskUsecase) GetSubtasks(ctx context.Context, actor user.UserId, parentId task.TaskId) ([]*task.Task, error) {
+	parent, err := tu.findAuthorized(ctx, actor, parentId, workspace.ActionViewTasks)
+	if err != nil {
+		return nil, err
+	}
+	return evaluateOverdue(parent.Subtasks, tu.now()), nil
+}
+
+// タスク間の依存関係を登録する
+// タスクを編集でき、依存先のタスクを参照できる必要がある
+func (tu *taskUsecase) AddDependency(ctx context.Context, actor user.UserId, id task.TaskId, blockedById task.TaskId) error {
+	if _, err := tu.findAuthorized(ctx, actor, id, workspace.ActionEditTasks); err != nil {
+		return err
+	}
+	if _, err := tu.findAuthorized(ctx, actor, blockedById, workspace.ActionViewTasks); err != nil {
+		return err
+	}
+
+	// 循環の確認は登録と同じトランザクションでリポジトリが行う
+	return tu.taskRepository.AddDependency(ctx, task.Dependency{TaskId: id, BlockedById: blockedById})
+}
+
+// タスク間の依存関係を削除する
+func (tu *taskUsecase) RemoveDependency(ctx context.Context, actor user.UserId, id task.TaskId, blockedById task.TaskId) error {
+	if _, err := tu.findAuthorized(ctx, actor, id, workspace.ActionEditTasks); err != nil {
+		return err
+	}
+	return tu.taskRepository.RemoveDependency(ctx, task.Dependency{TaskId: id, BlockedById: blockedById})
 }
 
+// タスクが依存するタスクを完了すべき順に取得する
+// 依存先は他のユーザーが追加できるため、参照できないタスクは含めない
+func (tu *taskUsecase) GetDependencyChain(ctx context.Context, actor user.UserId, id task.TaskId) ([]*task.Task, error) {
+	if _, err := tu.findAuthorized(ctx, actor, id, workspace.ActionViewTasks); err != nil {
+		return nil, err
+	}
+
+	deps, err := tu.taskRepository.FindDependencies(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	chain := task.NewDependencyGraph(deps).Chain(id)
+	tasks := make([]*task.Task, 0, len(chain))
+	for _, blockerId := range chain {
+		blocker, err := tu.taskRepository.FindById(ctx, blockerId)
+		if err != nil {
+			return nil, err
+		}
+		if err := tu.authorize(ctx, actor, blocker, workspace.ActionViewTasks); err != nil {
+			if errors.Is(err, task.ErrForbidden) {
+				continue
+			}
+			return nil, err
+		}
+		tasks = append(tasks, blocker)
+	}
+	return evaluateOverdue(tasks, tu.now()), nil
+}
 
-// タスク一覧をユーザーIDで取得する
-func (tu *taskUsecase) GetTasksByUserId(userId user.UserId) ([]*task.Task, error) {
-	return tu.taskRepository.FindByUserId(userId)
+// タスクを他のユーザーに共有する
+// 共有できるのは所有者のみ
+func (tu *taskUsecase) ShareTask(ctx context.Context, actor user.UserId, id task.TaskId, userId user.UserId) error {
+	t, err := tu.findOwned(ctx, actor, id)
+	if err != nil {
+		return err
+	}
+	share, err := t.ShareWith(userId)
+	if err != nil {
+		return err
+	}
+	return tu.taskRepository.AddShare(ctx, share)
+}
+
+// タスクの共有を解除する
+// 解除できるのは所有者のみ。解除したユーザーが担当者の場合は担当者を外す
+func (tu *taskUsecase) UnshareTask(ctx context.Context, actor user.UserId, id task.TaskId, userId user.UserId) error {
+	t, err := tu.findOwned(ctx, actor, id)
+	if err != nil {
+		return err
+	}
+	if t.AssigneeId != nil && *t.AssigneeId == userId {
+		assignment, err := t.Reassign(nil, nil, actor, tu.now())
+		if err != nil {
+			return err
+		}
+		if err := tu.taskRepository.Reassign(ctx, t, assignment); err != nil {
+			return err
+		}
+	}
+	return tu.taskRepository.RemoveShare(ctx, task.Share{TaskId: id, UserId: userId})
+}
+
+// 担当者を変更する
+// 担当者は存在するユーザーで、ワークスペースのタスクはワークスペースのメンバーである必要がある
+func (tu *taskUsecase) ReassignTask(ctx context.Context, actor user.UserId, id task.TaskId, assigneeId *user.UserId) error {
+	t, err := tu.taskRepository.FindById(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	var ws *workspace.Workspace
+	if t.WorkspaceId != nil {
+		ws, err = tu.authorizeWorkspace(actor, *t.WorkspaceId, workspace.ActionEditTasks)
+	} else {
+		err = tu.authorize(ctx, actor, t, workspace.ActionEditTasks)
+	}
+	if err != nil {
+		return err
+	}
+
+	if assigneeId != nil && tu.userRepository != nil {
+		if _, err := tu.userRepository.FindById(*assigneeId); err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return task.ErrAssigneeNotFound
+			}
+			return err
+		}
+	}
+
+	assignment, err := t.Reassign(assigneeId, ws, actor, tu.now())
+	if err != nil || assignment == nil {
+		return err
+	}
+	return tu.taskRepository.Reassign(ctx, t, assignment)
+}
+
+// 担当者の変更履歴を取得する
+func (tu *taskUsecase) GetAssignmentHistory(ctx context.Context, actor user.UserId, id task.TaskId) ([]task.Assignment, error) {
+	if _, err := tu.findAuthorized(ctx, actor, id, workspace.ActionViewTasks); err != nil {
+		return nil, err
+	}
+	return tu.taskRepository.FindAssignments(ctx, id)
+}
+
+// ワークスペースのタスク一覧を取得する
+func (tu *taskUsecase) GetWorkspaceTasks(ctx context.Context, actor user.UserId, workspaceId workspace.WorkspaceId, filter task.Filter) ([]*task.Task, error) {
+	if _, err := tu.authorizeWorkspace(actor, workspaceId, workspace.ActionViewTasks); err != nil {
+		return nil, err
+	}
+	now := tu.now()
+	if filter.Overdue {
+		filter.Now = now
+	}
+	tasks, err := tu.taskRepository.FindByWorkspaceId(ctx, workspaceId, filter)
+	if err != nil {
+		return nil, err
+	}
+	return evaluateOverdue(tasks, now), nil
+}
+
+// 操作を数え、イベントを送る
+// イベントはタスクを参照できるユーザーの送信先にのみ送る
+// 送信に失敗してもタスクの操作は失敗させない
+func (tu *taskUsecase) publish(ctx context.Context, eventType webhook.EventType, t *task.Task) {
+	if tu.metrics != nil {
+		tu.metrics.ObserveTaskOperation(eventType)
+	}
+	if tu.publisher == nil {
+		return
+	}
+	event := webhook.Event{Type: eventType, OccurredAt: tu.now(), Data: webhook.NewTaskData(t)}
+	audience := func() ([]user.UserId, error) {
+		return tu.viewers(ctx, t)
+	}
+	if err := tu.publisher.Publish(event, audience); err != nil {
+		slog.WarnContext(ctx, "failed to publish event", slog.String("event", string(eventType)), slog.Int("task_id", int(t.Id)), slog.Any("error", err))
+	}
+}
+
+// evaluateOverdue は取得したタスクにnowの時点で期限切れかどうかを設定する
+func evaluateOverdue(tasks []*task.Task, now time.Time) []*task.Task {
+	for _, t := range tasks {
+		t.EvaluateOverdue(now)
+	}
+	return tasks
+}
+
+// 入力値からタスクを生成する
+func (tu *taskUsecase) newTask(input CreateTaskInput) (*task.Task, error) {
+	t := task.NewTask(input.Name, input.CreatedBy, input.DueDate)
+	t.Description = input.Description
+	t.EstimateMinutes = input.EstimateMinutes
+	t.Recurrence = input.Recurrence
+	t.WorkspaceId = input.WorkspaceId
+	if input.Priority != "" {
+		t.Priority = input.Priority
+	}
+
+	tags, err := task.NewTags(input.Tags)
+	if err != nil {
+		return nil, err
+	}
+	t.Tags = tags
+	return t, nil
 }
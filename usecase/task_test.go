@@ -1,45 +1,137 @@
 package usecase_test
 
 import (
+	"context"
 	"errors"
 	"testing"
+	"time"
 
+	"github.com/fuki01/onion-architecture/domain/repository"
 	"github.com/fuki01/onion-architecture/domain/task"
 	"github.com/fuki01/onion-architecture/domain/user"
+	"github.com/fuki01/onion-architecture/domain/webhook"
+	"github.com/fuki01/onion-architecture/domain/workspace"
 	"github.com/fuki01/onion-architecture/usecase"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
 
+type MockEventPublisher struct {
+	mock.Mock
+}
+
+func (m *MockEventPublisher) Publish(event webhook.Event, audience usecase.Audience) error {
+	args := m.Called(event, audience)
+	return args.Error(0)
+}
+
+type MockTaskMetrics struct {
+	mock.Mock
+}
+
+func (m *MockTaskMetrics) ObserveTaskOperation(eventType webhook.EventType) {
+	m.Called(eventType)
+}
+
 type MockTaskRepository struct {
 	mock.Mock
 }
 
-func (m *MockTaskRepository) Insert(task *task.Task) (task.TaskId, error) {
+func (m *MockTaskRepository) FindIncompleteDueBy(ctx context.Context, dueDate string) ([]*task.Task, error) {
+	args := m.Called(dueDate)
+	return args.Get(0).([]*task.Task), args.Error(1)
+}
+
+func (m *MockTaskRepository) CountIncomplete(ctx context.Context, today string) (int64, int64, error) {
+	args := m.Called(today)
+	return args.Get(0).(int64), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockTaskRepository) Insert(ctx context.Context, task *task.Task) (task.TaskId, error) {
 	args := m.Called(task)
 	return 1, args.Error(1)
 }
 
-func (m *MockTaskRepository) FindById(id task.TaskId) (*task.Task, error) {
+func (m *MockTaskRepository) FindById(ctx context.Context, id task.TaskId) (*task.Task, error) {
 	args := m.Called(id)
 	return args.Get(0).(*task.Task), args.Error(1)
 }
 
-func (m *MockTaskRepository) FindByUserId(userId user.UserId) ([]*task.Task, error) {
-	args := m.Called(userId)
+func (m *MockTaskRepository) FindByCreatedBy(ctx context.Context, userId user.UserId, filter task.Filter) ([]*task.Task, error) {
+	args := m.Called(userId, filter)
 	return args.Get(0).([]*task.Task), args.Error(1)
 }
 
-func (m *MockTaskRepository) Update(task *task.Task) error {
+func (m *MockTaskRepository) FindByAssigneeId(ctx context.Context, userId user.UserId, filter task.Filter) ([]*task.Task, error) {
+	args := m.Called(userId, filter)
+	return args.Get(0).([]*task.Task), args.Error(1)
+}
+
+func (m *MockTaskRepository) FindByWorkspaceId(ctx context.Context, workspaceId workspace.WorkspaceId, filter task.Filter) ([]*task.Task, error) {
+	args := m.Called(workspaceId, filter)
+	return args.Get(0).([]*task.Task), args.Error(1)
+}
+
+func (m *MockTaskRepository) Update(ctx context.Context, task *task.Task) error {
+	args := m.Called(task)
+	return args.Error(0)
+}
+
+func (m *MockTaskRepository) UpdateReminderState(ctx context.Context, task *task.Task) error {
 	args := m.Called(task)
 	return args.Error(0)
 }
 
-func (m *MockTaskRepository) Delete(task *task.Task) error {
+func (m *MockTaskRepository) Delete(ctx context.Context, task *task.Task) error {
 	args := m.Called(task)
 	return args.Error(0)
 }
 
+func (m *MockTaskRepository) UpdatePositions(ctx context.Context, tasks []*task.Task) error {
+	args := m.Called(tasks)
+	return args.Error(0)
+}
+
+func (m *MockTaskRepository) UpdateWithNext(ctx context.Context, task *task.Task, next *task.Task) error {
+	args := m.Called(task, next)
+	return args.Error(0)
+}
+
+func (m *MockTaskRepository) FindDependencies(ctx context.Context, id task.TaskId) ([]task.Dependency, error) {
+	args := m.Called(id)
+	return args.Get(0).([]task.Dependency), args.Error(1)
+}
+
+func (m *MockTaskRepository) AddDependency(ctx context.Context, dep task.Dependency) error {
+	args := m.Called(dep)
+	return args.Error(0)
+}
+
+func (m *MockTaskRepository) RemoveDependency(ctx context.Context, dep task.Dependency) error {
+	args := m.Called(dep)
+	return args.Error(0)
+}
+
+func (m *MockTaskRepository) AddShare(ctx context.Context, share task.Share) error {
+	args := m.Called(share)
+	return args.Error(0)
+}
+
+func (m *MockTaskRepository) RemoveShare(ctx context.Context, share task.Share) error {
+	args := m.Called(share)
+	return args.Error(0)
+}
+
+func (m *MockTaskRepository) Reassign(ctx context.Context, t *task.Task, a *task.Assignment) error {
+	args := m.Called(t, a)
+	return args.Error(0)
+}
+
+func (m *MockTaskRepository) FindAssignments(ctx context.Context, id task.TaskId) ([]task.Assignment, error) {
+	args := m.Called(id)
+	return args.Get(0).([]task.Assignment), args.Error(1)
+}
+
 // タスクを作成する
 func TestCreateTask(t *testing.T) {
 	createMock := func(returnId task.TaskId, returnErr error) *MockTaskRepository {
@@ -53,10 +145,11 @@ func TestCreateTask(t *testing.T) {
 	}
 
 	t.Run("create", func(t *testing.T) {
+		input := usecase.CreateTaskInput{Name: "test", CreatedBy: user.UserId(1), DueDate: "2024-01-01"}
 		mockRepo := createMock(task.TaskId(1), nil)
 		usecase := createUsecase(mockRepo)
 
-		taskId, err := usecase.CreateTask("test", user.UserId(1), "2024-01-01")
+		taskId, err := usecase.CreateTask(context.Background(), input)
 
 		assert.NoError(t, err)
 		assert.Equal(t, task.TaskId(1), taskId)
@@ -64,19 +157,56 @@ func TestCreateTask(t *testing.T) {
 	})
 
 	t.Run("validate", func(t *testing.T) {
+		input := usecase.CreateTaskInput{Name: "", CreatedBy: user.UserId(1), DueDate: "2024-01-01"}
 		mockRepo := createMock(task.TaskId(1), nil)
 		usecase := createUsecase(mockRepo)
 
-		taskId, err := usecase.CreateTask("", user.UserId(1), "2024-01-01")
+		taskId, err := usecase.CreateTask(context.Background(), input)
 		assert.Error(t, err)
 		assert.Equal(t, task.TaskId(0), taskId)
 	})
 
+	t.Run("details", func(t *testing.T) {
+		mockRepo := new(MockTaskRepository)
+		mockRepo.On("Insert", mock.MatchedBy(func(created *task.Task) bool {
+			return created.Description == "# memo" &&
+				created.Priority == task.PriorityUrgent &&
+				created.EstimateMinutes == 90 &&
+				assert.ObjectsAreEqual([]string{"work", "review"}, created.TagNames())
+		})).Return(task.TaskId(1), nil)
+		input := usecase.CreateTaskInput{
+			Name:            "test",
+			CreatedBy:       user.UserId(1),
+			DueDate:         "2024-01-01",
+			Description:     "# memo",
+			Priority:        task.PriorityUrgent,
+			EstimateMinutes: 90,
+			Tags:            []string{"work", "review", "work"},
+		}
+		usecase := createUsecase(mockRepo)
+
+		_, err := usecase.CreateTask(context.Background(), input)
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("invalid priority", func(t *testing.T) {
+		input := usecase.CreateTaskInput{Name: "test", CreatedBy: user.UserId(1), DueDate: "2024-01-01", Priority: "critical"}
+		mockRepo := createMock(task.TaskId(1), nil)
+		usecase := createUsecase(mockRepo)
+
+		_, err := usecase.CreateTask(context.Background(), input)
+		assert.EqualError(t, err, "invalid priority")
+		mockRepo.AssertNotCalled(t, "Insert", mock.Anything)
+	})
+
 	t.Run("repository error", func(t *testing.T) {
+		input := usecase.CreateTaskInput{Name: "test", CreatedBy: user.UserId(1), DueDate: "2024-01-01"}
 		mockRepo := createMock(task.TaskId(0), errors.New("repository error"))
 		usecase := createUsecase(mockRepo)
 
-		taskId, err := usecase.CreateTask("test", user.UserId(1), "2024-01-01")
+		taskId, err := usecase.CreateTask(context.Background(), input)
 
 		assert.Error(t, err)
 		assert.Equal(t, task.TaskId(0), taskId)
@@ -107,7 +237,7 @@ func TestExtendDueDate(t *testing.T) {
 		usecase := createUsecase(mockRepo)
 
 		// 締切の延長
-		err := usecase.ExtendDueDate(task.TaskId(1), "2024-01-02")
+		err := usecase.ExtendDueDate(context.Background(), user.UserId(1), task.TaskId(1), "2024-01-02")
 
 		// 検証
 		assert.NoError(t, err)
@@ -126,7 +256,24 @@ func TestExtendDueDate(t *testing.T) {
 		usecase := createUsecase(mockRepo)
 
 		// 検証
-		assert.Error(t, usecase.ExtendDueDate(task.TaskId(1), "2024-01-02"))
+		assert.Error(t, usecase.ExtendDueDate(context.Background(), user.UserId(1), task.TaskId(1), "2024-01-02"))
+	})
+
+	t.Run("delay policy", func(t *testing.T) {
+		// 初期値の設定
+		existingTask := task.NewTask("test", user.UserId(1), "2024-01-01")
+		existingTask.Id = task.TaskId(1)
+		existingTask.DelayCount = 1
+
+		// モック作成
+		mockRepo := createMock(existingTask, nil, nil)
+		usecase := usecase.NewTaskUsecase(mockRepo, usecase.WithDelayPolicy(task.DelayPolicy{MaxExtensions: 1}))
+
+		// 検証
+		err := usecase.ExtendDueDate(context.Background(), user.UserId(1), task.TaskId(1), "2024-01-02")
+		var delayErr *task.DelayPolicyError
+		assert.ErrorAs(t, err, &delayErr)
+		mockRepo.AssertNotCalled(t, "Update", existingTask)
 	})
 
 	t.Run("update error", func(t *testing.T) {
@@ -139,7 +286,7 @@ func TestExtendDueDate(t *testing.T) {
 		usecase := createUsecase(mockRepo)
 
 		// 検証
-		assert.Error(t, usecase.ExtendDueDate(task.TaskId(1), "2024-01-02"))
+		assert.Error(t, usecase.ExtendDueDate(context.Background(), user.UserId(1), task.TaskId(1), "2024-01-02"))
 	})
 }
 
@@ -165,7 +312,7 @@ func TestChangeStatus(t *testing.T) {
 		usecase := createUsecase(mockRepo)
 
 		// ステータスの変更
-		err := usecase.ChangeStatus(task.TaskId(1), "完了")
+		err := usecase.ChangeStatus(context.Background(), user.UserId(1), task.TaskId(1), "完了")
 
 		// 検証
 		assert.NoError(t, err)
@@ -183,7 +330,7 @@ func TestChangeStatus(t *testing.T) {
 		usecase := createUsecase(mockRepo)
 
 		// 検証
-		assert.Error(t, usecase.ChangeStatus(task.TaskId(1), "完了"))
+		assert.Error(t, usecase.ChangeStatus(context.Background(), user.UserId(1), task.TaskId(1), "完了"))
 	})
 
 	t.Run("update error", func(t *testing.T) {
@@ -196,7 +343,28 @@ func TestChangeStatus(t *testing.T) {
 		usecase := createUsecase(mockRepo)
 
 		// 検証
-		assert.Error(t, usecase.ChangeStatus(task.TaskId(1), "完了"))
+		assert.Error(t, usecase.ChangeStatus(context.Background(), user.UserId(1), task.TaskId(1), "完了"))
+	})
+
+	t.Run("recurring task", func(t *testing.T) {
+		// 初期値の設定
+		existingTask := task.NewTask("weekly report", user.UserId(1), "2024-01-05")
+		existingTask.Id = task.TaskId(1)
+		existingTask.Recurrence = "FREQ=WEEKLY;BYDAY=FR"
+
+		// モック作成
+		mockRepo := new(MockTaskRepository)
+		mockRepo.On("FindById", existingTask.Id).Return(existingTask, nil)
+		mockRepo.On("UpdateWithNext", existingTask, mock.MatchedBy(func(next *task.Task) bool {
+			return next.DueDate == "2024-01-12" && next.Occurrence == 2 && next.Status == task.StatusIncomplete
+		})).Return(nil)
+		usecase := createUsecase(mockRepo)
+
+		// 検証
+		assert.NoError(t, usecase.ChangeStatus(context.Background(), user.UserId(1), task.TaskId(1), task.StatusComplete))
+		mockRepo.AssertExpectations(t)
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+		mockRepo.AssertNotCalled(t, "Insert", mock.Anything)
 	})
 
 	t.Run("invalid status", func(t *testing.T) {
@@ -207,19 +375,245 @@ func TestChangeStatus(t *testing.T) {
 		// モック作成
 		mockRepo := createMock(existingTask, nil, nil)
 		usecase := createUsecase(mockRepo)
-		usecase.ChangeStatus(task.TaskId(1), "完了")
+		usecase.ChangeStatus(context.Background(), user.UserId(1), task.TaskId(1), "完了")
 
-		err := usecase.ChangeStatus(task.TaskId(1), "未完了")
+		err := usecase.ChangeStatus(context.Background(), user.UserId(1), task.TaskId(1), "未完了")
 
 		// 検証
 		assert.Error(t, err)
 	})
 }
 
+func TestUpdateTask(t *testing.T) {
+	createMock := func(task *task.Task, findErr, updateErr error) *MockTaskRepository {
+		mockRepo := new(MockTaskRepository)
+		mockRepo.On("FindById", task.Id).Return(task, findErr)
+		mockRepo.On("Update", task).Return(updateErr)
+		return mockRepo
+	}
+
+	createUsecase := func(mock *MockTaskRepository) usecase.TaskUsecase {
+		return usecase.NewTaskUsecase(mock)
+	}
+
+	name := "renamed"
+
+	t.Run("success", func(t *testing.T) {
+		// 初期値の設定
+		existingTask := task.NewTask("test", user.UserId(1), "2024-01-01")
+		existingTask.Id = task.TaskId(1)
+
+		// モック作成
+		mockRepo := createMock(existingTask, nil, nil)
+		usecase := createUsecase(mockRepo)
+
+		// 部分更新
+		updated, err := usecase.UpdateTask(context.Background(), user.UserId(1), task.TaskId(1), task.Patch{Name: &name})
+
+		// 検証
+		assert.NoError(t, err)
+		assert.Equal(t, "renamed", updated.Name)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("validation error", func(t *testing.T) {
+		// 初期値の設定
+		existingTask := task.NewTask("test", user.UserId(1), "2024-01-01")
+		existingTask.Id = task.TaskId(1)
+		estimate := -1
+
+		// モック作成
+		mockRepo := createMock(existingTask, nil, nil)
+		usecase := createUsecase(mockRepo)
+
+		// 検証
+		_, err := usecase.UpdateTask(context.Background(), user.UserId(1), task.TaskId(1), task.Patch{EstimateMinutes: &estimate})
+		assert.EqualError(t, err, "invalid estimate")
+		mockRepo.AssertNotCalled(t, "Update", existingTask)
+	})
+
+	t.Run("completed task", func(t *testing.T) {
+		// 初期値の設定
+		existingTask := task.NewTask("test", user.UserId(1), "2024-01-01")
+		existingTask.Id = task.TaskId(1)
+		existingTask.Status = task.StatusComplete
+
+		// モック作成
+		mockRepo := createMock(existingTask, nil, nil)
+		usecase := createUsecase(mockRepo)
+
+		// 検証
+		_, err := usecase.UpdateTask(context.Background(), user.UserId(1), task.TaskId(1), task.Patch{Name: &name})
+		assert.ErrorIs(t, err, task.ErrCompletedTask)
+		mockRepo.AssertNotCalled(t, "Update", existingTask)
+	})
+}
+
+func TestSubtasks(t *testing.T) {
+	newParent := func() *task.Task {
+		parent := task.NewTask("parent", user.UserId(1), "2024-01-31")
+		parent.Id = task.TaskId(1)
+		return parent
+	}
+
+	t.Run("add subtask", func(t *testing.T) {
+		// 初期値の設定
+		parent := newParent()
+		input := usecase.CreateTaskInput{Name: "child", DueDate: "2024-01-10"}
+
+		// モック作成
+		mockRepo := new(MockTaskRepository)
+		mockRepo.On("FindById", task.TaskId(1)).Return(parent, nil)
+		mockRepo.On("Insert", mock.MatchedBy(func(child *task.Task) bool {
+			return *child.ParentId == task.TaskId(1) && child.CreatedBy == user.UserId(1)
+		})).Return(task.TaskId(2), nil)
+		usecase := usecase.NewTaskUsecase(mockRepo)
+
+		// 検証
+		_, err := usecase.AddSubtask(context.Background(), user.UserId(1), task.TaskId(1), input)
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("add to completed parent", func(t *testing.T) {
+		// 初期値の設定
+		parent := newParent()
+		parent.Status = task.StatusComplete
+		input := usecase.CreateTaskInput{Name: "child", DueDate: "2024-01-10"}
+
+		// モック作成
+		mockRepo := new(MockTaskRepository)
+		mockRepo.On("FindById", task.TaskId(1)).Return(parent, nil)
+		usecase := usecase.NewTaskUsecase(mockRepo)
+
+		// 検証
+		_, err := usecase.AddSubtask(context.Background(), user.UserId(1), task.TaskId(1), input)
+		assert.ErrorIs(t, err, task.ErrCompletedTask)
+		mockRepo.AssertNotCalled(t, "Insert", mock.Anything)
+	})
+
+	t.Run("reorder", func(t *testing.T) {
+		// 初期値の設定
+		parent := newParent()
+		first := &task.Task{Id: 2, Name: "first", Position: 0}
+		second := &task.Task{Id: 3, Name: "second", Position: 1}
+		parent.Subtasks = []*task.Task{first, second}
+
+		// モック作成
+		mockRepo := new(MockTaskRepository)
+		mockRepo.On("FindById", task.TaskId(1)).Return(parent, nil)
+		mockRepo.On("UpdatePositions", []*task.Task{second, first}).Return(nil)
+		usecase := usecase.NewTaskUsecase(mockRepo)
+
+		// 検証
+		assert.NoError(t, usecase.ReorderSubtasks(context.Background(), user.UserId(1), task.TaskId(1), []task.TaskId{3, 2}))
+		assert.Equal(t, 1, first.Position)
+		assert.Equal(t, 0, second.Position)
+		mockRepo.AssertExpectations(t)
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+	})
+
+	t.Run("complete parent with incomplete subtasks", func(t *testing.T) {
+		// 初期値の設定
+		parent := newParent()
+		parent.Subtasks = []*task.Task{{Id: 2, Name: "child", Status: task.StatusIncomplete}}
+
+		// モック作成
+		mockRepo := new(MockTaskRepository)
+		mockRepo.On("FindById", task.TaskId(1)).Return(parent, nil)
+		usecase := usecase.NewTaskUsecase(mockRepo)
+
+		// 検証
+		assert.ErrorIs(t, usecase.ChangeStatus(context.Background(), user.UserId(1), task.TaskId(1), task.StatusComplete), task.ErrIncompleteSubtasks)
+		mockRepo.AssertNotCalled(t, "Update", parent)
+	})
+}
+
+func TestDependencies(t *testing.T) {
+	createMock := func(deps []task.Dependency) *MockTaskRepository {
+		mockRepo := new(MockTaskRepository)
+		for i := 1; i <= 3; i++ {
+			existingTask := task.NewTask("test", user.UserId(1), "2024-01-01")
+			existingTask.Id = task.TaskId(i)
+			mockRepo.On("FindById", existingTask.Id).Return(existingTask, nil).Maybe()
+		}
+		mockRepo.On("FindDependencies", mock.Anything).Return(deps, nil).Maybe()
+		return mockRepo
+	}
+
+	t.Run("add dependency", func(t *testing.T) {
+		// モック作成
+		mockRepo := createMock([]task.Dependency{{TaskId: 2, BlockedById: 1}})
+		mockRepo.On("AddDependency", task.Dependency{TaskId: 3, BlockedById: 2}).Return(nil)
+		usecase := usecase.NewTaskUsecase(mockRepo)
+
+		// 検証
+		assert.NoError(t, usecase.AddDependency(context.Background(), user.UserId(1), task.TaskId(3), task.TaskId(2)))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("cycle", func(t *testing.T) {
+		// モック作成
+		mockRepo := createMock(nil)
+		mockRepo.On("AddDependency", task.Dependency{TaskId: 1, BlockedById: 3}).Return(task.ErrDependencyCycle)
+		usecase := usecase.NewTaskUsecase(mockRepo)
+
+		// 検証
+		assert.ErrorIs(t, usecase.AddDependency(context.Background(), user.UserId(1), task.TaskId(1), task.TaskId(3)), task.ErrDependencyCycle)
+	})
+
+	t.Run("chain", func(t *testing.T) {
+		// モック作成
+		mockRepo := createMock([]task.Dependency{{TaskId: 2, BlockedById: 1}, {TaskId: 3, BlockedById: 2}})
+		usecase := usecase.NewTaskUsecase(mockRepo)
+
+		// 検証
+		chain, err := usecase.GetDependencyChain(context.Background(), user.UserId(1), task.TaskId(3))
+		assert.NoError(t, err)
+		mockRepo.AssertCalled(t, "FindDependencies", task.TaskId(3))
+		assert.Len(t, chain, 2)
+		assert.Equal(t, task.TaskId(1), chain[0].Id)
+		assert.Equal(t, task.TaskId(2), chain[1].Id)
+	})
+
+	t.Run("chain omits tasks the actor cannot view", func(t *testing.T) {
+		// 初期値の設定
+		hidden := task.NewTask("other user's task", user.UserId(2), "2024-01-01")
+		hidden.Id = task.TaskId(5)
+
+		// モック作成
+		mockRepo := createMock([]task.Dependency{{TaskId: 2, BlockedById: 5}, {TaskId: 3, BlockedById: 2}})
+		mockRepo.On("FindById", hidden.Id).Return(hidden, nil)
+		usecase := usecase.NewTaskUsecase(mockRepo)
+
+		// 検証
+		chain, err := usecase.GetDependencyChain(context.Background(), user.UserId(1), task.TaskId(3))
+		assert.NoError(t, err)
+		assert.Len(t, chain, 1)
+		assert.Equal(t, task.TaskId(2), chain[0].Id)
+	})
+
+	t.Run("complete with open blockers", func(t *testing.T) {
+		// 初期値の設定
+		blocked := task.NewTask("blocked", user.UserId(1), "2024-01-01")
+		blocked.Id = task.TaskId(4)
+		blocked.BlockedBy = []*task.Task{task.NewTask("blocker", user.UserId(1), "2024-01-01")}
+
+		// モック作成
+		mockRepo := new(MockTaskRepository)
+		mockRepo.On("FindById", task.TaskId(4)).Return(blocked, nil)
+		usecase := usecase.NewTaskUsecase(mockRepo)
+
+		// 検証
+		assert.ErrorIs(t, usecase.ChangeStatus(context.Background(), user.UserId(1), task.TaskId(4), task.StatusComplete), task.ErrOpenBlockers)
+		mockRepo.AssertNotCalled(t, "Update", blocked)
+	})
+}
+
 func TestGetTasksByUserId(t *testing.T) {
 	createMock := func(tasks []*task.Task, returnErr error) *MockTaskRepository {
 		mockRepo := new(MockTaskRepository)
-		mockRepo.On("FindByUserId", mock.AnythingOfType("user.UserId")).Return(tasks, returnErr)
+		mockRepo.On("FindByCreatedBy", mock.AnythingOfType("user.UserId"), mock.AnythingOfType("task.Filter")).Return(tasks, returnErr)
 		return mockRepo
 	}
 
@@ -239,22 +633,352 @@ func TestGetTasksByUserId(t *testing.T) {
 		usecase := createUsecase(mockRepo)
 
 		// 検証
-		result, err := usecase.GetTasksByUserId(user.UserId(1))
+		result, err := usecase.GetTasksByUserId(context.Background(), user.UserId(1), user.UserId(1), task.Filter{})
 		assert.NoError(t, err)
 		assert.Equal(t, tasks, result)
 		mockRepo.AssertExpectations(t)
 	})
 
+	t.Run("overdue", func(t *testing.T) {
+		now := time.Date(2024, 1, 10, 9, 0, 0, 0, time.UTC)
+
+		// モック作成
+		mockRepo := new(MockTaskRepository)
+		mockRepo.On("FindByCreatedBy", user.UserId(1), task.Filter{Overdue: true, Now: now}).Return([]*task.Task{}, nil)
+		usecase := usecase.NewTaskUsecase(mockRepo, usecase.WithClock(func() time.Time { return now }))
+
+		// 検証
+		_, err := usecase.GetTasksByUserId(context.Background(), user.UserId(1), user.UserId(1), task.Filter{Overdue: true})
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("is overdue uses the usecase clock", func(t *testing.T) {
+		// 初期値の設定
+		now := time.Date(2024, 1, 10, 9, 0, 0, 0, time.UTC)
+		overdue := task.NewTask("overdue", user.UserId(1), "2024-01-09")
+		dueToday := task.NewTask("due today", user.UserId(1), "2024-01-10")
+
+		// モック作成
+		mockRepo := createMock([]*task.Task{overdue, dueToday}, nil)
+		usecase := usecase.NewTaskUsecase(mockRepo, usecase.WithClock(func() time.Time { return now }))
+
+		// 検証
+		result, err := usecase.GetTasksByUserId(context.Background(), user.UserId(1), user.UserId(1), task.Filter{})
+		assert.NoError(t, err)
+		assert.True(t, result[0].Overdue)
+		assert.False(t, result[1].Overdue)
+	})
+
 	t.Run("error", func(t *testing.T) {
 		// モック作成
 		mockRepo := createMock(nil, errors.New("repository error"))
 		usecase := createUsecase(mockRepo)
 
 		// 検証
-		result, err := usecase.GetTasksByUserId(user.UserId(1))
+		result, err := usecase.GetTasksByUserId(context.Background(), user.UserId(1), user.UserId(1), task.Filter{})
 		assert.Error(t, err)
 		assert.Nil(t, result)
 		assert.Contains(t, err.Error(), "repository error")
 		mockRepo.AssertExpectations(t)
 	})
 }
+
+// 担当者を変更する
+func TestReassignTask(t *testing.T) {
+	now := time.Date(2024, 1, 5, 9, 0, 0, 0, time.UTC)
+	owner := user.UserId(1)
+	shared := user.UserId(2)
+
+	newSharedTask := func() *task.Task {
+		t := task.NewTask("test", owner, "2024-01-10")
+		t.Id = task.TaskId(1)
+		t.Shares = []task.Share{{TaskId: 1, UserId: shared}}
+		return t
+	}
+
+	t.Run("history is recorded", func(t *testing.T) {
+		// 初期値の設定
+		existingTask := newSharedTask()
+
+		// モック作成
+		mockRepo := new(MockTaskRepository)
+		mockRepo.On("FindById", task.TaskId(1)).Return(existingTask, nil)
+		mockRepo.On("Reassign", existingTask, &task.Assignment{TaskId: 1, ToUserId: &shared, ChangedBy: owner, ChangedAt: now}).Return(nil)
+		users := new(MockUserRepository)
+		users.On("FindById", shared).Return(&user.User{Id: shared}, nil)
+		usecase := usecase.NewTaskUsecase(mockRepo, usecase.WithUserRepository(users), usecase.WithClock(func() time.Time { return now }))
+
+		// 検証
+		assert.NoError(t, usecase.ReassignTask(context.Background(), owner, task.TaskId(1), &shared))
+		assert.Equal(t, owner, existingTask.CreatedBy)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("unchanged assignee is not recorded", func(t *testing.T) {
+		// 初期値の設定
+		existingTask := newSharedTask()
+		existingTask.AssigneeId = &shared
+
+		// モック作成
+		mockRepo := new(MockTaskRepository)
+		mockRepo.On("FindById", task.TaskId(1)).Return(existingTask, nil)
+		usecase := usecase.NewTaskUsecase(mockRepo)
+
+		// 検証
+		assignee := shared
+		assert.NoError(t, usecase.ReassignTask(context.Background(), owner, task.TaskId(1), &assignee))
+		mockRepo.AssertNotCalled(t, "Reassign", mock.Anything, mock.Anything)
+	})
+
+	t.Run("unknown assignee", func(t *testing.T) {
+		// 初期値の設定
+		unknown := user.UserId(9)
+
+		// モック作成
+		mockRepo := new(MockTaskRepository)
+		mockRepo.On("FindById", task.TaskId(1)).Return(newSharedTask(), nil)
+		users := new(MockUserRepository)
+		users.On("FindById", unknown).Return(nil, repository.ErrNotFound)
+		usecase := usecase.NewTaskUsecase(mockRepo, usecase.WithUserRepository(users))
+
+		// 検証
+		assert.ErrorIs(t, usecase.ReassignTask(context.Background(), owner, task.TaskId(1), &unknown), task.ErrAssigneeNotFound)
+		mockRepo.AssertNotCalled(t, "Reassign", mock.Anything, mock.Anything)
+	})
+
+	t.Run("stranger cannot be assigned to personal task", func(t *testing.T) {
+		// 初期値の設定
+		stranger := user.UserId(3)
+
+		// モック作成
+		mockRepo := new(MockTaskRepository)
+		mockRepo.On("FindById", task.TaskId(1)).Return(newSharedTask(), nil)
+		users := new(MockUserRepository)
+		users.On("FindById", stranger).Return(&user.User{Id: stranger}, nil)
+		usecase := usecase.NewTaskUsecase(mockRepo, usecase.WithUserRepository(users))
+
+		// 検証
+		var validationErr *task.ValidationError
+		assert.ErrorAs(t, usecase.ReassignTask(context.Background(), owner, task.TaskId(1), &stranger), &validationErr)
+		mockRepo.AssertNotCalled(t, "Reassign", mock.Anything, mock.Anything)
+	})
+
+	t.Run("unshare clears assignee", func(t *testing.T) {
+		// 初期値の設定
+		existingTask := newSharedTask()
+		existingTask.AssigneeId = &shared
+
+		// モック作成
+		mockRepo := new(MockTaskRepository)
+		mockRepo.On("FindById", task.TaskId(1)).Return(existingTask, nil)
+		mockRepo.On("Reassign", existingTask, &task.Assignment{TaskId: 1, FromUserId: &shared, ChangedBy: owner, ChangedAt: now}).Return(nil)
+		mockRepo.On("RemoveShare", task.Share{TaskId: 1, UserId: shared}).Return(nil)
+		usecase := usecase.NewTaskUsecase(mockRepo, usecase.WithClock(func() time.Time { return now }))
+
+		// 検証
+		assert.NoError(t, usecase.UnshareTask(context.Background(), owner, task.TaskId(1), shared))
+		assert.Nil(t, existingTask.AssigneeId)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("unshare keeps other assignee", func(t *testing.T) {
+		// 初期値の設定
+		existingTask := newSharedTask()
+		existingTask.AssigneeId = &owner
+
+		// モック作成
+		mockRepo := new(MockTaskRepository)
+		mockRepo.On("FindById", task.TaskId(1)).Return(existingTask, nil)
+		mockRepo.On("RemoveShare", task.Share{TaskId: 1, UserId: shared}).Return(nil)
+		usecase := usecase.NewTaskUsecase(mockRepo)
+
+		// 検証
+		assert.NoError(t, usecase.UnshareTask(context.Background(), owner, task.TaskId(1), shared))
+		assert.Equal(t, &owner, existingTask.AssigneeId)
+		mockRepo.AssertNotCalled(t, "Reassign", mock.Anything, mock.Anything)
+	})
+
+	t.Run("history", func(t *testing.T) {
+		// 初期値の設定
+		history := []task.Assignment{{Id: 1, TaskId: 1, ToUserId: &shared, ChangedBy: owner, ChangedAt: now}}
+
+		// モック作成
+		mockRepo := new(MockTaskRepository)
+		mockRepo.On("FindById", task.TaskId(1)).Return(newSharedTask(), nil)
+		mockRepo.On("FindAssignments", task.TaskId(1)).Return(history, nil)
+		usecase := usecase.NewTaskUsecase(mockRepo)
+
+		// 検証
+		result, err := usecase.GetAssignmentHistory(context.Background(), shared, task.TaskId(1))
+		assert.NoError(t, err)
+		assert.Equal(t, history, result)
+		_, err = usecase.GetAssignmentHistory(context.Background(), user.UserId(3), task.TaskId(1))
+		assert.ErrorIs(t, err, task.ErrForbidden)
+	})
+
+	t.Run("assigned tasks", func(t *testing.T) {
+		// モック作成
+		mockRepo := new(MockTaskRepository)
+		mockRepo.On("FindByAssigneeId", shared, task.Filter{}).Return([]*task.Task{newSharedTask()}, nil)
+		usecase := usecase.NewTaskUsecase(mockRepo)
+
+		// 検証
+		result, err := usecase.GetAssignedTasks(context.Background(), shared, shared, task.Filter{})
+		assert.NoError(t, err)
+		assert.Len(t, result, 1)
+		_, err = usecase.GetAssignedTasks(context.Background(), owner, shared, task.Filter{})
+		assert.ErrorIs(t, err, task.ErrForbidden)
+	})
+}
+
+func TestPublishTaskEvents(t *testing.T) {
+	now := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	eventOf := func(eventType webhook.EventType) interface{} {
+		return mock.MatchedBy(func(e webhook.Event) bool {
+			return e.Type == eventType && e.OccurredAt.Equal(now)
+		})
+	}
+
+	t.Run("created", func(t *testing.T) {
+		// モック作成
+		mockRepo := new(MockTaskRepository)
+		mockRepo.On("Insert", mock.AnythingOfType("*task.Task")).Return(task.TaskId(1), nil)
+		publisher := new(MockEventPublisher)
+		publisher.On("Publish", eventOf(webhook.EventTaskCreated), mock.Anything).Return(nil)
+		input := usecase.CreateTaskInput{Name: "test", CreatedBy: 1, DueDate: "2024-01-01"}
+		usecase := usecase.NewTaskUsecase(mockRepo, usecase.WithClock(func() time.Time { return now }), usecase.WithEventPublisher(publisher))
+
+		// 検証
+		_, err := usecase.CreateTask(context.Background(), input)
+		assert.NoError(t, err)
+		publisher.AssertExpectations(t)
+	})
+
+	t.Run("extended", func(t *testing.T) {
+		// 初期値の設定
+		existingTask := task.NewTask("test", user.UserId(1), "2024-01-01")
+		existingTask.Id = task.TaskId(1)
+
+		// モック作成
+		mockRepo := new(MockTaskRepository)
+		mockRepo.On("FindById", existingTask.Id).Return(existingTask, nil)
+		mockRepo.On("Update", existingTask).Return(nil)
+		publisher := new(MockEventPublisher)
+		publisher.On("Publish", eventOf(webhook.EventTaskExtended), mock.Anything).Return(errors.New("publish error"))
+		usecase := usecase.NewTaskUsecase(mockRepo, usecase.WithClock(func() time.Time { return now }), usecase.WithEventPublisher(publisher))
+
+		// 発行に失敗しても延長は成功する
+		assert.NoError(t, usecase.ExtendDueDate(context.Background(), user.UserId(1), existingTask.Id, "2024-01-02"))
+		publisher.AssertExpectations(t)
+	})
+
+	t.Run("completed only", func(t *testing.T) {
+		// 初期値の設定
+		existingTask := task.NewTask("test", user.UserId(1), "2024-01-01")
+		existingTask.Id = task.TaskId(1)
+
+		// モック作成
+		mockRepo := new(MockTaskRepository)
+		mockRepo.On("FindById", existingTask.Id).Return(existingTask, nil)
+		mockRepo.On("Update", existingTask).Return(nil)
+		publisher := new(MockEventPublisher)
+		publisher.On("Publish", eventOf(webhook.EventTaskCompleted), mock.Anything).Return(nil)
+		usecase := usecase.NewTaskUsecase(mockRepo, usecase.WithClock(func() time.Time { return now }), usecase.WithEventPublisher(publisher))
+
+		// 検証
+		assert.NoError(t, usecase.ChangeStatus(context.Background(), user.UserId(1), existingTask.Id, task.StatusIncomplete))
+		publisher.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+		assert.NoError(t, usecase.ChangeStatus(context.Background(), user.UserId(1), existingTask.Id, "完了"))
+		publisher.AssertExpectations(t)
+	})
+
+	t.Run("created for subtasks", func(t *testing.T) {
+		// 初期値の設定
+		parent := task.NewTask("parent", user.UserId(1), "2024-01-31")
+		parent.Id = task.TaskId(1)
+
+		// モック作成
+		mockRepo := new(MockTaskRepository)
+		mockRepo.On("FindById", parent.Id).Return(parent, nil)
+		mockRepo.On("Insert", mock.AnythingOfType("*task.Task")).Return(task.TaskId(2), nil)
+		publisher := new(MockEventPublisher)
+		publisher.On("Publish", mock.MatchedBy(func(e webhook.Event) bool {
+			data, ok := e.Data.(webhook.TaskData)
+			return e.Type == webhook.EventTaskCreated && ok && data.ParentId != nil && *data.ParentId == parent.Id
+		}), mock.Anything).Return(nil)
+		input := usecase.CreateTaskInput{Name: "child", DueDate: "2024-01-10"}
+		usecase := usecase.NewTaskUsecase(mockRepo, usecase.WithClock(func() time.Time { return now }), usecase.WithEventPublisher(publisher))
+
+		// 検証
+		_, err := usecase.AddSubtask(context.Background(), user.UserId(1), parent.Id, input)
+		assert.NoError(t, err)
+		publisher.AssertExpectations(t)
+	})
+
+	t.Run("created for the next occurrence", func(t *testing.T) {
+		// 初期値の設定
+		existingTask := task.NewTask("weekly report", user.UserId(1), "2024-01-05")
+		existingTask.Id = task.TaskId(1)
+		existingTask.Recurrence = "FREQ=WEEKLY;BYDAY=FR"
+
+		// モック作成
+		mockRepo := new(MockTaskRepository)
+		mockRepo.On("FindById", existingTask.Id).Return(existingTask, nil)
+		mockRepo.On("UpdateWithNext", existingTask, mock.AnythingOfType("*task.Task")).Return(nil)
+		publisher := new(MockEventPublisher)
+		publisher.On("Publish", eventOf(webhook.EventTaskCompleted), mock.Anything).Return(nil).Once()
+		publisher.On("Publish", eventOf(webhook.EventTaskCreated), mock.Anything).Return(nil).Once()
+		usecase := usecase.NewTaskUsecase(mockRepo, usecase.WithClock(func() time.Time { return now }), usecase.WithEventPublisher(publisher))
+
+		// 検証
+		assert.NoError(t, usecase.ChangeStatus(context.Background(), user.UserId(1), existingTask.Id, task.StatusComplete))
+		publisher.AssertExpectations(t)
+	})
+
+	t.Run("sent only to users who can view the task", func(t *testing.T) {
+		// 初期値の設定
+		existingTask := task.NewTask("test", user.UserId(1), "2024-01-01")
+		existingTask.Id = task.TaskId(1)
+		existingTask.Shares = []task.Share{{TaskId: 1, UserId: 2}}
+
+		// モック作成
+		mockRepo := new(MockTaskRepository)
+		mockRepo.On("FindById", existingTask.Id).Return(existingTask, nil)
+		mockRepo.On("Update", existingTask).Return(nil)
+		var audience usecase.Audience
+		publisher := new(MockEventPublisher)
+		publisher.On("Publish", mock.MatchedBy(func(e webhook.Event) bool {
+			data, ok := e.Data.(webhook.TaskData)
+			return ok && data.Id == existingTask.Id
+		}), mock.Anything).Run(func(args mock.Arguments) {
+			audience = args.Get(1).(usecase.Audience)
+		}).Return(nil)
+		usecase := usecase.NewTaskUsecase(mockRepo, usecase.WithClock(func() time.Time { return now }), usecase.WithEventPublisher(publisher))
+
+		// 検証
+		assert.NoError(t, usecase.ExtendDueDate(context.Background(), user.UserId(2), existingTask.Id, "2024-01-02"))
+		publisher.AssertExpectations(t)
+		ids, err := audience()
+		assert.NoError(t, err)
+		assert.Equal(t, []user.UserId{1, 2}, ids)
+	})
+
+	t.Run("counted without publisher", func(t *testing.T) {
+		// 初期値の設定
+		existingTask := task.NewTask("test", user.UserId(1), "2024-01-01")
+		existingTask.Id = task.TaskId(1)
+
+		// モック作成
+		mockRepo := new(MockTaskRepository)
+		mockRepo.On("FindById", existingTask.Id).Return(existingTask, nil)
+		mockRepo.On("Update", existingTask).Return(nil)
+		metrics := new(MockTaskMetrics)
+		metrics.On("ObserveTaskOperation", webhook.EventTaskExtended).Return()
+		usecase := usecase.NewTaskUsecase(mockRepo, usecase.WithTaskMetrics(metrics))
+
+		// 検証
+		assert.NoError(t, usecase.ExtendDueDate(context.Background(), user.UserId(1), existingTask.Id, "2024-01-02"))
+		metrics.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,144 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/fuki01/onion-architecture/domain/task"
+	"github.com/fuki01/onion-architecture/domain/user"
+	"github.com/fuki01/onion-architecture/domain/workspace"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracedTaskUsecase はTaskUsecaseの操作ごとにスパンを記録する
+type tracedTaskUsecase struct {
+	next   TaskUsecase
+	tracer trace.Tracer
+}
+
+// TraceTaskUsecase はTaskUsecaseの操作ごとにスパンを記録するTaskUsecaseを返す
+func TraceTaskUsecase(next TaskUsecase, tracer trace.Tracer) TaskUsecase {
+	return &tracedTaskUsecase{next: next, tracer: tracer}
+}
+
+func (tt *tracedTaskUsecase) start(ctx context.Context, name string, actor user.UserId, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	attrs = append(attrs, attribute.Int("user.id", int(actor)))
+	return tt.tracer.Start(ctx, "TaskUsecase."+name, trace.WithAttributes(attrs...))
+}
+
+// end はエラーを記録してスパンを終了する
+// 利用者に起因するエラーかはHTTPのスパンのステータスコードで判断する
+func end(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+func taskIdAttr(id task.TaskId) attribute.KeyValue {
+	return attribute.Int("task.id", int(id))
+}
+
+func (tt *tracedTaskUsecase) CreateTask(ctx context.Context, input CreateTaskInput) (id task.TaskId, err error) {
+	ctx, span := tt.start(ctx, "CreateTask", input.CreatedBy)
+	defer func() { span.SetAttributes(taskIdAttr(id)); end(span, err) }()
+	return tt.next.CreateTask(ctx, input)
+}
+
+func (tt *tracedTaskUsecase) ExtendDueDate(ctx context.Context, actor user.UserId, id task.TaskId, dueDate string) (err error) {
+	ctx, span := tt.start(ctx, "ExtendDueDate", actor, taskIdAttr(id))
+	defer func() { end(span, err) }()
+	return tt.next.ExtendDueDate(ctx, actor, id, dueDate)
+}
+
+func (tt *tracedTaskUsecase) ChangeStatus(ctx context.Context, actor user.UserId, id task.TaskId, newStatus task.TaskStatus) (err error) {
+	ctx, span := tt.start(ctx, "ChangeStatus", actor, taskIdAttr(id))
+	defer func() { end(span, err) }()
+	return tt.next.ChangeStatus(ctx, actor, id, newStatus)
+}
+
+func (tt *tracedTaskUsecase) UpdateTask(ctx context.Context, actor user.UserId, id task.TaskId, patch task.Patch) (t *task.Task, err error) {
+	ctx, span := tt.start(ctx, "UpdateTask", actor, taskIdAttr(id))
+	defer func() { end(span, err) }()
+	return tt.next.UpdateTask(ctx, actor, id, patch)
+}
+
+func (tt *tracedTaskUsecase) AddSubtask(ctx context.Context, actor user.UserId, parentId task.TaskId, input CreateTaskInput) (id task.TaskId, err error) {
+	ctx, span := tt.start(ctx, "AddSubtask", actor, taskIdAttr(parentId))
+	defer func() { end(span, err) }()
+	return tt.next.AddSubtask(ctx, actor, parentId, input)
+}
+
+func (tt *tracedTaskUsecase) ReorderSubtasks(ctx context.Context, actor user.UserId, parentId task.TaskId, ids []task.TaskId) (err error) {
+	ctx, span := tt.start(ctx, "ReorderSubtasks", actor, taskIdAttr(parentId))
+	defer func() { end(span, err) }()
+	return tt.next.ReorderSubtasks(ctx, actor, parentId, ids)
+}
+
+func (tt *tracedTaskUsecase) GetSubtasks(ctx context.Context, actor user.UserId, parentId task.TaskId) (tasks []*task.Task, err error) {
+	ctx, span := tt.start(ctx, "GetSubtasks", actor, taskIdAttr(parentId))
+	defer func() { end(span, err) }()
+	return tt.next.GetSubtasks(ctx, actor, parentId)
+}
+
+func (tt *tracedTaskUsecase) AddDependency(ctx context.Context, actor user.UserId, id task.TaskId, blockedById task.TaskId) (err error) {
+	ctx, span := tt.start(ctx, "AddDependency", actor, taskIdAttr(id))
+	defer func() { end(span, err) }()
+	return tt.next.AddDependency(ctx, actor, id, blockedById)
+}
+
+func (tt *tracedTaskUsecase) RemoveDependency(ctx context.Context, actor user.UserId, id task.TaskId, blockedById task.TaskId) (err error) {
+	ctx, span := tt.start(ctx, "RemoveDependency", actor, taskIdAttr(id))
+	defer func() { end(span, err) }()
+	return tt.next.RemoveDependency(ctx, actor, id, blockedById)
+}
+
+func (tt *tracedTaskUsecase) GetDependencyChain(ctx context.Context, actor user.UserId, id task.TaskId) (tasks []*task.Task, err error) {
+	ctx, span := tt.start(ctx, "GetDependencyChain", actor, taskIdAttr(id))
+	defer func() { end(span, err) }()
+	return tt.next.GetDependencyChain(ctx, actor, id)
+}
+
+func (tt *tracedTaskUsecase) GetTasksByUserId(ctx context.Context, actor user.UserId, userId user.UserId, filter task.Filter) (tasks []*task.Task, err error) {
+	ctx, span := tt.start(ctx, "GetTasksByUserId", actor)
+	defer func() { end(span, err) }()
+	return tt.next.GetTasksByUserId(ctx, actor, userId, filter)
+}
+
+func (tt *tracedTaskUsecase) GetAssignedTasks(ctx context.Context, actor user.UserId, userId user.UserId, filter task.Filter) (tasks []*task.Task, err error) {
+	ctx, span := tt.start(ctx, "GetAssignedTasks", actor)
+	defer func() { end(span, err) }()
+	return tt.next.GetAssignedTasks(ctx, actor, userId, filter)
+}
+
+func (tt *tracedTaskUsecase) ShareTask(ctx context.Context, actor user.UserId, id task.TaskId, userId user.UserId) (err error) {
+	ctx, span := tt.start(ctx, "ShareTask", actor, taskIdAttr(id))
+	defer func() { end(span, err) }()
+	return tt.next.ShareTask(ctx, actor, id, userId)
+}
+
+func (tt *tracedTaskUsecase) UnshareTask(ctx context.Context, actor user.UserId, id task.TaskId, userId user.UserId) (err error) {
+	ctx, span := tt.start(ctx, "UnshareTask", actor, taskIdAttr(id))
+	defer func() { end(span, err) }()
+	return tt.next.UnshareTask(ctx, actor, id, userId)
+}
+
+func (tt *tracedTaskUsecase) ReassignTask(ctx context.Context, actor user.UserId, id task.TaskId, assigneeId *user.UserId) (err error) {
+	ctx, span := tt.start(ctx, "ReassignTask", actor, taskIdAttr(id))
+	defer func() { end(span, err) }()
+	return tt.next.ReassignTask(ctx, actor, id, assigneeId)
+}
+
+func (tt *tracedTaskUsecase) GetAssignmentHistory(ctx context.Context, actor user.UserId, id task.TaskId) (history []task.Assignment, err error) {
+	ctx, span := tt.start(ctx, "GetAssignmentHistory", actor, taskIdAttr(id))
+	defer func() { end(span, err) }()
+	return tt.next.GetAssignmentHistory(ctx, actor, id)
+}
+
+func (tt *tracedTaskUsecase) GetWorkspaceTasks(ctx context.Context, actor user.UserId, workspaceId workspace.WorkspaceId, filter task.Filter) (tasks []*task.Task, err error) {
+	ctx, span := tt.start(ctx, "GetWorkspaceTasks", actor, attribute.Int("workspace.id", int(workspaceId)))
+	defer func() { end(span, err) }()
+	return tt.next.GetWorkspaceTasks(ctx, actor, workspaceId, filter)
+}
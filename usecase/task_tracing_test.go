@@ -0,0 +1,62 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fuki01/onion-architecture/domain/repository"
+	"github.com/fuki01/onion-architecture/domain/task"
+	"github.com/fuki01/onion-architecture/domain/user"
+	"github.com/fuki01/onion-architecture/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// 操作ごとにスパンを記録する
+func TestTraceTaskUsecase(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+
+	t.Run("create", func(t *testing.T) {
+		input := usecase.CreateTaskInput{Name: "test", CreatedBy: user.UserId(1), DueDate: "2024-01-01"}
+		// モック作成
+		mockRepo := new(MockTaskRepository)
+		mockRepo.On("Insert", mock.AnythingOfType("*task.Task")).Return(task.TaskId(1), nil)
+		usecase := usecase.TraceTaskUsecase(usecase.NewTaskUsecase(mockRepo), tracer)
+
+		ctx, parent := tracer.Start(context.Background(), "request")
+		id, err := usecase.CreateTask(ctx, input)
+		parent.End()
+
+		// 検証
+		require.NoError(t, err)
+		assert.Equal(t, task.TaskId(1), id)
+		span := recorder.Ended()[0]
+		assert.Equal(t, "TaskUsecase.CreateTask", span.Name())
+		assert.Equal(t, parent.SpanContext().SpanID(), span.Parent().SpanID())
+		assert.Contains(t, span.Attributes(), attribute.Int("task.id", 1))
+		assert.Equal(t, codes.Unset, span.Status().Code)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		// モック作成
+		mockRepo := new(MockTaskRepository)
+		mockRepo.On("FindById", task.TaskId(2)).Return((*task.Task)(nil), repository.ErrNotFound)
+		usecase := usecase.TraceTaskUsecase(usecase.NewTaskUsecase(mockRepo), tracer)
+
+		err := usecase.ChangeStatus(context.Background(), user.UserId(1), task.TaskId(2), task.StatusComplete)
+
+		// 検証
+		assert.ErrorIs(t, err, repository.ErrNotFound)
+		spans := recorder.Ended()
+		span := spans[len(spans)-1]
+		assert.Equal(t, "TaskUsecase.ChangeStatus", span.Name())
+		assert.Equal(t, codes.Error, span.Status().Code)
+		assert.Len(t, span.Events(), 1)
+	})
+}
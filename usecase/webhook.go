@@ -0,0 +1,257 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/fuki01/onion-architecture/domain/repository"
+	"github.com/fuki01/onion-architecture/domain/user"
+	"github.com/fuki01/onion-architecture/domain/webhook"
+)
+
+// EventPublisher はタスクの操作で発生したイベントを外部に伝えるポート
+type EventPublisher interface {
+	// Publish はaudienceが返すユーザーにイベントを伝える
+	Publish(event webhook.Event, audience Audience) error
+}
+
+// Audience はイベントの内容を参照できるユーザーを返す
+// 取得にはデータベースの参照を伴うため、送り先がある場合のみ呼ぶ
+type Audience func() ([]user.UserId, error)
+
+// WebhookSender は送信先にイベントを送るポート
+type WebhookSender interface {
+	// CheckURL は送信先のホストが公開されたアドレスのみに解決されることを確認する
+	// 内部のアドレスの場合はwebhook.ErrNonPublicURLを返す
+	CheckURL(ctx context.Context, rawURL string) error
+	// Send は応答のステータスコードを返す
+	Send(ctx context.Context, sub *webhook.Subscription, d *webhook.Delivery) (int, error)
+}
+
+// WebhookUsecase は送信先の管理とイベントの配送を提供する
+// 送信先は登録したユーザーのみが操作でき、他のユーザーの送信先は存在しないものとして扱う
+type WebhookUsecase interface {
+	EventPublisher
+	CreateSubscription(actor user.UserId, url, secret string, eventTypes []webhook.EventType) (*webhook.Subscription, error)
+	GetSubscriptions(actor user.UserId) ([]*webhook.Subscription, error)
+	DeleteSubscription(actor user.UserId, id webhook.SubscriptionId) error
+	GetDeliveries(actor user.UserId, id webhook.SubscriptionId) ([]*webhook.Delivery, error)
+	Redeliver(actor user.UserId, id webhook.SubscriptionId, deliveryId webhook.DeliveryId) (*webhook.Delivery, error)
+	// Shutdown は再送待ちを打ち切り、配送中の処理の終了を待つ
+	Shutdown(ctx context.Context) error
+}
+
+// RetryPolicy は配送に失敗した場合の再送の設定
+// n回目の再送はBaseDelay*2^(n-1)後に行う
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+}
+
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 5,
+	BaseDelay:   time.Second,
+}
+
+type webhookUsecase struct {
+	webhookRepository repository.WebhookRepository
+	sender            WebhookSender
+	retry             RetryPolicy
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewWebhookUsecase(webhookRepository repository.WebhookRepository, sender WebhookSender, retry RetryPolicy) WebhookUsecase {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &webhookUsecase{
+		webhookRepository: webhookRepository,
+		sender:            sender,
+		retry:             retry,
+		ctx:               ctx,
+		cancel:            cancel,
+	}
+}
+
+// 送信先を登録する
+func (wu *webhookUsecase) CreateSubscription(actor user.UserId, url, secret string, eventTypes []webhook.EventType) (*webhook.Subscription, error) {
+	sub, err := webhook.NewSubscription(actor, url, secret, eventTypes)
+	if err != nil {
+		return nil, err
+	}
+	// 内部のサービスに送らせないよう、登録時にも送信先のアドレスを確認する
+	if err := wu.sender.CheckURL(wu.ctx, sub.URL); err != nil {
+		return nil, err
+	}
+	if err := wu.webhookRepository.InsertSubscription(sub); err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+// 送信先一覧を取得する
+func (wu *webhookUsecase) GetSubscriptions(actor user.UserId) ([]*webhook.Subscription, error) {
+	return wu.webhookRepository.FindSubscriptionsByUserIds([]user.UserId{actor})
+}
+
+// 送信先を削除する
+func (wu *webhookUsecase) DeleteSubscription(actor user.UserId, id webhook.SubscriptionId) error {
+	sub, err := wu.findOwnedSubscription(actor, id)
+	if err != nil {
+		return err
+	}
+	return wu.webhookRepository.DeleteSubscription(sub)
+}
+
+// 送信先の配送記録を取得する
+func (wu *webhookUsecase) GetDeliveries(actor user.UserId, id webhook.SubscriptionId) ([]*webhook.Delivery, error) {
+	if _, err := wu.findOwnedSubscription(actor, id); err != nil {
+		return nil, err
+	}
+	return wu.webhookRepository.FindDeliveriesBySubscriptionId(id)
+}
+
+// 配送記録と同じ内容を一度だけ再送し、その結果を返す
+func (wu *webhookUsecase) Redeliver(actor user.UserId, id webhook.SubscriptionId, deliveryId webhook.DeliveryId) (*webhook.Delivery, error) {
+	sub, err := wu.findOwnedSubscription(actor, id)
+	if err != nil {
+		return nil, err
+	}
+	original, err := wu.webhookRepository.FindDeliveryById(deliveryId)
+	if err != nil {
+		return nil, err
+	}
+	if original.SubscriptionId != sub.Id {
+		return nil, repository.ErrNotFound
+	}
+
+	d := original.Redelivery()
+	if err := wu.webhookRepository.InsertDelivery(d); err != nil {
+		return nil, err
+	}
+	if err := wu.attempt(sub, d); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// イベントを参照できるユーザーが登録し、イベントを購読している送信先ごとに配送記録を作成し、非同期に配送する
+// イベントを購読している送信先がない場合は参照できるユーザーを取得しない
+func (wu *webhookUsecase) Publish(event webhook.Event, audience Audience) error {
+	subscribed, err := wu.webhookRepository.HasSubscriptionsFor(event.Type)
+	if err != nil || !subscribed {
+		return err
+	}
+	userIds, err := audience()
+	if err != nil {
+		return err
+	}
+	if len(userIds) == 0 {
+		return nil
+	}
+	subs, err := wu.webhookRepository.FindSubscriptionsByUserIds(userIds)
+	if err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	for _, sub := range subs {
+		if !sub.Subscribes(event.Type) {
+			continue
+		}
+		d := &webhook.Delivery{
+			SubscriptionId: sub.Id,
+			EventType:      event.Type,
+			Payload:        string(payload),
+		}
+		if err := wu.webhookRepository.InsertDelivery(d); err != nil {
+			return err
+		}
+
+		wu.wg.Add(1)
+		go func(sub *webhook.Subscription) {
+			defer wu.wg.Done()
+			wu.deliverWithRetry(sub, d)
+		}(sub)
+	}
+	return nil
+}
+
+// 送信先を取得し、ユーザーが登録したものであることを確認する
+// 他のユーザーの送信先は存在しないものとして扱う
+func (wu *webhookUsecase) findOwnedSubscription(actor user.UserId, id webhook.SubscriptionId) (*webhook.Subscription, error) {
+	sub, err := wu.webhookRepository.FindSubscriptionById(id)
+	if err != nil {
+		return nil, err
+	}
+	if sub.UserId != actor {
+		return nil, repository.ErrNotFound
+	}
+	return sub, nil
+}
+
+// 配送中の処理の終了を待つ
+func (wu *webhookUsecase) Shutdown(ctx context.Context) error {
+	wu.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		wu.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// 成功するか上限に達するまで指数的に間隔を広げながら配送を繰り返す
+// 送信先が削除された場合は再送をやめる
+func (wu *webhookUsecase) deliverWithRetry(sub *webhook.Subscription, d *webhook.Delivery) {
+	delay := wu.retry.BaseDelay
+	for {
+		if _, err := wu.webhookRepository.FindSubscriptionById(sub.Id); err != nil {
+			if !errors.Is(err, repository.ErrNotFound) {
+				slog.Error("failed to find webhook subscription", slog.Int("subscription_id", int(sub.Id)), slog.Any("error", err))
+			}
+			return
+		}
+		if err := wu.attempt(sub, d); err != nil {
+			if !errors.Is(err, repository.ErrNotFound) {
+				slog.Error("failed to record webhook delivery", slog.Int("delivery_id", int(d.Id)), slog.Any("error", err))
+			}
+			return
+		}
+		if d.Succeeded || d.Attempts >= wu.retry.MaxAttempts {
+			return
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-wu.ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		delay *= 2
+	}
+}
+
+// 一度だけ配送を試み、結果を記録する
+func (wu *webhookUsecase) attempt(sub *webhook.Subscription, d *webhook.Delivery) error {
+	status, err := wu.sender.Send(wu.ctx, sub, d)
+	d.RecordAttempt(time.Now(), status, err)
+	return wu.webhookRepository.UpdateDelivery(d)
+}
@@ -0,0 +1,387 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/fuki01/onion-architecture/domain/repository"
+	"github.com/fuki01/onion-architecture/domain/user"
+	"github.com/fuki01/onion-architecture/domain/webhook"
+	"github.com/fuki01/onion-architecture/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockWebhookRepository struct {
+	mock.Mock
+}
+
+func (m *MockWebhookRepository) FindSubscriptionById(id webhook.SubscriptionId) (*webhook.Subscription, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*webhook.Subscription), args.Error(1)
+}
+
+func (m *MockWebhookRepository) HasSubscriptionsFor(eventType webhook.EventType) (bool, error) {
+	args := m.Called(eventType)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockWebhookRepository) FindSubscriptionsByUserIds(userIds []user.UserId) ([]*webhook.Subscription, error) {
+	args := m.Called(userIds)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*webhook.Subscription), args.Error(1)
+}
+
+func (m *MockWebhookRepository) InsertSubscription(s *webhook.Subscription) error {
+	args := m.Called(s)
+	return args.Error(0)
+}
+
+func (m *MockWebhookRepository) DeleteSubscription(s *webhook.Subscription) error {
+	args := m.Called(s)
+	return args.Error(0)
+}
+
+func (m *MockWebhookRepository) FindDeliveryById(id webhook.DeliveryId) (*webhook.Delivery, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*webhook.Delivery), args.Error(1)
+}
+
+func (m *MockWebhookRepository) FindDeliveriesBySubscriptionId(id webhook.SubscriptionId) ([]*webhook.Delivery, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*webhook.Delivery), args.Error(1)
+}
+
+func (m *MockWebhookRepository) InsertDelivery(d *webhook.Delivery) error {
+	args := m.Called(d)
+	return args.Error(0)
+}
+
+func (m *MockWebhookRepository) UpdateDelivery(d *webhook.Delivery) error {
+	args := m.Called(d)
+	return args.Error(0)
+}
+
+type MockWebhookSender struct {
+	mock.Mock
+}
+
+func (m *MockWebhookSender) CheckURL(ctx context.Context, rawURL string) error {
+	args := m.Called(rawURL)
+	return args.Error(0)
+}
+
+func (m *MockWebhookSender) Send(ctx context.Context, sub *webhook.Subscription, d *webhook.Delivery) (int, error) {
+	args := m.Called(sub, d)
+	return args.Int(0), args.Error(1)
+}
+
+func TestCreateSubscription(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		// モック作成
+		mockRepo := new(MockWebhookRepository)
+		mockRepo.On("InsertSubscription", mock.AnythingOfType("*webhook.Subscription")).Return(nil)
+		sender := new(MockWebhookSender)
+		sender.On("CheckURL", "https://example.com/hook").Return(nil)
+		usecase := usecase.NewWebhookUsecase(mockRepo, sender, usecase.DefaultRetryPolicy)
+
+		// 検証
+		sub, err := usecase.CreateSubscription(user.UserId(1), "https://example.com/hook", "", []webhook.EventType{webhook.EventTaskCreated})
+		require.NoError(t, err)
+		assert.Equal(t, user.UserId(1), sub.UserId)
+		assert.NotEmpty(t, sub.Secret)
+		mockRepo.AssertExpectations(t)
+		sender.AssertExpectations(t)
+	})
+
+	t.Run("non-public address", func(t *testing.T) {
+		// モック作成
+		mockRepo := new(MockWebhookRepository)
+		sender := new(MockWebhookSender)
+		sender.On("CheckURL", "http://169.254.169.254/latest").Return(webhook.ErrNonPublicURL)
+		usecase := usecase.NewWebhookUsecase(mockRepo, sender, usecase.DefaultRetryPolicy)
+
+		// 検証
+		_, err := usecase.CreateSubscription(user.UserId(1), "http://169.254.169.254/latest", "", []webhook.EventType{webhook.EventTaskCreated})
+		assert.ErrorIs(t, err, webhook.ErrNonPublicURL)
+		mockRepo.AssertNotCalled(t, "InsertSubscription", mock.Anything)
+	})
+
+	t.Run("invalid url", func(t *testing.T) {
+		// モック作成
+		mockRepo := new(MockWebhookRepository)
+		usecase := usecase.NewWebhookUsecase(mockRepo, new(MockWebhookSender), usecase.DefaultRetryPolicy)
+
+		// 検証
+		_, err := usecase.CreateSubscription(user.UserId(1), "ftp://example.com", "", []webhook.EventType{webhook.EventTaskCreated})
+		assert.ErrorIs(t, err, webhook.ErrInvalidURL)
+		mockRepo.AssertNotCalled(t, "InsertSubscription", mock.Anything)
+	})
+}
+
+func TestPublish(t *testing.T) {
+	created := &webhook.Subscription{Id: 1, UserId: 1, URL: "https://example.com/a", EventTypes: []webhook.EventType{webhook.EventTaskCreated}}
+	completed := &webhook.Subscription{Id: 2, UserId: 1, URL: "https://example.com/b", EventTypes: []webhook.EventType{webhook.EventTaskCompleted}}
+	event := webhook.Event{Type: webhook.EventTaskCreated, OccurredAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	audience := func() ([]user.UserId, error) {
+		return []user.UserId{1}, nil
+	}
+
+	t.Run("retry until success", func(t *testing.T) {
+		// モック作成
+		var delivery *webhook.Delivery
+		mockRepo := new(MockWebhookRepository)
+		mockRepo.On("HasSubscriptionsFor", webhook.EventTaskCreated).Return(true, nil)
+		mockRepo.On("FindSubscriptionsByUserIds", []user.UserId{1}).Return([]*webhook.Subscription{created, completed}, nil)
+		mockRepo.On("FindSubscriptionById", created.Id).Return(created, nil)
+		mockRepo.On("InsertDelivery", mock.AnythingOfType("*webhook.Delivery")).Run(func(args mock.Arguments) {
+			delivery = args.Get(0).(*webhook.Delivery)
+			delivery.Id = 10
+		}).Return(nil)
+		mockRepo.On("UpdateDelivery", mock.AnythingOfType("*webhook.Delivery")).Return(nil)
+
+		done := make(chan struct{})
+		sender := new(MockWebhookSender)
+		sender.On("Send", created, mock.Anything).Return(0, errors.New("connection refused")).Once()
+		sender.On("Send", created, mock.Anything).Return(500, nil).Once()
+		sender.On("Send", created, mock.Anything).Return(200, nil).Once().Run(func(mock.Arguments) { close(done) })
+		usecase := usecase.NewWebhookUsecase(mockRepo, sender, usecase.RetryPolicy{MaxAttempts: 5, BaseDelay: time.Millisecond})
+
+		// イベントの発行
+		require.NoError(t, usecase.Publish(event, audience))
+		<-done
+		require.NoError(t, usecase.Shutdown(context.Background()))
+
+		// 検証
+		mockRepo.AssertNumberOfCalls(t, "InsertDelivery", 1)
+		assert.Equal(t, webhook.SubscriptionId(1), delivery.SubscriptionId)
+		assert.JSONEq(t, `{"type":"task.created","occurred_at":"2024-01-01T00:00:00Z","data":null}`, delivery.Payload)
+		assert.Equal(t, 3, delivery.Attempts)
+		assert.Equal(t, 200, delivery.StatusCode)
+		assert.True(t, delivery.Succeeded)
+		sender.AssertExpectations(t)
+	})
+
+	t.Run("give up after max attempts", func(t *testing.T) {
+		// モック作成
+		var delivery *webhook.Delivery
+		mockRepo := new(MockWebhookRepository)
+		mockRepo.On("HasSubscriptionsFor", webhook.EventTaskCreated).Return(true, nil)
+		mockRepo.On("FindSubscriptionsByUserIds", []user.UserId{1}).Return([]*webhook.Subscription{created}, nil)
+		mockRepo.On("FindSubscriptionById", created.Id).Return(created, nil)
+		mockRepo.On("InsertDelivery", mock.AnythingOfType("*webhook.Delivery")).Run(func(args mock.Arguments) {
+			delivery = args.Get(0).(*webhook.Delivery)
+		}).Return(nil)
+		mockRepo.On("UpdateDelivery", mock.AnythingOfType("*webhook.Delivery")).Return(nil)
+
+		done := make(chan struct{})
+		sender := new(MockWebhookSender)
+		sender.On("Send", created, mock.Anything).Return(503, nil).Twice()
+		sender.On("Send", created, mock.Anything).Return(503, nil).Once().Run(func(mock.Arguments) { close(done) })
+		usecase := usecase.NewWebhookUsecase(mockRepo, sender, usecase.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond})
+
+		// イベントの発行
+		require.NoError(t, usecase.Publish(event, audience))
+		<-done
+		require.NoError(t, usecase.Shutdown(context.Background()))
+
+		// 検証
+		assert.Equal(t, 3, delivery.Attempts)
+		assert.False(t, delivery.Succeeded)
+		sender.AssertNumberOfCalls(t, "Send", 3)
+	})
+
+	t.Run("no audience", func(t *testing.T) {
+		// モック作成
+		mockRepo := new(MockWebhookRepository)
+		mockRepo.On("HasSubscriptionsFor", webhook.EventTaskCreated).Return(true, nil)
+		usecase := usecase.NewWebhookUsecase(mockRepo, new(MockWebhookSender), usecase.DefaultRetryPolicy)
+
+		// 検証
+		require.NoError(t, usecase.Publish(event, func() ([]user.UserId, error) { return nil, nil }))
+		mockRepo.AssertNotCalled(t, "FindSubscriptionsByUserIds", mock.Anything)
+	})
+
+	t.Run("audience is not resolved without subscriptions", func(t *testing.T) {
+		// モック作成
+		mockRepo := new(MockWebhookRepository)
+		mockRepo.On("HasSubscriptionsFor", webhook.EventTaskCreated).Return(false, nil)
+		usecase := usecase.NewWebhookUsecase(mockRepo, new(MockWebhookSender), usecase.DefaultRetryPolicy)
+
+		// 検証
+		resolved := false
+		require.NoError(t, usecase.Publish(event, func() ([]user.UserId, error) {
+			resolved = true
+			return []user.UserId{1}, nil
+		}))
+		assert.False(t, resolved)
+		mockRepo.AssertNotCalled(t, "FindSubscriptionsByUserIds", mock.Anything)
+	})
+
+	t.Run("stop retrying after the subscription is deleted", func(t *testing.T) {
+		// モック作成
+		mockRepo := new(MockWebhookRepository)
+		mockRepo.On("HasSubscriptionsFor", webhook.EventTaskCreated).Return(true, nil)
+		mockRepo.On("FindSubscriptionsByUserIds", []user.UserId{1}).Return([]*webhook.Subscription{created}, nil)
+		mockRepo.On("InsertDelivery", mock.AnythingOfType("*webhook.Delivery")).Return(nil)
+		mockRepo.On("UpdateDelivery", mock.AnythingOfType("*webhook.Delivery")).Return(nil)
+
+		done := make(chan struct{})
+		mockRepo.On("FindSubscriptionById", created.Id).Return(created, nil).Once()
+		mockRepo.On("FindSubscriptionById", created.Id).Return(nil, repository.ErrNotFound).Once().Run(func(mock.Arguments) { close(done) })
+		sender := new(MockWebhookSender)
+		sender.On("Send", created, mock.Anything).Return(500, nil)
+		usecase := usecase.NewWebhookUsecase(mockRepo, sender, usecase.RetryPolicy{MaxAttempts: 5, BaseDelay: time.Millisecond})
+
+		// イベントの発行
+		require.NoError(t, usecase.Publish(event, audience))
+		<-done
+		require.NoError(t, usecase.Shutdown(context.Background()))
+
+		// 検証
+		sender.AssertNumberOfCalls(t, "Send", 1)
+	})
+
+	t.Run("stop retrying when the delivery is gone", func(t *testing.T) {
+		// モック作成
+		mockRepo := new(MockWebhookRepository)
+		mockRepo.On("HasSubscriptionsFor", webhook.EventTaskCreated).Return(true, nil)
+		mockRepo.On("FindSubscriptionsByUserIds", []user.UserId{1}).Return([]*webhook.Subscription{created}, nil)
+		mockRepo.On("FindSubscriptionById", created.Id).Return(created, nil)
+		mockRepo.On("InsertDelivery", mock.AnythingOfType("*webhook.Delivery")).Return(nil)
+
+		done := make(chan struct{})
+		mockRepo.On("UpdateDelivery", mock.AnythingOfType("*webhook.Delivery")).Return(repository.ErrNotFound).Once().Run(func(mock.Arguments) { close(done) })
+		sender := new(MockWebhookSender)
+		sender.On("Send", created, mock.Anything).Return(500, nil)
+		usecase := usecase.NewWebhookUsecase(mockRepo, sender, usecase.RetryPolicy{MaxAttempts: 5, BaseDelay: time.Millisecond})
+
+		// イベントの発行
+		require.NoError(t, usecase.Publish(event, audience))
+		<-done
+		require.NoError(t, usecase.Shutdown(context.Background()))
+
+		// 検証
+		sender.AssertNumberOfCalls(t, "Send", 1)
+	})
+
+	t.Run("shutdown cancels pending retries", func(t *testing.T) {
+		// モック作成
+		var delivery *webhook.Delivery
+		mockRepo := new(MockWebhookRepository)
+		mockRepo.On("HasSubscriptionsFor", webhook.EventTaskCreated).Return(true, nil)
+		mockRepo.On("FindSubscriptionsByUserIds", []user.UserId{1}).Return([]*webhook.Subscription{created}, nil)
+		mockRepo.On("FindSubscriptionById", created.Id).Return(created, nil)
+		mockRepo.On("InsertDelivery", mock.AnythingOfType("*webhook.Delivery")).Run(func(args mock.Arguments) {
+			delivery = args.Get(0).(*webhook.Delivery)
+		}).Return(nil)
+
+		attempted := make(chan struct{})
+		mockRepo.On("UpdateDelivery", mock.AnythingOfType("*webhook.Delivery")).Return(nil).Once().Run(func(mock.Arguments) { close(attempted) })
+		sender := new(MockWebhookSender)
+		sender.On("Send", created, mock.Anything).Return(500, nil).Once()
+		usecase := usecase.NewWebhookUsecase(mockRepo, sender, usecase.RetryPolicy{MaxAttempts: 5, BaseDelay: time.Hour})
+
+		// イベントの発行
+		require.NoError(t, usecase.Publish(event, audience))
+		<-attempted
+
+		// 検証
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		require.NoError(t, usecase.Shutdown(ctx))
+		assert.Equal(t, 1, delivery.Attempts)
+	})
+}
+
+func TestRedeliver(t *testing.T) {
+	sub := &webhook.Subscription{Id: 1, UserId: 1, URL: "https://example.com/a", EventTypes: []webhook.EventType{webhook.EventTaskCreated}}
+	original := &webhook.Delivery{Id: 10, SubscriptionId: 1, EventType: webhook.EventTaskCreated, Payload: `{}`, Attempts: 5}
+
+	t.Run("success", func(t *testing.T) {
+		// モック作成
+		mockRepo := new(MockWebhookRepository)
+		mockRepo.On("FindDeliveryById", webhook.DeliveryId(10)).Return(original, nil)
+		mockRepo.On("FindSubscriptionById", webhook.SubscriptionId(1)).Return(sub, nil)
+		mockRepo.On("InsertDelivery", mock.AnythingOfType("*webhook.Delivery")).Return(nil)
+		mockRepo.On("UpdateDelivery", mock.AnythingOfType("*webhook.Delivery")).Return(nil)
+		sender := new(MockWebhookSender)
+		sender.On("Send", sub, mock.Anything).Return(204, nil)
+		usecase := usecase.NewWebhookUsecase(mockRepo, sender, usecase.DefaultRetryPolicy)
+
+		// 再送
+		d, err := usecase.Redeliver(user.UserId(1), 1, 10)
+
+		// 検証
+		require.NoError(t, err)
+		assert.Equal(t, webhook.DeliveryId(10), *d.RedeliveryOf)
+		assert.Equal(t, `{}`, d.Payload)
+		assert.Equal(t, 1, d.Attempts)
+		assert.True(t, d.Succeeded)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("other subscription", func(t *testing.T) {
+		// モック作成
+		other := &webhook.Subscription{Id: 2, UserId: 1, URL: "https://example.com/b", EventTypes: []webhook.EventType{webhook.EventTaskCreated}}
+		mockRepo := new(MockWebhookRepository)
+		mockRepo.On("FindSubscriptionById", webhook.SubscriptionId(2)).Return(other, nil)
+		mockRepo.On("FindDeliveryById", webhook.DeliveryId(10)).Return(original, nil)
+		sender := new(MockWebhookSender)
+		usecase := usecase.NewWebhookUsecase(mockRepo, sender, usecase.DefaultRetryPolicy)
+
+		// 検証
+		_, err := usecase.Redeliver(user.UserId(1), 2, 10)
+		assert.ErrorIs(t, err, repository.ErrNotFound)
+		sender.AssertNotCalled(t, "Send", mock.Anything, mock.Anything)
+	})
+}
+
+func TestSubscriptionOwnership(t *testing.T) {
+	sub := &webhook.Subscription{Id: 1, UserId: 1, URL: "https://example.com/a", EventTypes: []webhook.EventType{webhook.EventTaskCreated}}
+
+	t.Run("list own subscriptions", func(t *testing.T) {
+		// モック作成
+		mockRepo := new(MockWebhookRepository)
+		mockRepo.On("FindSubscriptionsByUserIds", []user.UserId{1}).Return([]*webhook.Subscription{sub}, nil)
+		usecase := usecase.NewWebhookUsecase(mockRepo, new(MockWebhookSender), usecase.DefaultRetryPolicy)
+
+		// 検証
+		subs, err := usecase.GetSubscriptions(user.UserId(1))
+		require.NoError(t, err)
+		assert.Equal(t, []*webhook.Subscription{sub}, subs)
+	})
+
+	t.Run("other user's subscription", func(t *testing.T) {
+		// モック作成
+		mockRepo := new(MockWebhookRepository)
+		mockRepo.On("FindSubscriptionById", webhook.SubscriptionId(1)).Return(sub, nil)
+		sender := new(MockWebhookSender)
+		usecase := usecase.NewWebhookUsecase(mockRepo, sender, usecase.DefaultRetryPolicy)
+
+		// 検証
+		assert.ErrorIs(t, usecase.DeleteSubscription(user.UserId(2), 1), repository.ErrNotFound)
+		_, err := usecase.GetDeliveries(user.UserId(2), 1)
+		assert.ErrorIs(t, err, repository.ErrNotFound)
+		_, err = usecase.Redeliver(user.UserId(2), 1, 10)
+		assert.ErrorIs(t, err, repository.ErrNotFound)
+		mockRepo.AssertNotCalled(t, "DeleteSubscription", mock.Anything)
+		mockRepo.AssertNotCalled(t, "FindDeliveriesBySubscriptionId", mock.Anything)
+		mockRepo.AssertNotCalled(t, "FindDeliveryById", mock.Anything)
+		sender.AssertNotCalled(t, "Send", mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,114 @@
+package usecase
+
+import (
+	"time"
+
+	"github.com/fuki01/onion-architecture/domain/repository"
+	"github.com/fuki01/onion-architecture/domain/user"
+	"github.com/fuki01/onion-architecture/domain/workspace"
+)
+
+// WorkspaceUsecase はワークスペースとメンバーの管理を提供する
+// actorは操作するユーザーで、役割で許可されていなければErrForbiddenを返す
+type WorkspaceUsecase interface {
+	CreateWorkspace(actor user.UserId, name string) (*workspace.Workspace, error)
+	GetWorkspaces(actor user.UserId) ([]*workspace.Workspace, error)
+	GetWorkspace(actor user.UserId, id workspace.WorkspaceId) (*workspace.Workspace, error)
+	AddMember(actor user.UserId, id workspace.WorkspaceId, userId user.UserId, role workspace.Role) (workspace.Member, error)
+	ChangeRole(actor user.UserId, id workspace.WorkspaceId, userId user.UserId, role workspace.Role) (workspace.Member, error)
+	RemoveMember(actor user.UserId, id workspace.WorkspaceId, userId user.UserId) error
+}
+
+type workspaceUsecase struct {
+	workspaceRepository repository.WorkspaceRepository
+	userRepository      repository.UserRepository
+	now                 func() time.Time
+}
+
+func NewWorkspaceUsecase(workspaceRepository repository.WorkspaceRepository, userRepository repository.UserRepository) WorkspaceUsecase {
+	return &workspaceUsecase{
+		workspaceRepository: workspaceRepository,
+		userRepository:      userRepository,
+		now:                 time.Now,
+	}
+}
+
+// ワークスペースを作成する
+// 作成したユーザーが所有者になる
+func (wu *workspaceUsecase) CreateWorkspace(actor user.UserId, name string) (*workspace.Workspace, error) {
+	ws, err := workspace.NewWorkspace(name, actor, wu.now())
+	if err != nil {
+		return nil, err
+	}
+	if err := wu.workspaceRepository.Insert(ws); err != nil {
+		return nil, err
+	}
+	return ws, nil
+}
+
+// 参加しているワークスペースの一覧を取得する
+func (wu *workspaceUsecase) GetWorkspaces(actor user.UserId) ([]*workspace.Workspace, error) {
+	return wu.workspaceRepository.FindByUserId(actor)
+}
+
+// ワークスペースを取得する
+// メンバーのみ参照できる
+func (wu *workspaceUsecase) GetWorkspace(actor user.UserId, id workspace.WorkspaceId) (*workspace.Workspace, error) {
+	ws, err := wu.workspaceRepository.FindById(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := ws.Authorize(actor, workspace.ActionViewTasks); err != nil {
+		return nil, err
+	}
+	return ws, nil
+}
+
+// メンバーを追加する
+func (wu *workspaceUsecase) AddMember(actor user.UserId, id workspace.WorkspaceId, userId user.UserId, role workspace.Role) (workspace.Member, error) {
+	ws, err := wu.workspaceRepository.FindById(id)
+	if err != nil {
+		return workspace.Member{}, err
+	}
+	if _, err := wu.userRepository.FindById(userId); err != nil {
+		return workspace.Member{}, err
+	}
+
+	m, err := ws.AddMember(actor, userId, role, wu.now())
+	if err != nil {
+		return workspace.Member{}, err
+	}
+	if err := wu.workspaceRepository.AddMember(m); err != nil {
+		return workspace.Member{}, err
+	}
+	return m, nil
+}
+
+// メンバーの役割を変更する
+func (wu *workspaceUsecase) ChangeRole(actor user.UserId, id workspace.WorkspaceId, userId user.UserId, role workspace.Role) (workspace.Member, error) {
+	ws, err := wu.workspaceRepository.FindById(id)
+	if err != nil {
+		return workspace.Member{}, err
+	}
+	m, err := ws.ChangeRole(actor, userId, role)
+	if err != nil {
+		return workspace.Member{}, err
+	}
+	if err := wu.workspaceRepository.UpdateMember(m); err != nil {
+		return workspace.Member{}, err
+	}
+	return m, nil
+}
+
+// メンバーを削除する
+func (wu *workspaceUsecase) RemoveMember(actor user.UserId, id workspace.WorkspaceId, userId user.UserId) error {
+	ws, err := wu.workspaceRepository.FindById(id)
+	if err != nil {
+		return err
+	}
+	m, err := ws.RemoveMember(actor, userId)
+	if err != nil {
+		return err
+	}
+	return wu.workspaceRepository.RemoveMember(m)
+}
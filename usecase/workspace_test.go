@@ -0,0 +1,153 @@
+package usecase_test
+
+import (
+	"testing"
+
+	"github.com/fuki01/onion-architecture/domain/repository"
+	"github.com/fuki01/onion-architecture/domain/user"
+	"github.com/fuki01/onion-architecture/domain/workspace"
+	"github.com/fuki01/onion-architecture/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockWorkspaceRepository struct {
+	mock.Mock
+}
+
+func (m *MockWorkspaceRepository) FindById(id workspace.WorkspaceId) (*workspace.Workspace, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*workspace.Workspace), args.Error(1)
+}
+
+func (m *MockWorkspaceRepository) FindByUserId(userId user.UserId) ([]*workspace.Workspace, error) {
+	args := m.Called(userId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*workspace.Workspace), args.Error(1)
+}
+
+func (m *MockWorkspaceRepository) Insert(w *workspace.Workspace) error {
+	args := m.Called(w)
+	return args.Error(0)
+}
+
+func (m *MockWorkspaceRepository) AddMember(member workspace.Member) error {
+	args := m.Called(member)
+	return args.Error(0)
+}
+
+func (m *MockWorkspaceRepository) UpdateMember(member workspace.Member) error {
+	args := m.Called(member)
+	return args.Error(0)
+}
+
+func (m *MockWorkspaceRepository) RemoveMember(member workspace.Member) error {
+	args := m.Called(member)
+	return args.Error(0)
+}
+
+// newTeam はユーザー1が所有者、2が管理者、3がメンバー、4が閲覧者のワークスペースを生成する
+func newTeam() *workspace.Workspace {
+	return &workspace.Workspace{
+		Id:   workspace.WorkspaceId(1),
+		Name: "team",
+		Members: []workspace.Member{
+			{WorkspaceId: 1, UserId: 1, Role: workspace.RoleOwner},
+			{WorkspaceId: 1, UserId: 2, Role: workspace.RoleAdmin},
+			{WorkspaceId: 1, UserId: 3, Role: workspace.RoleMember},
+			{WorkspaceId: 1, UserId: 4, Role: workspace.RoleViewer},
+		},
+	}
+}
+
+func TestWorkspaceUsecase(t *testing.T) {
+	t.Run("create", func(t *testing.T) {
+		// モック作成
+		workspaces := new(MockWorkspaceRepository)
+		workspaces.On("Insert", mock.MatchedBy(func(w *workspace.Workspace) bool {
+			return w.Name == "team" && len(w.Members) == 1 && w.Members[0].Role == workspace.RoleOwner
+		})).Return(nil)
+		usecase := usecase.NewWorkspaceUsecase(workspaces, new(MockUserRepository))
+
+		// 検証
+		ws, err := usecase.CreateWorkspace(user.UserId(1), "team")
+		require.NoError(t, err)
+		role, _ := ws.RoleOf(user.UserId(1))
+		assert.Equal(t, workspace.RoleOwner, role)
+		workspaces.AssertExpectations(t)
+	})
+
+	t.Run("outsider cannot read", func(t *testing.T) {
+		// モック作成
+		workspaces := new(MockWorkspaceRepository)
+		workspaces.On("FindById", workspace.WorkspaceId(1)).Return(newTeam(), nil)
+		usecase := usecase.NewWorkspaceUsecase(workspaces, new(MockUserRepository))
+
+		// 検証
+		_, err := usecase.GetWorkspace(user.UserId(5), workspace.WorkspaceId(1))
+		assert.ErrorIs(t, err, workspace.ErrForbidden)
+		_, err = usecase.GetWorkspace(user.UserId(4), workspace.WorkspaceId(1))
+		assert.NoError(t, err)
+	})
+
+	t.Run("add member", func(t *testing.T) {
+		// モック作成
+		workspaces := new(MockWorkspaceRepository)
+		workspaces.On("FindById", workspace.WorkspaceId(1)).Return(newTeam(), nil)
+		workspaces.On("AddMember", mock.MatchedBy(func(m workspace.Member) bool {
+			return m.WorkspaceId == 1 && m.UserId == 5 && m.Role == workspace.RoleMember
+		})).Return(nil)
+		users := new(MockUserRepository)
+		users.On("FindById", user.UserId(5)).Return(&user.User{Id: 5}, nil)
+		usecase := usecase.NewWorkspaceUsecase(workspaces, users)
+
+		// 検証
+		_, err := usecase.AddMember(user.UserId(2), workspace.WorkspaceId(1), user.UserId(5), workspace.RoleMember)
+		require.NoError(t, err)
+		workspaces.AssertExpectations(t)
+	})
+
+	t.Run("add unknown user", func(t *testing.T) {
+		// モック作成
+		workspaces := new(MockWorkspaceRepository)
+		workspaces.On("FindById", workspace.WorkspaceId(1)).Return(newTeam(), nil)
+		users := new(MockUserRepository)
+		users.On("FindById", user.UserId(9)).Return(nil, repository.ErrNotFound)
+		usecase := usecase.NewWorkspaceUsecase(workspaces, users)
+
+		// 検証
+		_, err := usecase.AddMember(user.UserId(1), workspace.WorkspaceId(1), user.UserId(9), workspace.RoleMember)
+		assert.ErrorIs(t, err, repository.ErrNotFound)
+		workspaces.AssertNotCalled(t, "AddMember", mock.Anything)
+	})
+
+	t.Run("member cannot change roles", func(t *testing.T) {
+		// モック作成
+		workspaces := new(MockWorkspaceRepository)
+		workspaces.On("FindById", workspace.WorkspaceId(1)).Return(newTeam(), nil)
+		usecase := usecase.NewWorkspaceUsecase(workspaces, new(MockUserRepository))
+
+		// 検証
+		_, err := usecase.ChangeRole(user.UserId(3), workspace.WorkspaceId(1), user.UserId(4), workspace.RoleMember)
+		assert.ErrorIs(t, err, workspace.ErrForbidden)
+		workspaces.AssertNotCalled(t, "UpdateMember", mock.Anything)
+	})
+
+	t.Run("remove member", func(t *testing.T) {
+		// モック作成
+		workspaces := new(MockWorkspaceRepository)
+		workspaces.On("FindById", workspace.WorkspaceId(1)).Return(newTeam(), nil)
+		workspaces.On("RemoveMember", workspace.Member{WorkspaceId: 1, UserId: 4, Role: workspace.RoleViewer}).Return(nil)
+		usecase := usecase.NewWorkspaceUsecase(workspaces, new(MockUserRepository))
+
+		// 検証
+		require.NoError(t, usecase.RemoveMember(user.UserId(2), workspace.WorkspaceId(1), user.UserId(4)))
+		workspaces.AssertExpectations(t)
+	})
+}